@@ -0,0 +1,86 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/hybrid"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+func adoptCmd() *cobra.Command {
+	var mapping []string
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "adopt",
+		Short: "connect the services of an existing (hand-written) docker-compose.yaml to the recipe services",
+		Long: "Analyse the services of docker-compose.yaml, and map them to the recipe services by the explicit mapping (--map), " +
+			"the " + compose.RecipeLabel + " label of the service, or by the command and the image. The recipe service, the " +
+			"instance index and the labels are saved to the " + compose.MetadataExtension + " extension of the services, " +
+			"and used by all the other commands. Services which can't be matched are kept as is.",
+		Args: cobra.NoArgs,
+		RunE: ExecuteStorjUP(func(stack recipe.Stack, rt runtime.Runtime, args []string) error {
+			var c *compose.Compose
+			switch r := rt.(type) {
+			case *compose.Compose:
+				c = r
+			case *hybrid.Hybrid:
+				c = r.Containers()
+			default:
+				return errs.Errorf("adopt requires a docker compose based environment")
+			}
+			explicit := map[string]string{}
+			for _, m := range mapping {
+				service, recipeService, found := strings.Cut(m, "=")
+				if !found || service == "" || recipeService == "" {
+					return errs.Errorf("invalid mapping %q (expected <service>=<recipe service>[/<instance>])", m)
+				}
+				explicit[service] = recipeService
+			}
+			adoptions, err := c.Adopt(stack, explicit, force)
+			if err != nil {
+				return err
+			}
+			return printAdoptions(adoptions)
+		}),
+	}
+	cmd.Flags().StringSliceVarP(&mapping, "map", "m", nil, "map a service explicitly to a recipe service (<service>=<recipe service>[/<instance>])")
+	cmd.Flags().BoolVar(&force, "force", false, "map the services again, even if they are already adopted")
+	return cmd
+}
+
+func printAdoptions(adoptions []compose.Adoption) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tRECIPE SERVICE\tMATCHED BY\tLABELS")
+	for _, a := range adoptions {
+		if a.Reason == "" {
+			reason := "-"
+			if len(a.Candidates) > 0 {
+				reason = "ambiguous: " + strings.Join(a.Candidates, ",")
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t-\t%s\t-\n", a.Service, reason)
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			a.Service,
+			a.Metadata.Recipe+"/"+strconv.Itoa(a.Metadata.Instance),
+			a.Reason,
+			orDash(strings.Join(a.Metadata.Labels, ",")))
+	}
+	return errs.Wrap(tw.Flush())
+}
+
+func init() {
+	RootCmd.AddCommand(adoptCmd())
+}
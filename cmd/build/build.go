@@ -4,6 +4,11 @@
 package build
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
@@ -13,10 +18,19 @@ import (
 	"storj.io/storj-up/cmd"
 	"storj.io/storj-up/pkg/common"
 	dockerfiles "storj.io/storj-up/pkg/files/docker"
-	"storj.io/storj-up/pkg/files/templates"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
 )
 
 var skipFrontend bool
+var patch string
+
+// patchDir is the directory of the patches in the build context (see the patched stage of the Dockerfiles).
+const patchDir = "patches"
+
+// sourceBuildArgs are the build arguments which describe the source of a build. They are removed before each change,
+// so only the arguments of the current source are recorded.
+var sourceBuildArgs = []string{"TYPE", "SOURCE", "BRANCH", "COMMIT", "PR", "REF", "URL", "PATH", "PATCH", "SKIP_FRONTEND_BUILD"}
 
 var buildCmd = &cobra.Command{
 	Use:   "build",
@@ -29,154 +43,282 @@ var buildCmd = &cobra.Command{
 
 func init() {
 	buildCmd.PersistentFlags().BoolVarP(&skipFrontend, "skipfrontend", "s", false, "Skip building the frontend")
+	buildCmd.PersistentFlags().StringVar(&patch, "patch", "", "Patch file (like the output of git diff) to apply on the source before the build")
 	cmd.RootCmd.AddCommand(buildCmd)
 }
 
-func updateCompose(services []string, remoteType string) error {
-	err := common.ExtractFile("", "storj.Dockerfile", dockerfiles.StorjDocker)
+func updateCompose(services []string, remoteType string) (err error) {
+	pwd, err := cmd.ProjectDir()
+	if err != nil {
+		return err
+	}
+	unlock, err := common.LockProject(pwd)
 	if err != nil {
 		return err
 	}
+	defer func() { err = errs.Combine(err, unlock()) }()
 
-	err = common.ExtractFile("", "edge.Dockerfile", dockerfiles.EdgeDocker)
+	dir, err := filepath.Abs(pwd)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	composeProject, err := common.LoadComposeFromFile(dir, common.ComposeFileName)
 	if err != nil {
 		return err
 	}
+	before := compose.ProjectSpecs(composeProject)
 
-	composeProject, err := common.LoadComposeFromFile("./", common.ComposeFileName)
+	st, err := recipe.GetStack()
 	if err != nil {
 		return err
 	}
-	templateProject, err := common.LoadComposeFromBytes(templates.ComposeTemplate)
+	resolvedServices, err := common.ResolveServices(services)
 	if err != nil {
 		return err
 	}
-
-	resolvedBuilds, err := common.ResolveBuilds(services)
+	builds, err := resolveBuilds(st, resolvedServices, remoteType)
 	if err != nil {
 		return err
 	}
 
-	for buildType := range resolvedBuilds {
-		_, err = addToCompose(composeProject, templateProject, []string{buildType})
+	patchName := ""
+	var patchContent []byte
+	if patch != "" {
+		patchName, patchContent, err = readPatch(patch)
 		if err != nil {
 			return err
 		}
-		for serviceName, service := range composeProject.Services {
-			if strings.EqualFold(service.Name, buildType) {
-				err = setArg(&service, "BUILD_TAG="+dockerfiles.BuildTag())
-				if err != nil {
-					return errs.Wrap(err)
-				}
-				err = setArg(&service, "BASE_TAG="+dockerfiles.BaseTag())
-				if err != nil {
-					return errs.Wrap(err)
-				}
-				err = setArg(&service, "TYPE="+remoteType)
-				if err != nil {
-					return errs.Wrap(err)
-				}
-				if skipFrontend {
-					err = setArg(&service, "SKIP_FRONTEND_BUILD=true")
-					if err != nil {
-						return errs.Wrap(err)
-					}
-				}
-				switch remoteType {
-				case github:
-					err = setArg(&service, "BRANCH="+branch)
-					if err != nil {
-						return errs.Wrap(err)
-					}
-					err = setArg(&service, "SOURCE=branch")
-					if err != nil {
-						return errs.Wrap(err)
-					}
-					if commit != "" {
-						err = setArg(&service, "COMMIT="+commit)
-						if err != nil {
-							return errs.Wrap(err)
-						}
-						err = setArg(&service, "SOURCE=commit")
-						if err != nil {
-							return errs.Wrap(err)
-						}
-					}
-				case gerrit:
-					err = setArg(&service, "REF="+ref)
-					if err != nil {
-						return errs.Wrap(err)
-					}
-					err = setArg(&service, "SOURCE=none")
-					if err != nil {
-						return errs.Wrap(err)
-					}
-				case local:
-					if path == "" {
-						path = "."
-					}
-					err = setArg(&service, "SOURCE=none")
-					if err != nil {
-						return errs.Wrap(err)
-					}
-					err = setArg(&service, "PATH="+path)
-					if err != nil {
-						return errs.Wrap(err)
-					}
-				default:
-					return errs.Errorf("Unsupported remote: %s", remoteType)
-				}
-				composeProject.Services[serviceName] = service
-			}
-		}
 	}
-
-	resolvedServices, err := common.ResolveServices(services)
+	args, err := sourceArgs(remoteType, patchName)
 	if err != nil {
 		return err
 	}
 
+	added := map[string]bool{}
 	for _, service := range resolvedServices {
+		build := builds[service]
+		if !added[build.Name] {
+			err = addBuilder(composeProject, dir, build, args)
+			if err != nil {
+				return err
+			}
+			if patchName != "" {
+				// the Dockerfiles copy the patch from the build context
+				err = addPatch(contextDir(dir, build), patchName, patchContent)
+				if err != nil {
+					return err
+				}
+			}
+			added[build.Name] = true
+		}
 		for serviceName, composeService := range composeProject.Services {
 			if common.ServiceMatches(composeService.Name, service) {
-				composeService.Image = strings.Split(common.BuildDict[service], "-")[1]
+				composeService.Image = build.Image
 				composeProject.Services[serviceName] = composeService
 			}
 		}
 	}
-	return common.WriteComposeFile(".", composeProject)
+	if cmd.DryRun {
+		return cmd.PrintDiff(before, compose.ProjectSpecs(composeProject))
+	}
+	return common.WriteComposeFile(dir, composeProject)
 }
 
-func addToCompose(compose *types.Project, template *types.Project, services []string) (*types.Project, error) {
-	if compose == nil {
-		compose = &types.Project{Services: make(types.Services)}
-	}
-	if compose.Services == nil {
-		compose.Services = make(types.Services)
+// resolveBuilds returns with the build definition of each service, declared by the recipe (or by the flags of
+// `build dockerfile`), with the defaults.
+func resolveBuilds(st recipe.Stack, services []string, remoteType string) (map[string]recipe.Build, error) {
+	builds := map[string]recipe.Build{}
+	for _, service := range services {
+		var build recipe.Build
+		if remoteType == dockerfile {
+			// the prefix avoids shadowing the public images (like redis)
+			build = recipe.Build{Name: "app-" + service, Image: "storj-up/" + service, Dockerfile: dockerfilePath, Context: buildContext, Target: buildTarget}
+		} else {
+			recipeService, err := st.FindRecipeByName(service)
+			if err != nil {
+				return nil, err
+			}
+			if recipeService.Build == nil {
+				return nil, errs.Errorf("the recipe of %s doesn't define the build of the image, use `storj-up build dockerfile`", service)
+			}
+			build = *recipeService.Build
+		}
+		if build.Image == "" {
+			build.Image = service
+		}
+		if build.Name == "" {
+			build.Name = "app-" + build.Image
+		}
+		if build.Context == "" {
+			build.Context = "."
+		}
+		builds[service] = build
 	}
+	return builds, nil
+}
 
-	resolvedServices, err := common.ResolveServices(services)
+// addBuilder adds (or updates) the builder service of the build definition to the compose project. args are the
+// build arguments of the source.
+func addBuilder(project *types.Project, dir string, build recipe.Build, args []string) error {
+	context := contextDir(dir, build)
+	dockerfile, err := extractDockerfile(dir, context, build.Dockerfile)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	for _, service := range resolvedServices {
-		if !containsService(compose.Services, service) {
-			newService, err := template.GetService(service)
-			if err != nil {
-				return nil, err
+
+	if project.Services == nil {
+		project.Services = make(types.Services)
+	}
+	service, found := project.Services[build.Name]
+	if !found {
+		service = types.ServiceConfig{
+			Name: build.Name,
+			Networks: map[string]*types.ServiceNetworkConfig{
+				"default": nil,
+			},
+		}
+	}
+	service.Image = build.Image
+	if service.Build == nil {
+		service.Build = &types.BuildConfig{}
+	}
+	service.Build.Context = context
+	service.Build.Dockerfile = dockerfile
+	service.Build.Target = build.Target
+	for _, arg := range sourceBuildArgs {
+		delete(service.Build.Args, arg)
+	}
+	for key, value := range build.Args {
+		err = setArg(&service, key+"="+value)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	for _, arg := range args {
+		err = setArg(&service, arg)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	project.Services[build.Name] = service
+	return nil
+}
+
+// contextDir returns with the absolute path of the build context.
+func contextDir(dir string, build recipe.Build) string {
+	if filepath.IsAbs(build.Context) {
+		return build.Context
+	}
+	return filepath.Join(dir, build.Context)
+}
+
+// extractDockerfile extracts the embedded Dockerfile to the directory (an existing file is kept), or checks the custom
+// one. The returned path is relative to the build context, if possible.
+func extractDockerfile(dir string, context string, name string) (string, error) {
+	if name == "" {
+		return "", errs.Errorf("the Dockerfile of the build is not defined")
+	}
+	path := name
+	if content, found := dockerfiles.Embedded(name); found {
+		path = filepath.Join(dir, name)
+		if !cmd.DryRun {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				err = os.WriteFile(path, content, 0o644)
+				if err != nil {
+					return "", errs.Wrap(err)
+				}
 			}
-			compose.Services[service] = newService
 		}
+	} else {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return "", errs.Wrap(err)
+		}
+	}
+	if rel, err := filepath.Rel(context, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel, nil
 	}
-	return compose, nil
+	return path, nil
 }
 
-// containsService checks if the service is included in the services map.
-func containsService(services types.Services, name string) bool {
-	for _, s := range services {
-		if s.Name == name {
-			return true
+// sourceArgs returns with the build arguments (KEY=VALUE) of the source, based on the remote type and the flags.
+func sourceArgs(remoteType string, patchName string) ([]string, error) {
+	if remoteType == dockerfile {
+		if patchName != "" {
+			return nil, errs.Errorf("--patch is supported only by the embedded Dockerfiles")
 		}
+		for _, arg := range buildArgs {
+			if !strings.Contains(arg, "=") {
+				return nil, errs.Errorf("invalid build argument %q (KEY=VALUE)", arg)
+			}
+		}
+		return buildArgs, nil
+	}
+	args := []string{
+		"BUILD_TAG=" + dockerfiles.BuildTag(),
+		"BASE_TAG=" + dockerfiles.BaseTag(),
+		"TYPE=" + remoteType,
+	}
+	if skipFrontend {
+		args = append(args, "SKIP_FRONTEND_BUILD=true")
+	}
+	switch remoteType {
+	case github:
+		switch {
+		case pr < 0:
+			return nil, errs.Errorf("invalid pull request number: %d", pr)
+		case pr > 0:
+			args = append(args, "PR="+strconv.Itoa(pr), "SOURCE=pr")
+		case commit != "":
+			args = append(args, "BRANCH="+branch, "COMMIT="+commit, "SOURCE=commit")
+		default:
+			args = append(args, "BRANCH="+branch, "SOURCE=branch")
+		}
+	case gerrit:
+		args = append(args, "REF="+ref, "SOURCE=none")
+	case git:
+		args = append(args, "URL="+gitURL, "REF="+gitRef, "SOURCE=none")
+	case local:
+		if path == "" {
+			path = "."
+		}
+		args = append(args, "SOURCE=none", "PATH="+path)
+	default:
+		return nil, errs.Errorf("Unsupported remote: %s", remoteType)
+	}
+	if patchName != "" {
+		args = append(args, "PATCH="+patchName)
+	}
+	return args, nil
+}
+
+// readPatch reads the patch file, and returns with its name in the build context. The name starts with the hash of
+// the content, so the recorded build argument identifies the exact patch.
+func readPatch(file string) (string, []byte, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", nil, errs.Wrap(err)
+	}
+	hash := sha256.Sum256(raw)
+	base := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, filepath.Base(file))
+	return hex.EncodeToString(hash[:])[:12] + "-" + base, raw, nil
+}
+
+// addPatch copies the patch to the patches directory of the build context.
+func addPatch(context string, name string, content []byte) error {
+	if cmd.DryRun {
+		return nil
+	}
+	err := os.MkdirAll(filepath.Join(context, patchDir), 0o755)
+	if err != nil {
+		return errs.Wrap(err)
 	}
-	return false
+	return errs.Wrap(os.WriteFile(filepath.Join(context, patchDir, name), content, 0o644))
 }
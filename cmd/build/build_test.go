@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/recipe"
+)
+
+func TestResolveBuilds(t *testing.T) {
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+
+	builds, err := resolveBuilds(st, []string{"satellite-api", "storagenode", "gateway-mt", "storjscan"}, github)
+	require.NoError(t, err)
+	require.Equal(t, recipe.Build{Name: "app-storj", Image: "storj", Dockerfile: "storj.Dockerfile", Context: "."}, builds["satellite-api"])
+	require.Equal(t, builds["satellite-api"], builds["storagenode"])
+	require.Equal(t, "app-edge", builds["gateway-mt"].Name)
+	require.Equal(t, "storjscan.Dockerfile", builds["storjscan"].Dockerfile)
+
+	_, err = resolveBuilds(st, []string{"redis"}, github)
+	require.Error(t, err)
+
+	dockerfilePath, buildTarget = "custom.Dockerfile", "final"
+	defer func() { dockerfilePath, buildTarget = "", "" }()
+	builds, err = resolveBuilds(st, []string{"redis"}, dockerfile)
+	require.NoError(t, err)
+	require.Equal(t, recipe.Build{Name: "app-redis", Image: "storj-up/redis", Dockerfile: "custom.Dockerfile", Context: ".", Target: "final"}, builds["redis"])
+}
+
+func TestAddBuilder(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "custom.Dockerfile"), []byte("FROM scratch\n"), 0o644))
+
+	stale := "main"
+	project := &types.Project{Services: types.Services{
+		"app-storj": {
+			Name:  "app-storj",
+			Image: "storj",
+			Build: &types.BuildConfig{Args: types.MappingWithEquals{"BRANCH": &stale}},
+		},
+	}}
+
+	// embedded Dockerfile, source arguments replace the previous ones
+	build := recipe.Build{Name: "app-storj", Image: "storj", Dockerfile: "storj.Dockerfile", Context: ".", Args: map[string]string{"GO_TAGS": "noquic"}}
+	require.NoError(t, addBuilder(project, dir, build, []string{"TYPE=github", "PR=12", "SOURCE=pr"}))
+	builder := project.Services["app-storj"]
+	require.Equal(t, dir, builder.Build.Context)
+	require.Equal(t, "storj.Dockerfile", builder.Build.Dockerfile)
+	require.FileExists(t, filepath.Join(dir, "storj.Dockerfile"))
+	require.NotContains(t, builder.Build.Args, "BRANCH")
+	require.Equal(t, "12", *builder.Build.Args["PR"])
+	require.Equal(t, "noquic", *builder.Build.Args["GO_TAGS"])
+
+	// custom Dockerfile outside of the context
+	build = recipe.Build{Name: "app-redis", Image: "redis", Dockerfile: "custom.Dockerfile", Context: "src", Target: "final"}
+	require.NoError(t, addBuilder(project, dir, build, nil))
+	builder = project.Services["app-redis"]
+	require.Equal(t, filepath.Join(dir, "src"), builder.Build.Context)
+	require.Equal(t, filepath.Join(dir, "custom.Dockerfile"), builder.Build.Dockerfile)
+	require.Equal(t, "final", builder.Build.Target)
+	require.Equal(t, "redis", builder.Image)
+
+	build.Dockerfile = "missing.Dockerfile"
+	require.Error(t, addBuilder(project, dir, build, nil))
+}
+
+func TestUpdateComposeRoot(t *testing.T) {
+	t.Setenv("STORJUP_NO_HISTORY", "1")
+	dir := t.TempDir()
+	pwd := t.TempDir()
+	t.Chdir(pwd)
+	require.NoError(t, common.WriteComposeFile(dir, &types.Project{Name: "storj-up", Services: types.Services{
+		"storagenode": {Name: "storagenode", Image: "img.dev.storj.io/storjup/storj:1.125.2"},
+	}}))
+	require.NoError(t, cmd.RootCmd.PersistentFlags().Set("root", dir))
+	defer func() { require.NoError(t, cmd.RootCmd.PersistentFlags().Set("root", "")) }()
+
+	// the project of --root is locked and changed, instead of the current directory
+	require.NoError(t, updateCompose([]string{"storagenode"}, github))
+	project, err := common.LoadComposeFromFile(dir, common.ComposeFileName)
+	require.NoError(t, err)
+	require.Equal(t, "storj", project.Services["storagenode"].Image)
+	require.Equal(t, dir, project.Services["app-storj"].Build.Context)
+	require.FileExists(t, filepath.Join(dir, "storj.Dockerfile"))
+	require.FileExists(t, filepath.Join(dir, common.LockFileName))
+	entries, err := os.ReadDir(pwd)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestUpdateComposePatch(t *testing.T) {
+	t.Setenv("STORJUP_NO_HISTORY", "1")
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	require.NoError(t, os.MkdirAll(filepath.Join(config, "storj-up", "recipes"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(config, "storj-up", "recipes", "custom.yaml"), []byte(`name: custom
+add:
+  - name: custom
+    image: custom:latest
+    build:
+      image: custom
+      dockerfile: storj.Dockerfile
+      context: src
+`), 0o644))
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fix.diff"), []byte("diff\n"), 0o644))
+	require.NoError(t, common.WriteComposeFile(dir, &types.Project{Name: "storj-up", Services: types.Services{
+		"custom": {Name: "custom", Image: "custom:latest"},
+	}}))
+	patch = "fix.diff"
+	defer func() { patch = "" }()
+
+	// the patch is copied to the build context, where the Dockerfile copies it from
+	require.NoError(t, updateCompose([]string{"custom"}, github))
+	project, err := common.LoadComposeFromFile(dir, common.ComposeFileName)
+	require.NoError(t, err)
+	builder := project.Services["app-custom"]
+	require.Equal(t, filepath.Join(dir, "src"), builder.Build.Context)
+	name := *builder.Build.Args["PATCH"]
+	require.FileExists(t, filepath.Join(dir, "src", patchDir, name))
+	require.NoDirExists(t, filepath.Join(dir, patchDir))
+}
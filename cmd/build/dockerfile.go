@@ -0,0 +1,46 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package build
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var dockerfilePath, buildContext, buildTarget string
+var buildArgs []string
+
+const (
+	dockerfile = "dockerfile"
+)
+
+func dockerfileCmd() *cobra.Command {
+	// NOTE cobra doesn't have a way to document positional parameters:
+	// https://github.com/spf13/cobra/issues/378
+	dockerfileCmd := &cobra.Command{
+		Use:   "dockerfile <selector>...",
+		Short: "build the images of the services with a custom Dockerfile",
+		Long: `build the images of the indicated services with a custom Dockerfile, instead of
+the build defined by the recipe. Each service gets its own builder service
+(app-<service>) and image (storj-up/<service>). See the list of supported service running
+` + "`storj-up services`.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, selector []string) error {
+			err := updateCompose(selector, dockerfile)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	dockerfileCmd.Flags().StringVarP(&dockerfilePath, "file", "f", "", "The path of the Dockerfile")
+	dockerfileCmd.Flags().StringVarP(&buildContext, "context", "c", ".", "The directory of the build context")
+	dockerfileCmd.Flags().StringVarP(&buildTarget, "target", "t", "", "The stage of the Dockerfile to build")
+	dockerfileCmd.Flags().StringArrayVarP(&buildArgs, "arg", "a", nil, "Build argument (KEY=VALUE), can be repeated")
+	_ = dockerfileCmd.MarkFlagRequired("file")
+	return dockerfileCmd
+}
+
+func init() {
+	buildCmd.AddCommand(dockerfileCmd())
+}
@@ -7,11 +7,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var branch, commit, ref string
+var branch, commit, ref, gitURL, gitRef string
+var pr int
 
 const (
 	github = "github"
 	gerrit = "gerrit"
+	git    = "git"
 )
 
 var remoteCmd = &cobra.Command{
@@ -43,6 +45,9 @@ services through positional arguments. See the list of supported service running
 	}
 	githubCmd.PersistentFlags().StringVarP(&branch, "branch", "b", "main", "The branch to checkout and build")
 	githubCmd.PersistentFlags().StringVarP(&commit, "commit", "c", "", "The commit to checkout and build")
+	githubCmd.PersistentFlags().IntVar(&pr, "pr", 0, "The number of the pull request to checkout and build")
+	githubCmd.MarkFlagsMutuallyExclusive("pr", "branch")
+	githubCmd.MarkFlagsMutuallyExclusive("pr", "commit")
 	return githubCmd
 }
 
@@ -69,8 +74,34 @@ services through positional arguments. See the list of supported service running
 	return gerritCmd
 }
 
+func gitCmd() *cobra.Command {
+	// NOTE cobra doesn't have a way to document positional parameters:
+	// https://github.com/spf13/cobra/issues/378
+	gitCmd := &cobra.Command{
+		Use:   "git <selector>...",
+		Short: "build src repo from any git remote for use inside the container",
+		Long: `build src repo from any git remote (like a fork) for use inside the
+container for the indicated services through positional arguments. The ref can be a
+branch, a tag, a commit or any refspec. See the list of supported service running
+` + "`storj-up services`.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, selector []string) error {
+			err := updateCompose(selector, git)
+			if err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+	gitCmd.PersistentFlags().StringVarP(&gitURL, "url", "u", "", "The url of the git repository")
+	gitCmd.PersistentFlags().StringVarP(&gitRef, "ref", "r", "HEAD", "The ref (branch, tag, commit or refspec) to checkout and build")
+	_ = gitCmd.MarkPersistentFlagRequired("url")
+	return gitCmd
+}
+
 func init() {
 	buildCmd.AddCommand(remoteCmd)
 	remoteCmd.AddCommand(githubCmd())
 	remoteCmd.AddCommand(gerritCmd())
+	remoteCmd.AddCommand(gitCmd())
 }
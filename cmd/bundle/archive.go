@@ -0,0 +1,310 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/zeebo/errs/v2"
+)
+
+// writeArchive creates the zstd compressed tar archive. The manifest is the first entry, followed by the bundled files.
+// The archive is written to a temporary file first, and renamed only if it's complete.
+func writeArchive(target string, pwd string, m manifest) (err error) {
+	out, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(out.Name())
+		}
+	}()
+
+	compressed, err := zstd.NewWriter(out)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	tw := tar.NewWriter(compressed)
+
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     manifestName,
+		Mode:     0o644,
+		Size:     int64(len(raw)),
+		ModTime:  m.Created,
+	})
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	_, err = tw.Write(raw)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+
+	for _, f := range m.Files {
+		err = addPath(tw, pwd, f.Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = errs.Combine(tw.Close(), compressed.Close(), out.Close())
+	if err != nil {
+		return err
+	}
+	return errs.Wrap(os.Rename(out.Name(), target))
+}
+
+// addPath adds a file or a directory (recursively) to the archive. Name is relative to the project directory.
+func addPath(tw *tar.Writer, pwd string, name string) error {
+	root := filepath.Join(pwd, filepath.FromSlash(name))
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		var link string
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err = os.Readlink(p)
+			if err != nil {
+				return errs.Wrap(err)
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		rel, err := filepath.Rel(pwd, p)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		header.Name = filepath.ToSlash(rel)
+		// the bundle shouldn't depend on the users of the exporting machine
+		header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+		if err := tw.WriteHeader(header); err != nil {
+			return errs.Wrap(err)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		in, err := os.Open(p)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		_, err = io.Copy(tw, in)
+		return errs.Combine(err, in.Close())
+	})
+}
+
+// readArchive extracts the archive to dir, which must not exist or must be empty. Host specific paths of the
+// exporting machine are replaced with the local ones (hosts) in the files marked for rewrite. The files are extracted
+// to a temporary directory first, which is renamed only if the import is complete. With dryRun, the archive is
+// extracted and checked the same way, but the temporary directory is removed instead.
+func readArchive(source string, dir string, hosts map[string]string, dryRun bool) (_ manifest, err error) {
+	var m manifest
+	entries, err := os.ReadDir(dir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return m, errs.Wrap(err)
+	case len(entries) > 0:
+		return m, errs.Errorf("%s is not empty", dir)
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	defer func() { _ = in.Close() }()
+	compressed, err := zstd.NewReader(in)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	defer compressed.Close()
+	tr := tar.NewReader(compressed)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestName {
+		return m, errs.Errorf("%s is not a storj-up bundle", source)
+	}
+	err = json.NewDecoder(tr).Decode(&m)
+	if err != nil {
+		return m, errs.Errorf("couldn't parse the manifest of %s: %v", source, err)
+	}
+	if m.Version != manifestVersion {
+		return m, errs.Errorf("%s has version %d, but only version %d is supported. Please upgrade storj-up", source, m.Version, manifestVersion)
+	}
+	replacer := newPathReplacer(replacements(m.Hosts, hosts)...)
+
+	parent := ""
+	if !dryRun {
+		parent = filepath.Dir(dir)
+		err = os.MkdirAll(parent, 0o755)
+		if err != nil {
+			return m, errs.Wrap(err)
+		}
+	}
+	tmp, err := os.MkdirTemp(parent, "."+filepath.Base(dir)+".*")
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(tmp)
+		}
+	}()
+
+	root, err := os.OpenRoot(tmp)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	defer func() { _ = root.Close() }()
+	var links []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return m, errs.Errorf("couldn't read %s: %v", source, err)
+		}
+		err = extract(tr, header, root, rewritten(m.Files, header.Name), replacer)
+		if err != nil {
+			return m, err
+		}
+		if header.Typeflag == tar.TypeSymlink {
+			links = append(links, filepath.FromSlash(header.Name))
+		}
+	}
+	// links are checked again when all of them exist, a link can point to a path which is a symlink only later
+	for _, name := range links {
+		link, err := root.Readlink(name)
+		if err != nil {
+			return m, errs.Wrap(err)
+		}
+		if !localLink(root, filepath.Dir(name), link) {
+			return m, errs.Errorf("invalid symlink in the bundle: %s -> %s", filepath.ToSlash(name), link)
+		}
+	}
+
+	if dryRun {
+		return m, errs.Wrap(os.RemoveAll(tmp))
+	}
+	err = os.RemoveAll(dir)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	return m, errs.Wrap(os.Rename(tmp, dir))
+}
+
+// extract writes one entry of the archive to the root (the extraction directory). Writes through symlinks can't
+// leave the root, and symlinks pointing outside of it are rejected.
+func extract(r io.Reader, header *tar.Header, root *os.Root, rewrite bool, replacer *pathReplacer) error {
+	name := filepath.FromSlash(header.Name)
+	if !filepath.IsLocal(name) {
+		return errs.Errorf("invalid path in the bundle: %s", header.Name)
+	}
+	// the exported directories are walked without following symlinks, entries can't be below a symlink
+	for parent := filepath.Dir(name); parent != "."; parent = filepath.Dir(parent) {
+		info, err := root.Lstat(parent)
+		if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+			return errs.Errorf("invalid path in the bundle (below a symlink): %s", header.Name)
+		}
+	}
+	mode := fs.FileMode(header.Mode).Perm()
+	err := root.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return errs.Wrap(root.MkdirAll(name, mode|0o700))
+	case tar.TypeSymlink:
+		link := header.Linkname
+		if rewrite {
+			link = replacer.Replace(link)
+		}
+		if filepath.IsAbs(link) || !filepath.IsLocal(filepath.Join(filepath.Dir(name), link)) {
+			return errs.Errorf("invalid symlink in the bundle: %s -> %s", header.Name, header.Linkname)
+		}
+		return errs.Wrap(root.Symlink(link, name))
+	case tar.TypeReg:
+		if !rewrite {
+			out, err := root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return errs.Wrap(err)
+			}
+			_, err = io.Copy(out, r)
+			return errs.Combine(err, out.Close())
+		}
+		raw := &bytes.Buffer{}
+		_, err := io.Copy(raw, r)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		return errs.Wrap(root.WriteFile(name, []byte(replacer.Replace(raw.String())), mode))
+	default:
+		return errs.Errorf("unsupported entry in the bundle: %s", header.Name)
+	}
+}
+
+// localLink checks if the target of a symlink in dir stays in the root. The target is resolved component by component,
+// and it can't go through other symlinks.
+func localLink(root *os.Root, dir string, link string) bool {
+	if filepath.IsAbs(link) {
+		return false
+	}
+	current := dir
+	parts := strings.Split(filepath.ToSlash(link), "/")
+	for ix, part := range parts {
+		switch part {
+		case "", ".":
+		case "..":
+			if current == "." {
+				return false
+			}
+			current = filepath.Dir(current)
+		default:
+			current = filepath.Join(current, part)
+			if ix == len(parts)-1 {
+				continue
+			}
+			info, err := root.Lstat(current)
+			if err == nil && info.Mode()&fs.ModeSymlink != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rewritten checks if the host specific paths should be replaced in the content of an archive entry.
+func rewritten(files []file, name string) bool {
+	name = path.Clean(name)
+	for _, f := range files {
+		if f.Path == name || strings.HasPrefix(name, f.Path+"/") {
+			return f.Rewrite
+		}
+	}
+	return false
+}
@@ -0,0 +1,384 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package bundle contains the commands to move an environment to an other directory or machine.
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/common/composedb"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/hybrid"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/runtime/standalone"
+)
+
+// manifestName is the name of the bundle description inside the archive.
+const manifestName = "storj-up.bundle.json"
+
+// manifestVersion is the current version of the bundle format.
+const manifestVersion = 1
+
+// Kinds of the bundled files.
+const (
+	kindDescriptor  = "descriptor"
+	kindGenerated   = "generated"
+	kindMetadata    = "metadata"
+	kindIdentity    = "identity"
+	kindCredentials = "credentials"
+	kindData        = "data"
+)
+
+// extractedFiles are the files generated next to docker-compose.yaml by the build commands.
+var extractedFiles = []string{"storj.Dockerfile", "edge.Dockerfile"}
+
+// manifest describes the content of a bundle.
+type manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	// Hosts are the host specific directories of the exporting machine (project, home, storj, gateway), which are
+	// replaced with the local ones during the import.
+	Hosts map[string]string `json:"hosts"`
+	Files []file            `json:"files"`
+}
+
+// file is a bundled file or directory.
+type file struct {
+	// Path is relative to the project directory, with slash separators.
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Rewrite is true if the host specific paths should be replaced in the content.
+	Rewrite bool `json:"rewrite,omitempty"`
+}
+
+func init() {
+	bundleCmd := &cobra.Command{
+		Use:   "bundle",
+		Args:  cobra.NoArgs,
+		Short: "export the environment to a portable archive, or import an archive to a new directory",
+	}
+	bundleCmd.AddCommand(exportCmd(), importCmd())
+	cmd.RootCmd.AddCommand(bundleCmd)
+}
+
+func exportCmd() *cobra.Command {
+	var data, credentials bool
+	exportCmd := &cobra.Command{
+		Use:   "export <file.tar.zst>",
+		Short: "pack the environment to a zstd compressed tar archive",
+		Long: "Pack the runtime descriptor (docker-compose.yaml or the standalone state), the history, identities and " +
+			"configuration files, the generated files (files of the recipes, scripts), the credentials and optionally the " +
+			"persisted data to an archive. Named volumes and the data inside the containers are not included (see snapshot).",
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) (err error) {
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			pwd, err = filepath.Abs(pwd)
+			if err != nil {
+				return errs.Wrap(err)
+			}
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+
+			files, err := collect(pwd, data, credentials)
+			if err != nil {
+				return err
+			}
+			m := manifest{
+				Version: manifestVersion,
+				Created: time.Now(),
+				Hosts:   hostDirs(pwd),
+				Files:   files,
+			}
+			err = writeArchive(args[0], pwd, m)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Environment is exported to %s (%s)\n", args[0], summary(files))
+			return nil
+		},
+	}
+	exportCmd.Flags().BoolVar(&data, "data", false, "include the persisted data of the services (directories on the host)")
+	exportCmd.Flags().BoolVar(&credentials, "credentials", true, "include the saved credentials ("+cmd.CredentialsFileName+")")
+	return exportCmd
+}
+
+func importCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file.tar.zst> [<dir>]",
+		Short: "unpack an exported environment to a new directory",
+		Long: "Unpack the archive to a new directory (the name of the archive without extensions, by default). Absolute " +
+			"paths of the exporting machine (project directory, home directory, STORJ_PROJECT_DIR, GATEWAY_PROJECT_DIR) are " +
+			"replaced with the local ones.",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			dir := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(args[0]), ".zst"), ".tar")
+			if len(args) > 1 {
+				dir = args[1]
+			}
+			dir, err := filepath.Abs(dir)
+			if err != nil {
+				return errs.Wrap(err)
+			}
+			hosts := hostDirs(dir)
+			m, err := readArchive(args[0], dir, hosts, cmd.DryRun)
+			if err != nil {
+				return err
+			}
+			if cmd.DryRun {
+				pairs := replacements(m.Hosts, hosts)
+				for i := 0; i+1 < len(pairs); i += 2 {
+					fmt.Printf("Would replace %s with %s\n", pairs[i], pairs[i+1])
+				}
+				fmt.Printf("Environment would be imported to %s (%s)\n", dir, summary(m.Files))
+				return nil
+			}
+			for _, warning := range missingMounts(dir) {
+				fmt.Println("WARNING: " + warning)
+			}
+			fmt.Printf("Environment is imported to %s (%s)\n", dir, summary(m.Files))
+			return nil
+		},
+	}
+}
+
+// collect returns with the files of the environment which are included in the bundle.
+func collect(pwd string, data bool, credentials bool) ([]file, error) {
+	rt, err := cmd.FromDir(pwd)
+	if err != nil {
+		return nil, err
+	}
+	st, err := recipe.GetStack()
+	if err != nil {
+		return nil, err
+	}
+	err = rt.Reload(st)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []file
+	add := func(path string, kind string, rewrite bool) {
+		rel, err := filepath.Rel(pwd, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		rel = filepath.ToSlash(rel)
+		if _, err := os.Lstat(path); err != nil {
+			return
+		}
+		if slices.ContainsFunc(files, func(f file) bool { return f.Path == rel || strings.HasPrefix(rel, f.Path+"/") }) {
+			return
+		}
+		files = append(files, file{Path: rel, Kind: kind, Rewrite: rewrite})
+	}
+
+	for _, name := range []string{common.ComposeFileName, standalone.StateFileName} {
+		add(filepath.Join(pwd, name), kindDescriptor, true)
+	}
+	add(filepath.Join(pwd, composedb.HistoryDir), kindMetadata, true)
+	if credentials {
+		add(filepath.Join(pwd, cmd.CredentialsFileName), kindCredentials, false)
+	}
+
+	var native *standalone.Standalone
+	switch r := rt.(type) {
+	case *standalone.Standalone:
+		native = r
+	case *hybrid.Hybrid:
+		native = r.Native()
+	}
+	if native != nil {
+		for _, name := range native.Generated() {
+			add(filepath.Join(pwd, name), kindGenerated, true)
+		}
+	}
+	for _, name := range extractedFiles {
+		add(filepath.Join(pwd, name), kindGenerated, false)
+	}
+
+	var states []runtime.ServiceState
+	if cleaner, ok := rt.(runtime.Cleaner); ok {
+		for _, s := range rt.GetServices() {
+			var persisted []string
+			if rService, err := st.FindRecipeByName(s.ID().Name); err == nil {
+				persisted = rService.Persistence
+			}
+			states = append(states, cleaner.State(s, persisted))
+		}
+	}
+	for _, state := range states {
+		for _, path := range state.Config {
+			add(path, kindIdentity, true)
+		}
+	}
+
+	// files of the recipes are mounted from the project directory
+	for _, s := range rt.GetServices() {
+		if _, ok := s.(*compose.Service); !ok {
+			continue
+		}
+		for _, mount := range s.GetVolumes() {
+			if mount.MountType != "bind" || isData(states, mount.Source) {
+				continue
+			}
+			add(mount.Source, kindGenerated, false)
+		}
+	}
+
+	if data {
+		for _, state := range states {
+			for _, path := range state.Paths {
+				add(path, kindData, false)
+			}
+			if len(state.Volumes) > 0 || len(state.Internal) > 0 {
+				fmt.Printf("WARNING: data of %s in docker volumes or inside the container is not included\n", state.Name)
+			}
+		}
+	}
+	return files, nil
+}
+
+func isData(states []runtime.ServiceState, path string) bool {
+	for _, state := range states {
+		if slices.Contains(state.Paths, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostDirs returns with the host specific directories of the current machine.
+func hostDirs(pwd string) map[string]string {
+	dirs := map[string]string{
+		"project": pwd,
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs["home"] = home
+	}
+	if storj := os.Getenv("STORJ_PROJECT_DIR"); storj != "" {
+		dirs["storj"] = storj
+	}
+	if gateway := os.Getenv("GATEWAY_PROJECT_DIR"); gateway != "" {
+		dirs["gateway"] = gateway
+	}
+	return dirs
+}
+
+// replacements returns with the old/new pairs for newPathReplacer. Longer paths are replaced first, as the project
+// directory can be inside the home directory.
+func replacements(exported map[string]string, local map[string]string) []string {
+	var keys []string
+	for key, old := range exported {
+		if current, found := local[key]; found && old != "" && old != current {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(exported[keys[i]]) > len(exported[keys[j]])
+	})
+	var pairs []string
+	for _, key := range keys {
+		pairs = append(pairs, filepath.ToSlash(exported[key]), filepath.ToSlash(local[key]))
+		if filepath.Separator != '/' {
+			pairs = append(pairs, exported[key], local[key])
+		}
+	}
+	return pairs
+}
+
+// pathReplacer replaces paths only at path segment boundaries: /home/al is not replaced in /home/alex or /x/home/al.
+type pathReplacer struct {
+	pairs []string
+}
+
+func newPathReplacer(pairs ...string) *pathReplacer {
+	return &pathReplacer{pairs: pairs}
+}
+
+// Replace returns with s, where all the old paths are replaced with the new ones.
+func (r *pathReplacer) Replace(s string) string {
+	if len(r.pairs) == 0 {
+		return s
+	}
+	out := strings.Builder{}
+	for ix := 0; ix < len(s); {
+		replaced := false
+		if ix == 0 || !segmentChar(s[ix-1]) && s[ix-1] != '/' && s[ix-1] != '\\' {
+			for p := 0; p+1 < len(r.pairs); p += 2 {
+				old := r.pairs[p]
+				end := ix + len(old)
+				if strings.HasPrefix(s[ix:], old) && (end == len(s) || !segmentChar(s[end])) {
+					out.WriteString(r.pairs[p+1])
+					ix = end
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			out.WriteByte(s[ix])
+			ix++
+		}
+	}
+	return out.String()
+}
+
+// segmentChar checks if a character can be part of a path segment (name).
+func segmentChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("._-~+@", c) >= 0 || c >= 0x80
+}
+
+// missingMounts checks the bind mounts of the imported compose file, which are not available on this machine.
+func missingMounts(dir string) []string {
+	project, err := common.LoadComposeFromFile(dir, common.ComposeFileName)
+	if err != nil {
+		return nil
+	}
+	var warnings []string
+	for _, s := range project.Services {
+		for _, v := range s.Volumes {
+			if v.Type != "bind" || strings.HasPrefix(v.Source, dir) {
+				continue
+			}
+			if _, err := os.Stat(v.Source); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s is mounted to %s, but it doesn't exist on this machine", v.Source, s.Name))
+			}
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// summary returns with the number of bundled items by kind.
+func summary(files []file) string {
+	counts := map[string]int{}
+	for _, f := range files {
+		counts[f.Kind]++
+	}
+	var parts []string
+	for _, kind := range []string{kindDescriptor, kindGenerated, kindMetadata, kindIdentity, kindCredentials, kindData} {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,150 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package bundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "storagenode", "0"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "docker-compose.yaml"), []byte("source: "+src+"/identities\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "storagenode", "0", "config.yaml"), []byte("storage.path: "+src+"/storagenode/0/storage\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "storagenode", "0", "run.sh"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".creds"), []byte(src), 0o600))
+	require.NoError(t, os.Symlink("0", filepath.Join(src, "storagenode", "latest")))
+
+	target := filepath.Join(t.TempDir(), "env.tar.zst")
+	require.NoError(t, writeArchive(target, src, manifest{
+		Version: manifestVersion,
+		Created: time.Now(),
+		Hosts:   map[string]string{"project": src},
+		Files: []file{
+			{Path: "docker-compose.yaml", Kind: kindDescriptor, Rewrite: true},
+			{Path: "storagenode", Kind: kindIdentity, Rewrite: true},
+			{Path: ".creds", Kind: kindCredentials},
+		},
+	}))
+
+	dst := filepath.Join(t.TempDir(), "imported")
+	// a dry-run checks the archive without creating anything next to the target
+	m, err := readArchive(target, dst, map[string]string{"project": dst}, true)
+	require.NoError(t, err)
+	require.Len(t, m.Files, 3)
+	entries, err := os.ReadDir(filepath.Dir(dst))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	m, err = readArchive(target, dst, map[string]string{"project": dst}, false)
+	require.NoError(t, err)
+	require.Len(t, m.Files, 3)
+
+	content, err := os.ReadFile(filepath.Join(dst, "docker-compose.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "source: "+dst+"/identities\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dst, "storagenode", "0", "config.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, "storage.path: "+dst+"/storagenode/0/storage\n", string(content))
+	// files which are not marked for rewrite are kept as is
+	content, err = os.ReadFile(filepath.Join(dst, ".creds"))
+	require.NoError(t, err)
+	require.Equal(t, src, string(content))
+	info, err := os.Stat(filepath.Join(dst, "storagenode", "0", "run.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+	link, err := os.Readlink(filepath.Join(dst, "storagenode", "latest"))
+	require.NoError(t, err)
+	require.Equal(t, "0", link)
+
+	_, err = readArchive(target, dst, map[string]string{"project": dst}, false)
+	require.ErrorContains(t, err, "is not empty")
+	_, err = readArchive(filepath.Join(dst, ".creds"), filepath.Join(t.TempDir(), "other"), nil, false)
+	require.Error(t, err)
+}
+
+func TestArchiveTraversal(t *testing.T) {
+	outside := t.TempDir()
+	for name, entries := range map[string][]tar.Header{
+		"absolute":  {{Name: "a", Typeflag: tar.TypeSymlink, Linkname: outside}, {Name: "a/passwd", Typeflag: tar.TypeReg}},
+		"relative":  {{Name: "x/a", Typeflag: tar.TypeSymlink, Linkname: "../../" + filepath.Base(outside)}},
+		"parent":    {{Name: "../passwd", Typeflag: tar.TypeReg}},
+		"resolved":  {{Name: "x/a", Typeflag: tar.TypeSymlink, Linkname: ".."}, {Name: "x/b", Typeflag: tar.TypeSymlink, Linkname: "a/.."}, {Name: "x/b/passwd", Typeflag: tar.TypeReg}},
+		"later":     {{Name: "x/b", Typeflag: tar.TypeSymlink, Linkname: "a/.."}, {Name: "x/a", Typeflag: tar.TypeSymlink, Linkname: ".."}},
+		"duplicate": {{Name: "x/a", Typeflag: tar.TypeSymlink, Linkname: ".."}, {Name: "x/a/b", Typeflag: tar.TypeSymlink, Linkname: "../.."}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			source := filepath.Join(t.TempDir(), "bundle.tar.zst")
+			writeRawArchive(t, source, entries)
+			dst := filepath.Join(t.TempDir(), "imported")
+			_, err := readArchive(source, dst, nil, false)
+			require.Error(t, err)
+			require.NoDirExists(t, dst)
+			content, err := os.ReadDir(outside)
+			require.NoError(t, err)
+			require.Empty(t, content)
+		})
+	}
+}
+
+// writeRawArchive writes a bundle with arbitrary entries (with the content "x" for regular files).
+func writeRawArchive(t *testing.T, target string, entries []tar.Header) {
+	out, err := os.Create(target)
+	require.NoError(t, err)
+	compressed, err := zstd.NewWriter(out)
+	require.NoError(t, err)
+	tw := tar.NewWriter(compressed)
+	raw, err := json.Marshal(manifest{Version: manifestVersion})
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: manifestName, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(raw))}))
+	_, err = tw.Write(raw)
+	require.NoError(t, err)
+	for _, header := range entries {
+		header.Mode = 0o644
+		if header.Typeflag == tar.TypeReg {
+			header.Size = 1
+		}
+		require.NoError(t, tw.WriteHeader(&header))
+		if header.Typeflag == tar.TypeReg {
+			_, err = tw.Write([]byte("x"))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, compressed.Close())
+	require.NoError(t, out.Close())
+}
+
+func TestReplacements(t *testing.T) {
+	exported := map[string]string{"home": "/home/alice", "project": "/home/alice/env", "storj": "/src/storj"}
+	local := map[string]string{"home": "/home/bob", "project": "/tmp/env", "gateway": "/src/gateway"}
+	require.Equal(t, []string{"/home/alice/env", "/tmp/env", "/home/alice", "/home/bob"}, replacements(exported, local))
+	require.Empty(t, replacements(exported, exported))
+}
+
+func TestPathReplacer(t *testing.T) {
+	r := newPathReplacer(replacements(
+		map[string]string{"home": "/home/al", "project": "/home/al/env"},
+		map[string]string{"home": "/home/bob", "project": "/tmp/env"})...)
+	require.Equal(t, "source: /tmp/env/identities", r.Replace("source: /home/al/env/identities"))
+	require.Equal(t, "/home/bob/envx /home/alex/env", r.Replace("/home/al/envx /home/alex/env"))
+	require.Equal(t, "'/home/bob':/tmp/env\n/x/home/al", r.Replace("'/home/al':/home/al/env\n/x/home/al"))
+	require.Equal(t, "/home/bob", r.Replace("/home/al"))
+	require.Equal(t, "/home/al", newPathReplacer().Replace("/home/al"))
+}
+
+func TestRewritten(t *testing.T) {
+	files := []file{{Path: ".history", Rewrite: true}, {Path: "storagenode"}}
+	require.True(t, rewritten(files, ".history/1.yaml"))
+	require.False(t, rewritten(files, "storagenode/0/data"))
+	require.False(t, rewritten(files, ".historyx"))
+}
@@ -0,0 +1,244 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/supervisor"
+)
+
+// extractedFiles are the files generated next to docker-compose.yaml by the build commands.
+var extractedFiles = []string{"storj.Dockerfile", "edge.Dockerfile"}
+
+func cleanCmd() *cobra.Command {
+	var data, history, creds, all bool
+	cmd := &cobra.Command{
+		Use:   "clean [<selector>...]",
+		Short: "remove the state of the services (databases, storagenode data), the history or the credentials, but keep the configuration",
+		Long: "Remove the data of the selected services (all, if no selector is given), so the next start begins with a fresh " +
+			"database and fresh nodes. Affected services are stopped (containers are removed) first. docker-compose.yaml, " +
+			"supervisord.conf and the generated scripts are kept. Without flags, only the data is removed. " + SelectorHelp,
+		RunE: func(cmd *cobra.Command, selectors []string) (err error) {
+			if all {
+				data, history, creds = true, true, true
+			}
+			if !data && !history && !creds {
+				data = true
+			}
+
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+
+			if data {
+				err = CleanData(cmd.Context(), pwd, selectors)
+				if err != nil {
+					return err
+				}
+			}
+			if history {
+				if DryRun {
+					fmt.Println("Would remove the history of " + common.ComposeFileName)
+				} else {
+					fmt.Println("Removing the history of " + common.ComposeFileName)
+					err = common.Store.Clear()
+					if err != nil {
+						return err
+					}
+				}
+			}
+			var files []string
+			if creds {
+				files = append(files, filepath.Join(pwd, CredentialsFileName))
+			}
+			if all {
+				for _, f := range extractedFiles {
+					files = append(files, filepath.Join(pwd, f))
+				}
+			}
+			return removePaths(cmd.Context(), files)
+		},
+	}
+	cmd.Flags().BoolVar(&data, "data", false, "remove the persisted data of the services (default, if no other flag is used)")
+	cmd.Flags().BoolVar(&history, "history", false, "remove the history of "+common.ComposeFileName+" (used by undo)")
+	cmd.Flags().BoolVar(&creds, "credentials", false, "remove the saved credentials ("+CredentialsFileName+")")
+	cmd.Flags().BoolVar(&all, "all", false, "remove data, history, credentials and the extracted Dockerfiles")
+	return cmd
+}
+
+// CleanData stops the selected services (all, if no selector is given) and removes their state.
+func CleanData(ctx context.Context, pwd string, selectors []string) error {
+	rt, err := FromDir(pwd)
+	if err != nil {
+		return err
+	}
+	st, err := recipe.GetStack()
+	if err != nil {
+		return err
+	}
+	err = rt.Reload(st)
+	if err != nil {
+		return err
+	}
+	cleaner, ok := rt.(runtime.Cleaner)
+	if !ok {
+		return errs.Errorf("clean is not supported by this runtime")
+	}
+
+	services := rt.GetServices()
+	if len(selectors) > 0 {
+		services = nil
+		err = runtime.ModifyService(st, rt, selectors, func(s runtime.Service) error {
+			if !slices.ContainsFunc(services, func(o runtime.Service) bool { return o.ID() == s.ID() }) {
+				services = append(services, s)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var containers, processes, paths, volumes []string
+	for _, s := range services {
+		var persisted []string
+		if rService, err := st.FindRecipeByName(s.ID().Name); err == nil {
+			persisted = rService.Persistence
+		}
+		state := cleaner.State(s, persisted)
+		if state.Name == "" {
+			continue
+		}
+		if state.Container {
+			containers = append(containers, state.Name)
+		} else {
+			processes = append(processes, state.Name)
+		}
+		paths = append(paths, state.Paths...)
+		volumes = append(volumes, state.Volumes...)
+	}
+
+	slices.Sort(containers)
+	slices.Sort(processes)
+
+	// containers are removed, not only stopped: files which are not persisted (like the migration marker) are
+	// stored in the container.
+	if len(containers) > 0 {
+		err = runDocker(pwd, append([]string{"compose", "rm", "--stop", "--force", "-v"}, containers...))
+		if err != nil {
+			return err
+		}
+	}
+	if len(processes) > 0 {
+		StopProcesses(ctx, pwd, processes)
+	}
+	err = removePaths(ctx, paths)
+	if err != nil {
+		return err
+	}
+	if len(volumes) > 0 {
+		err = runDocker(pwd, append([]string{"volume", "rm", "--force"}, volumes...))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDocker(pwd string, args []string) error {
+	if DryRun {
+		fmt.Println("Would execute docker " + strings.Join(args, " "))
+		return nil
+	}
+	docker := exec.Command("docker", args...)
+	docker.Dir = pwd
+	docker.Stdout = os.Stdout
+	docker.Stderr = os.Stderr
+	err := docker.Run()
+	if err != nil {
+		return errs.Errorf("couldn't execute docker %s: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// StopProcesses stops the native processes, if they are supervised by `storj-up run` or by supervisord. Processes
+// which are not running are ignored.
+func StopProcesses(ctx context.Context, pwd string, names []string) {
+	controlProcesses(ctx, pwd, "stop", (*supervisor.Client).Stop, names)
+}
+
+// StartProcesses starts the native processes again, if they are supervised by `storj-up run` or by supervisord.
+func StartProcesses(ctx context.Context, pwd string, names []string) {
+	controlProcesses(ctx, pwd, "start", (*supervisor.Client).Start, names)
+}
+
+// RestartProcesses restarts the native processes, if they are supervised by `storj-up run` or by supervisord.
+func RestartProcesses(ctx context.Context, pwd string, names []string) {
+	controlProcesses(ctx, pwd, "restart", (*supervisor.Client).Restart, names)
+}
+
+func controlProcesses(ctx context.Context, pwd string, op string, call func(c *supervisor.Client, ctx context.Context, names ...string) ([]supervisor.Status, error), names []string) {
+	if DryRun {
+		fmt.Printf("Would %s %s\n", op, strings.Join(names, ", "))
+		return
+	}
+	if _, err := os.Stat(filepath.Join(pwd, supervisor.SocketName)); err == nil {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		_, err = call(supervisor.NewClient(filepath.Join(pwd, supervisor.SocketName)), ctx, names...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't %s the services with `storj-up run %s`: %v\n", op, op, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(pwd, "supervisord.conf")); err == nil {
+		if _, err := exec.LookPath("supervisorctl"); err == nil {
+			ctl := exec.CommandContext(ctx, "supervisorctl", append([]string{"-c", "supervisord.conf", op}, names...)...)
+			ctl.Dir = pwd
+			// fails if supervisord is not running, which is fine.
+			_ = ctl.Run()
+		}
+	}
+}
+
+func removePaths(ctx context.Context, paths []string) error {
+	for _, path := range paths {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		if DryRun {
+			fmt.Println("Would remove " + path)
+			continue
+		}
+		fmt.Println("Removing " + path)
+		err := removeAll(ctx, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(cleanCmd())
+}
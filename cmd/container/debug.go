@@ -4,14 +4,11 @@
 package container
 
 import (
-	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs/v2"
 
 	"storj.io/storj-up/cmd"
-	"storj.io/storj-up/pkg/common"
 	"storj.io/storj-up/pkg/recipe"
-	"storj.io/storj-up/pkg/runtime/compose"
 	"storj.io/storj-up/pkg/runtime/runtime"
 )
 
@@ -19,9 +16,11 @@ func enableDebugCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "enable <selector>...",
 		Short: "turn on local debugging with Delve (go debugger)",
-		Long:  "Add environment variable which will activate the DLV debug. Container won't start until the agent is connected. " + cmd.SelectorHelp,
-		Args:  cobra.MinimumNArgs(1),
-		RunE:  cmd.ExecuteStorjUP(enableDebug),
+		Long: "Start the selected services with a headless Delve server, listening on a unique port per instance. " +
+			"Containers won't start until the debugger is connected. Remote debug configurations are generated to " +
+			".vscode/launch.json and .run/ (GoLand). " + cmd.SelectorHelp,
+		Args: cobra.MinimumNArgs(1),
+		RunE: cmd.ExecuteStorjUP(enableDebug),
 	}
 }
 
@@ -47,48 +46,19 @@ func init() {
 }
 
 func enableDebug(st recipe.Stack, rt runtime.Runtime, selector []string) error {
-	return runtime.ModifyService(st, rt, selector, func(s runtime.Service) error {
-		composeService, ok := s.(*compose.Service)
-		if !ok {
-			return errs.Errorf("this subcommand is supported only for compose based environments")
-		}
-		return composeService.TransformRaw(func(composeService *types.ServiceConfig) error {
-			tr := "true"
-			composeService.Environment["GO_DLV"] = &tr
-			for _, portConfig := range composeService.Ports {
-				if portConfig.Mode == "ingress" &&
-					portConfig.Target == 2345 &&
-					portConfig.Published == "2345" &&
-					portConfig.Protocol == "tcp" {
-					return nil
-				}
-			}
-			composeService.Ports = append(composeService.Ports, types.ServicePortConfig{
-				Mode:      "ingress",
-				Target:    2345,
-				Published: "2345",
-				Protocol:  "tcp",
-			})
-			return nil
-		})
-	})
+	return setDebug(st, rt, selector, true)
+}
+
+func disableDebug(st recipe.Stack, rt runtime.Runtime, selector []string) error {
+	return setDebug(st, rt, selector, false)
 }
 
-func disableDebug(st recipe.Stack, rt runtime.Runtime, args []string) error {
-	selector, _ := common.SplitArgsSelector1(args) // TODO: doesn't look right
+func setDebug(st recipe.Stack, rt runtime.Runtime, selector []string, enabled bool) error {
 	return runtime.ModifyService(st, rt, selector, func(s runtime.Service) error {
-		composeService, ok := s.(*compose.Service)
+		debuggable, ok := s.(runtime.Debuggable)
 		if !ok {
-			return errs.Errorf("this subcommand is supported only for compose based environments")
+			return errs.Errorf("debugging is not supported for %s", s.ID())
 		}
-		return composeService.TransformRaw(func(composeService *types.ServiceConfig) error {
-			delete(composeService.Environment, "GO_DLV")
-			for i, port := range composeService.Ports {
-				if port.Target == 2345 {
-					composeService.Ports = append(composeService.Ports[:i], composeService.Ports[i+1:]...)
-				}
-			}
-			return nil
-		})
+		return debuggable.SetDebug(enabled)
 	})
 }
@@ -6,7 +6,6 @@ package container
 import (
 	"strconv"
 
-	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs/v2"
 
@@ -21,15 +20,11 @@ func scaleCmd() *cobra.Command {
 		Use:   "scale <selector>... <number>",
 		Short: "static scale of services",
 		Args:  cobra.MinimumNArgs(2),
-		Long: "This command creates multiple instances of the service or services. After this scale services couldn't be scaled up with `docker compose scale` any more. " +
-			"But also not required to scale up and down and it's possible to do per instance local bindmount",
-		RunE: cmd.ExecuteStorjUP(func(st recipe.Stack, rt runtime.Runtime, args []string) error {
-			selector, number := common.SplitArgsSelector1(args)
-			_ = number
-			return cmd.ChangeCompose(st, rt, selector, func(composeService *types.ServiceConfig) error {
-				return scale(composeService, args[0]) // TODO: doesn't look right
-			})
-		}),
+		Long: "This command adds or removes instances of the service or services. Each instance has its own identity, ports, " +
+			"persistence and contact address (same as the instances created by the recipes). Scaling down removes the highest numbered instances " +
+			"(the directory of a removed standalone instance, with its identity and data, is deleted). " +
+			"Works for both compose and standalone environments. " + cmd.SelectorHelp,
+		RunE: cmd.ExecuteStorjUP(scale),
 	}
 }
 
@@ -37,17 +32,11 @@ func init() {
 	cmd.RootCmd.AddCommand(scaleCmd())
 }
 
-func scale(composeService *types.ServiceConfig, scale string) error {
-	instances, err := strconv.Atoi(scale)
+func scale(st recipe.Stack, rt runtime.Runtime, args []string) error {
+	selector, number := common.SplitArgsSelector1(args)
+	instances, err := strconv.Atoi(number)
 	if err != nil {
-		return errs.Wrap(err)
+		return errs.Errorf("number of instances should be a number, not %s", number)
 	}
-	if instances == 1 {
-		composeService.Deploy = nil
-	} else if composeService.Deploy == nil {
-		composeService.Deploy = &types.DeployConfig{Replicas: &instances}
-	} else {
-		*composeService.Deploy.Replicas = instances
-	}
-	return nil
+	return runtime.Scale(st, rt, selector, instances)
 }
@@ -8,16 +8,47 @@ import (
 
 	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
 )
 
 func TestScale(t *testing.T) {
-	k := types.ServiceConfig{
-		Name:  "storagenode",
-		Image: "foobar",
-	}
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
 
-	err := scale(&k, "10")
+	rt, err := compose.NewCompose(t.TempDir())
 	require.NoError(t, err)
+	require.NoError(t, runtime.ApplyRecipes(st, rt, []string{"minimal", "tracing"}, 0))
+
+	require.NoError(t, scale(st, rt, []string{"storagenode", "12"}))
+	require.Len(t, instances(rt, "storagenode"), 12)
+
+	// new instances have their own address, and they are modified by the recipes of the environment
+	node12 := instances(rt, "storagenode")[runtime.NewServiceInstance("storagenode", 11)]
+	require.NotNil(t, node12)
+	require.Equal(t, "storagenode12:30111", *node12.GetENV()["STORJ_CONTACT_EXTERNAL_ADDRESS"])
+	require.NoError(t, node12.(*compose.Service).TransformRaw(func(config *types.ServiceConfig) error {
+		require.Contains(t, config.Command, "--tracing.enabled=true")
+		return nil
+	}))
 
-	require.Equal(t, 10, *k.Deploy.Replicas)
+	require.NoError(t, scale(st, rt, []string{"storagenode", "3"}))
+	nodes := instances(rt, "storagenode")
+	require.Len(t, nodes, 3)
+	require.Contains(t, nodes, runtime.NewServiceInstance("storagenode", 2))
+
+	require.Error(t, scale(st, rt, []string{"storagenode", "0"}))
+	require.Error(t, scale(st, rt, []string{"storagenode", "x"}))
+}
+
+func instances(rt runtime.Runtime, name string) map[runtime.ServiceInstance]runtime.Service {
+	res := map[runtime.ServiceInstance]runtime.Service{}
+	for _, s := range rt.GetServices() {
+		if s.ID().Name == name {
+			res[s.ID()] = s
+		}
+	}
+	return res
 }
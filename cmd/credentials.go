@@ -7,7 +7,6 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base32"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -20,20 +19,21 @@ import (
 	"github.com/spf13/viper"
 	"github.com/zeebo/errs/v2"
 
-	"storj.io/common/uuid"
 	pkg "storj.io/storj-up/pkg"
-	"storj.io/storj/web/satellite/wasm/consolewasm"
+	"storj.io/storj-up/pkg/up"
 )
 
 const (
 	encKeyVersionByte = byte(77) // magic number EncryptionKey encoding
 	secKeyVersionByte = byte(78) // magic number SecretKey encoding
 
-	password = "password"
-	secret   = "Welcome1"
-	filename = ".creds"
+	password = up.Password
+	secret   = up.EncryptionSecret
 )
 
+// CredentialsFileName is the file where the generated credentials are saved.
+const CredentialsFileName = ".creds"
+
 var (
 	base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
 
@@ -56,20 +56,7 @@ type EncryptionKey [16]byte
 type SecretKey [32]byte
 
 // Credentials is the structure of the credentials file.
-type Credentials struct {
-	StorjUser     string `json:"email"`
-	StorjPassword string `json:"password"`
-
-	ProjectID        string `json:"ProjectID"`
-	Cookie           string `json:"Cookie"`
-	ApiKey           string `json:"ApiKey"`
-	EncryptionSecret string `json:"EncryptionSecret"`
-	Grant            string `json:"Grant"`
-
-	AccessKey string `json:"AccessKey,omitempty"`
-	SecretKey string `json:"SecretKey,omitempty"`
-	Endpoint  string `json:"Endpoint,omitempty"`
-}
+type Credentials = up.Credentials
 
 func credentialsCmd() *cobra.Command {
 	credentialsCmd := &cobra.Command{
@@ -77,7 +64,7 @@ func credentialsCmd() *cobra.Command {
 		Args:  cobra.NoArgs,
 		Short: "generate test user with credentials",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			_, err := os.Stat(filename)
+			_, err := os.Stat(CredentialsFileName)
 			if err != nil || persist {
 				err = executeWithRetry(context.Background(), generateCredentials)
 				if err != nil {
@@ -179,36 +166,14 @@ func generateCredentials(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	satelliteNodeURL, err := pkg.GetSatelliteID(ctx, satelliteHost)
-	if err != nil {
-		return errs.Wrap(err)
-	}
-
-	consoleEndpoint := pkg.NewConsoleEndpoints(consoleHost, credentials.StorjUser)
-	err = consoleEndpoint.Login(ctx)
-	if err != nil {
-		return errs.Wrap(err)
-	}
-
-	credentials.ProjectID, credentials.Cookie, err = consoleEndpoint.GetOrCreateProject(ctx)
-	if err != nil {
-		return errs.Wrap(err)
-	}
-
-	credentials.ApiKey, err = consoleEndpoint.CreateAPIKey(ctx, credentials.ProjectID)
-	if err != nil {
-		return errs.Wrap(err)
-	}
-
-	projectUUID, err := uuid.FromString(credentials.ProjectID)
+	generated, err := up.GenerateCredentials(ctx, satelliteHost, consoleHost, credentials.StorjUser)
 	if err != nil {
-		return errs.Wrap(err)
-	}
-
-	credentials.Grant, err = consolewasm.GenAccessGrant(satelliteNodeURL+"@"+satelliteHost, credentials.ApiKey, secret, base64.StdEncoding.EncodeToString(projectUUID.Bytes()), true)
-	if err != nil {
-		return errs.Wrap(err)
+		return err
 	}
+	credentials.ProjectID = generated.ProjectID
+	credentials.Cookie = generated.Cookie
+	credentials.ApiKey = generated.ApiKey
+	credentials.Grant = generated.Grant
 
 	if s3 {
 		err = registerS3Credentials(ctx)
@@ -243,7 +208,7 @@ func persistCredentials() error {
 	if err != nil {
 		return errs.Wrap(err)
 	}
-	err = os.WriteFile(filename, file, 0644)
+	err = os.WriteFile(CredentialsFileName, file, 0644)
 	if err != nil {
 		return errs.Wrap(err)
 	}
@@ -251,7 +216,7 @@ func persistCredentials() error {
 }
 
 func loadCredentials() error {
-	file, err := os.ReadFile(filename)
+	file, err := os.ReadFile(CredentialsFileName)
 	if err != nil {
 		return errs.Wrap(err)
 	}
@@ -0,0 +1,103 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// migrationDir is the directory of the migration marker of satellite-api (see entrypoint.sh). Migrations are executed
+// at start, if the marker is missing.
+const migrationDir = "/var/lib/storj/.local"
+
+func dbCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "manage the database backend of the satellite services",
+	}
+	cmd.AddCommand(dbUseCmd())
+	return cmd
+}
+
+func dbUseCmd() *cobra.Command {
+	var resetMigration bool
+	cmd := &cobra.Command{
+		Use:   "use " + strings.Join(runtime.Databases, "|"),
+		Args:  cobra.ExactArgs(1),
+		Short: "switch the satellite services to another database backend",
+		Long: "Removes the services of the other database backends (unless other services, like storjscan, use them), adds the services of the selected one, and " +
+			"rewrites the database configuration (STORJ_DATABASE, STORJ_METAINFO_DATABASE_URL, SPANNER_EMULATOR_HOST) " +
+			"of every satellite service. With --reset-migration, the migration marker of satellite-api is removed " +
+			"(the container is removed), so the migrations are executed against the new database at the next start.",
+		ValidArgs: runtime.Databases,
+		RunE: executeStorjUP(func(st recipe.Stack, rt runtime.Runtime, args []string) error {
+			if resetMigration {
+				if _, ok := rt.(runtime.Cleaner); !ok {
+					return errs.Errorf("--reset-migration is not supported by this runtime")
+				}
+			}
+			changed, err := runtime.UseDatabase(st, rt, args[0])
+			if err != nil {
+				return err
+			}
+			if len(changed) == 0 {
+				fmt.Println("No satellite service uses a database.")
+			} else {
+				fmt.Printf("Database of %s is changed to %s.\n", strings.Join(changed, ", "), args[0])
+			}
+			return nil
+		}, func(pwd string, rt runtime.Runtime) error {
+			// the marker is removed only if the new configuration is written
+			if resetMigration {
+				return resetMigrationMarker(pwd, rt)
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().BoolVar(&resetMigration, "reset-migration", false, "remove the migration marker of satellite-api, to run the migrations at the next start")
+	return cmd
+}
+
+func init() {
+	RootCmd.AddCommand(dbCmd())
+}
+
+// resetMigrationMarker removes the containers of satellite-api (the marker is stored in the container), and the
+// marker on the host, if the directory of the marker is persisted.
+func resetMigrationMarker(pwd string, rt runtime.Runtime) error {
+	cleaner, ok := rt.(runtime.Cleaner)
+	if !ok {
+		return errs.Errorf("--reset-migration is not supported by this runtime")
+	}
+	var containers, paths []string
+	for _, s := range rt.GetServices() {
+		if s.ID().Name != "satellite-api" {
+			continue
+		}
+		if state := cleaner.State(s, nil); state.Container && state.Name != "" {
+			containers = append(containers, state.Name)
+		}
+		for _, v := range s.GetVolumes() {
+			if v.Target == migrationDir && v.MountType == runtime.PersistBind {
+				paths = append(paths, filepath.Join(v.Source, "migrated"))
+			}
+		}
+	}
+	if len(containers) > 0 {
+		err := runDocker(pwd, append([]string{"compose", "rm", "--stop", "--force"}, containers...))
+		if err != nil {
+			return err
+		}
+	}
+	return removePaths(context.Background(), paths)
+}
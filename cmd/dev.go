@@ -0,0 +1,232 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+	"golang.org/x/exp/slices"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+	"storj.io/storj-up/pkg/gobuild"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/watch"
+)
+
+// devTarget is a service which uses the binary built from a local source directory.
+type devTarget struct {
+	// Name is the name of the compose service, or the name of the supervised program.
+	Name      string
+	Container bool
+	// Build is the build of the binary for the platform of the service.
+	Build gobuild.Build
+	// Mount is the mounted binary of the container.
+	Mount string
+}
+
+func devCmd() *cobra.Command {
+	var debounce time.Duration
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Args:  cobra.NoArgs,
+		Short: "watch the sources of the local binaries, rebuild the changed binaries and restart the services using them",
+		Long: "Watches the Go packages of the STORJ_UP_LOCAL_BINARY_SOURCE (or STORJ_UP_LOCAL_<service>) directories and all " +
+			"their local dependencies. On change, only the affected binaries are rebuilt with go install, and only the " +
+			"services using them are restarted: the containers with the local-bin mount, or the programs supervised by " +
+			"`storj-up run` or supervisord. Build errors are printed, and the services keep running with the previous " +
+			"binary. The environment should be started first (with `storj-up start` or `storj-up run`).",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if DryRun {
+				return errs.Errorf("--dry-run is not supported by dev: it rebuilds and restarts the services, there are no changes to print")
+			}
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			rt, err := FromDir(pwd)
+			if err != nil {
+				return err
+			}
+			st, err := recipe.GetStack()
+			if err != nil {
+				return err
+			}
+			err = rt.Reload(st)
+			if err != nil {
+				return err
+			}
+			targets, err := devTargets(cmd.Context(), rt)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			w, err := watch.New(debounce)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = w.Close() }()
+			sources := make([]string, 0, len(targets))
+			for source := range targets {
+				sources = append(sources, source)
+			}
+			sort.Strings(sources)
+			for _, source := range sources {
+				if err := w.Add(ctx, source); err != nil {
+					return err
+				}
+			}
+			for _, source := range sources {
+				fmt.Printf("*** Storj-Up watching %s (%d packages) for %s ***\n", source, w.Packages()[source], targetNames(targets[source]))
+			}
+
+			return w.Run(ctx, func(changed []string) {
+				for _, source := range changed {
+					rebuild(ctx, pwd, w, source, targets[source])
+				}
+				fmt.Println("*** Storj-Up waiting for changes ***")
+			}, func(err error) {
+				fmt.Printf("*** Storj-Up watch error (changes may be missed): %v ***\n", err)
+			})
+		},
+	}
+	cmd.Flags().DurationVar(&debounce, "debounce", 500*time.Millisecond, "time to wait for more changes before rebuilding")
+	return cmd
+}
+
+func init() {
+	RootCmd.AddCommand(devCmd())
+}
+
+// devTargets returns with the services with local binaries, by the absolute path of the source directory. Containers
+// are included only if the local go/bin directory is mounted.
+func devTargets(ctx context.Context, rt runtime.Runtime) (map[string][]devTarget, error) {
+	cleaner, ok := rt.(runtime.Cleaner)
+	if !ok {
+		return nil, errs.Errorf("the runtime doesn't support the dev mode")
+	}
+	targets := map[string][]devTarget{}
+	var platform gobuild.Platform
+	for _, service := range rt.GetServices() {
+		if _, found := localBinarySource(service); !found {
+			continue
+		}
+		state := cleaner.State(service, nil)
+		target := devTarget{Name: state.Name, Container: state.Container}
+		buildPlatform := gobuild.Host()
+		if state.Container {
+			mount, found := localBinaryMount(service)
+			if !found {
+				fmt.Printf("*** Storj-Up ignoring %s: the local binaries are not mounted (use `storj-up local-bin %s`) ***\n", state.Name, service.ID().Name)
+				continue
+			}
+			if platform == (gobuild.Platform{}) {
+				platform = containerPlatform(ctx)
+			}
+			target.Mount = mount
+			buildPlatform = platform
+		}
+		build, _, err := localBinary(service, buildPlatform)
+		if err != nil {
+			return nil, err
+		}
+		target.Build = build
+		targets[build.Source] = append(targets[build.Source], target)
+	}
+	if len(targets) == 0 {
+		return nil, errs.Errorf("no service uses a local binary: set STORJ_UP_LOCAL_BINARY_SOURCE with `storj-up env setenv` " +
+			"(and mount the binaries of containers with `storj-up local-bin`)")
+	}
+	return targets, nil
+}
+
+// rebuild installs the binaries of a source directory (one for each platform), and restarts the services using them.
+// Errors are printed only.
+func rebuild(ctx context.Context, pwd string, w *watch.Watcher, source string, targets []devTarget) {
+	if len(targets) == 0 {
+		return
+	}
+	var builds []gobuild.Build
+	mounts := map[gobuild.Build][]string{}
+	for _, target := range targets {
+		if !slices.Contains(builds, target.Build) {
+			builds = append(builds, target.Build)
+		}
+		if target.Mount != "" {
+			mounts[target.Build] = append(mounts[target.Build], target.Mount)
+		}
+	}
+	results, _ := installBinaries(ctx, pwd, builds, mounts, defaultBuildParallelism)
+	failed := map[gobuild.Build]bool{}
+	for _, result := range results {
+		if result.Err != nil {
+			failed[result.Build] = true
+		}
+	}
+	// imports may be changed
+	if err := w.Add(ctx, source); err != nil {
+		fmt.Printf("*** Storj-Up couldn't refresh the dependencies of %s: %v ***\n", source, err)
+	}
+
+	var containers, processes, skipped []string
+	for _, target := range targets {
+		switch {
+		case failed[target.Build]:
+			skipped = append(skipped, target.Name)
+		case target.Container:
+			containers = append(containers, target.Name)
+		default:
+			processes = append(processes, target.Name)
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("*** Storj-Up build of %s failed, %s not restarted ***\n", source, strings.Join(skipped, ", "))
+	}
+	if len(containers) > 0 {
+		fmt.Printf("*** Storj-Up restarting %s ***\n", strings.Join(containers, ", "))
+		if err := restartContainers(ctx, pwd, containers); err != nil {
+			fmt.Printf("*** Storj-Up restart failed: %v ***\n", err)
+		}
+	}
+	if len(processes) > 0 {
+		fmt.Printf("*** Storj-Up restarting %s ***\n", strings.Join(processes, ", "))
+		RestartProcesses(ctx, pwd, processes)
+	}
+}
+
+// restartContainers restarts the containers of compose services with the Docker Engine API, or with
+// `docker compose restart`.
+func restartContainers(ctx context.Context, pwd string, services []string) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		return runCommand(exec.CommandContext(ctx, "docker", append([]string{"compose", "restart"}, services...)...), pwd)
+	}
+	name, err := docker.ProjectName(pwd, common.ComposeFileName)
+	if err != nil {
+		return err
+	}
+	return client.Restart(ctx, name, services, 10)
+}
+
+func targetNames(targets []devTarget) string {
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	return strings.Join(names, ", ")
+}
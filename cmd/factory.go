@@ -12,6 +12,7 @@ import (
 	"github.com/zeebo/errs/v2"
 
 	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/hybrid"
 	"storj.io/storj-up/pkg/runtime/runtime"
 	"storj.io/storj-up/pkg/runtime/standalone"
 )
@@ -19,28 +20,62 @@ import (
 // FromDir creates the right runtime based on available file names in the directory.
 func FromDir(dir string) (runtime.Runtime, error) {
 	_, err := os.Stat(filepath.Join(dir, "docker-compose.yaml"))
-	if err == nil {
+	hasCompose := err == nil
+
+	if hasCompose && isStandalone(dir) {
+		paths, err := standalonePaths(dir, "", "", false)
+		if err != nil {
+			return nil, err
+		}
+		return hybrid.NewHybrid(paths)
+	}
+
+	if hasCompose {
 		return compose.NewCompose(dir)
 	}
 
-	_, err = os.Stat(filepath.Join(dir, "supervisord.conf"))
-	if err == nil {
-		storjProjectDir := os.Getenv("STORJ_PROJECT_DIR")
-		if storjProjectDir == "" {
-			return nil, errs.Errorf("Please set \"STORJ_PROJECT_DIR\" environment variable with the location of your checked out storj/storj project. (Required to use web resources")
+	if isStandalone(dir) {
+		paths, err := standalonePaths(dir, "", "", false)
+		if err != nil {
+			return nil, err
 		}
-		gatewayProjectDir := os.Getenv("GATEWAY_PROJECT_DIR")
-		if gatewayProjectDir == "" {
-			fmt.Println("WARNING: \"GATEWAY_PROJECT_DIR\" environment variable not set! Please set or add -g flag with the location of your checked out storj/gateway-mt project to use web resources.")
-			gatewayProjectDir = "/tmp"
-		}
-		return standalone.NewStandalone(standalone.Paths{
-			ScriptDir:  dir,
-			StorjDir:   storjProjectDir,
-			GatewayDir: gatewayProjectDir,
-			CleanDir:   false,
-		})
+		return standalone.NewStandalone(paths)
 	}
 
 	return nil, errors.New("directory doesn't contain supported deployment descriptor")
 }
+
+// standalonePaths resolves the directories of the checked out projects. Flags (if not empty) override the environment variables.
+func standalonePaths(dir string, storjDir string, gatewayDir string, clean bool) (standalone.Paths, error) {
+	storjProjectDir := os.Getenv("STORJ_PROJECT_DIR")
+	if storjDir != "" {
+		storjProjectDir = storjDir
+	}
+	if storjProjectDir == "" {
+		return standalone.Paths{}, errs.Errorf("Please set \"STORJ_PROJECT_DIR\" environment variable or add -s flag with the location of your checked out storj/storj project. (Required to use web resources")
+	}
+	gatewayProjectDir := os.Getenv("GATEWAY_PROJECT_DIR")
+	if gatewayDir != "" {
+		gatewayProjectDir = gatewayDir
+	}
+	if gatewayProjectDir == "" {
+		fmt.Println("WARNING: \"GATEWAY_PROJECT_DIR\" environment variable not set! Please set or add -g flag with the location of your checked out storj/gateway-mt project to use web resources.")
+		gatewayProjectDir = "/tmp"
+	}
+	return standalone.Paths{
+		ScriptDir:  dir,
+		StorjDir:   storjProjectDir,
+		GatewayDir: gatewayProjectDir,
+		CleanDir:   clean,
+	}, nil
+}
+
+// isStandalone checks if the directory contains a standalone environment (state file, or supervisord.conf of older versions).
+func isStandalone(dir string) bool {
+	for _, name := range []string{standalone.StateFileName, "supervisord.conf"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
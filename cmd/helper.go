@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/docker"
+)
+
+// HelperImage is the image of the one-off containers which access the named volumes, and the files written by the
+// containers to the bind mounts (which can be owned by root).
+const HelperImage = "busybox"
+
+// RunHelper runs a command in a one-off HelperImage container with the mounts, with the Docker Engine API. `docker run
+// --rm` is used, if the API is not available.
+func RunHelper(ctx context.Context, mounts []docker.Mount, command ...string) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		args := []string{"run", "--rm"}
+		for _, m := range mounts {
+			mount := "type=" + m.Type + ",source=" + m.Source + ",target=" + m.Target
+			if m.ReadOnly {
+				mount += ",readonly"
+			}
+			args = append(args, "--mount", mount)
+		}
+		args = append(append(args, HelperImage), command...)
+		c := exec.CommandContext(ctx, "docker", args...)
+		c.Stdout = os.Stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return errs.Errorf("couldn't execute docker %s: %v", strings.Join(args, " "), err)
+		}
+		return nil
+	}
+	return client.RunContainer(ctx, docker.ContainerConfig{
+		Image:      HelperImage,
+		Cmd:        command,
+		HostConfig: docker.HostConfig{Mounts: mounts},
+	})
+}
+
+// removeAll removes a path like os.RemoveAll. Files which can't be removed on the host (like the files of the
+// containers owned by root) are removed with a helper container.
+func removeAll(ctx context.Context, path string) error {
+	err := os.RemoveAll(path)
+	if !errors.Is(err, fs.ErrPermission) {
+		return errs.Wrap(err)
+	}
+	parent, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return RunHelper(ctx, []docker.Mount{{Type: "bind", Source: parent, Target: "/parent"}},
+		"rm", "-rf", "/parent/"+filepath.Base(path))
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package history
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/common/composedb"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+func diffCmd() *cobra.Command {
+	var recipes string
+	diffCmd := &cobra.Command{
+		Use:   "diff [<version>]",
+		Short: "compare the docker compose file with a previous version (the latest one by default) or with a fresh rendering of recipes",
+		Long: "Compare the docker compose file with a version from the history (the latest one by default), or with the services of the " +
+			"recipes (--recipes), rendered from scratch. Available versions are listed if the version is not found.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			current, err := common.LoadComposeFromFile(pwd, common.ComposeFileName)
+			if err != nil {
+				return err
+			}
+
+			var fromName string
+			var from []runtime.ServiceSpec
+			switch {
+			case recipes != "":
+				if len(args) > 0 {
+					return errs.Errorf("version and --recipes can't be used together")
+				}
+				fromName = "recipes " + recipes
+				from, err = renderRecipes(strings.Split(recipes, ","))
+				if err != nil {
+					return err
+				}
+			default:
+				versions, err := common.Store.ListVersions()
+				if err != nil {
+					return err
+				}
+				fromName = versions[0].ID
+				if len(args) > 0 {
+					fromName = args[0]
+				}
+				previous, err := loadVersion(fromName)
+				if err != nil {
+					return errs.Errorf("%v (available versions: %s)", err, versionList(versions))
+				}
+				from = compose.ProjectSpecs(previous)
+			}
+			return printDiff(os.Stdout, fromName, from, compose.ProjectSpecs(current))
+		},
+	}
+	diffCmd.Flags().StringVarP(&recipes, "recipes", "r", "", "compare with the services of these recipes (or services), eg. minimal,db")
+	return diffCmd
+}
+
+func printDiff(w io.Writer, fromName string, from []runtime.ServiceSpec, to []runtime.ServiceSpec) error {
+	changed, err := runtime.WriteDiff(w, fromName, from, common.ComposeFileName, to)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if !changed {
+		_, err = fmt.Fprintln(w, "No differences.")
+	}
+	return errs.Wrap(err)
+}
+
+// renderRecipes renders the selected recipes to a temporary compose runtime.
+func renderRecipes(selector []string) ([]runtime.ServiceSpec, error) {
+	st, err := recipe.GetStack()
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "storj-up-diff")
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	rt, err := compose.NewCompose(tmp)
+	if err != nil {
+		return nil, err
+	}
+	err = runtime.ApplyRecipes(st, rt, selector, 0)
+	if err != nil {
+		return nil, err
+	}
+	return rt.Specs(), nil
+}
+
+// loadVersion loads one version of the compose file from the history.
+func loadVersion(version string) (*types.Project, error) {
+	raw, err := common.Store.RestoreVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	return common.LoadComposeFromBytes(raw)
+}
+
+func versionList(versions []composedb.Version) string {
+	var ids []string
+	for _, v := range versions {
+		ids = append(ids, v.ID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(diffCmd())
+}
@@ -6,11 +6,15 @@ package history
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
 
 	"storj.io/storj-up/cmd"
 	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/common/atomicfile"
+	"storj.io/storj-up/pkg/runtime/compose"
 )
 
 func undoCmd() *cobra.Command {
@@ -18,28 +22,51 @@ func undoCmd() *cobra.Command {
 		Use:   "undo",
 		Short: "revert to a previous version of the generated docker compose file",
 		Args:  cobra.NoArgs,
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			newTemplateBytes, err := common.Store.RestoreLatestVersion()
+		RunE: func(_ *cobra.Command, _ []string) (err error) {
+			pwd, _ := os.Getwd()
+			unlock, err := common.LockProject(pwd)
 			if err != nil {
 				return err
 			}
-			if newTemplateBytes == nil {
-				return fmt.Errorf("no previous version of the compose file found")
+			defer func() { err = errs.Combine(err, unlock()) }()
+
+			if cmd.DryRun {
+				return undoDryRun(pwd)
 			}
-			newTemplate, err := common.LoadComposeFromBytes(newTemplateBytes)
+			newTemplateBytes, err := common.Store.RestoreLatestVersion()
 			if err != nil {
 				return err
 			}
-			pwd, _ := os.Getwd()
-			err = common.WriteComposeFileNoHistory(pwd, newTemplate)
+			if newTemplateBytes == nil {
+				return fmt.Errorf("no previous version of the compose file found")
+			}
+			_, err = common.LoadComposeFromBytes(newTemplateBytes)
 			if err != nil {
 				return err
 			}
-			return nil
+			// the saved version is restored as is, including the content which is not managed by storj-up
+			return atomicfile.WriteFile(filepath.Join(pwd, common.ComposeFileName), newTemplateBytes, 0o644)
 		},
 	}
 }
 
+// undoDryRun prints the changes of the undo, without removing the version from the history.
+func undoDryRun(pwd string) error {
+	versions, err := common.Store.ListVersions()
+	if err != nil {
+		return err
+	}
+	previous, err := loadVersion(versions[0].ID)
+	if err != nil {
+		return err
+	}
+	current, err := common.LoadComposeFromFile(pwd, common.ComposeFileName)
+	if err != nil {
+		return err
+	}
+	return cmd.PrintDiff(compose.ProjectSpecs(current), compose.ProjectSpecs(previous))
+}
+
 func init() {
 	cmd.RootCmd.AddCommand(undoCmd())
 }
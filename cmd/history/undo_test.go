@@ -20,6 +20,8 @@ import (
 func Test_Undo(t *testing.T) {
 
 	dir := t.TempDir()
+	// the history is saved to the working directory
+	t.Chdir(dir)
 
 	rt, err := compose.NewCompose(dir)
 	require.NoError(t, err)
@@ -0,0 +1,57 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"storj.io/storj-up/pkg/ide"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// localSourceDirs are the environment variables with the location of the local checkouts.
+var localSourceDirs = map[string]string{
+	"storj":      "STORJ_PROJECT_DIR",
+	"gateway-mt": "GATEWAY_PROJECT_DIR",
+}
+
+// writeDebugConfigs generates VS Code and GoLand configurations to attach to the debugged services. Configurations are
+// named after the compose services or the scripts, like the other commands.
+func writeDebugConfigs(dir string, rt runtime.Runtime) error {
+	cleaner, hasState := rt.(runtime.Cleaner)
+	var targets []ide.Target
+	for _, s := range rt.GetServices() {
+		d, ok := s.(runtime.Debuggable)
+		if !ok || d.DebugPort() == 0 {
+			continue
+		}
+		id := s.ID()
+		name := fmt.Sprintf("%s%d", id.Name, id.Instance+1)
+		if hasState {
+			if state := cleaner.State(s, nil); state.Name != "" {
+				name = state.Name
+			}
+		}
+		target := ide.Target{
+			Name: name,
+			Host: rt.GetHost(id, "external"),
+			Port: d.DebugPort(),
+		}
+		// binaries of the containers are built from the checkout inside the image
+		if _, container := s.(*compose.Service); container {
+			project := ide.Project(id.Name)
+			local := os.Getenv(localSourceDirs[project])
+			if project != "" && local != "" {
+				target.PathMappings = append(target.PathMappings, ide.PathMapping{
+					Local:  local,
+					Remote: ide.ContainerSourceDirs[project],
+				})
+			}
+		}
+		targets = append(targets, target)
+	}
+	return ide.Write(dir, targets)
+}
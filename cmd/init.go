@@ -4,37 +4,48 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs/v2"
 
+	"storj.io/storj-up/pkg/common"
 	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/release"
 	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/hybrid"
 	"storj.io/storj-up/pkg/runtime/runtime"
 	"storj.io/storj-up/pkg/runtime/standalone"
 )
 
 func initCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:  "init [<selector>...] OR init <compose|shell> [<selector>...]",
+		Use:  "init [<selector>...] OR init <compose|shell|hybrid> [<selector>...]",
 		Args: cobra.MinimumNArgs(1),
 		Short: "Initialize new storj-up stack with the chosen container orchestrator. " + SelectorHelp + ". Without argument it generates " +
 			"full Storj cluster with databases (db,minimal,edge)",
 	}
+	releaseName := cmd.PersistentFlags().String("release", "", "pin the images to the known-compatible image set of a release (like v1.125, or a release of --manifest)")
+	manifest := cmd.PersistentFlags().String("manifest", "", "release manifest file, which extends the embedded one")
 
 	{
 		composeCmd := &cobra.Command{
 			Use:  "compose [<selector>...]",
 			Args: cobra.MinimumNArgs(0),
 		}
-		composeCmd.RunE = func(cmd *cobra.Command, selector []string) error {
-			pwd, err := os.Getwd()
+		composeCmd.RunE = func(cmd *cobra.Command, selector []string) (err error) {
+			pwd, err := ProjectDir()
 			if err != nil {
 				return err
 			}
+			// the initialization shouldn't be mixed with the changes of other storj-up executions
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
 			n, err := compose.NewCompose(pwd)
 			if err != nil {
 				return err
@@ -47,6 +58,13 @@ func initCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			err = pinRelease(n, *releaseName, *manifest)
+			if err != nil {
+				return err
+			}
+			if DryRun {
+				return PrintDiff(currentSpecs(FromDir(pwd)), n.Specs())
+			}
 
 			return n.Write()
 		}
@@ -62,35 +80,34 @@ func initCmd() *cobra.Command {
 		}
 		storjProjDir := shellCmd.Flags().StringP("storjdir", "s", "", "Directory of the storj code.")
 		gatewayProjDir := shellCmd.Flags().StringP("gatewaydir", "g", "", "Directory of the gateway code.")
-		shellCmd.RunE = func(cmd *cobra.Command, selector []string) error {
-			pwd, err := os.Getwd()
+		processManager := shellCmd.Flags().String("process-manager", standalone.DefaultProcessManager, processManagerHelp())
+		shellCmd.RunE = func(cmd *cobra.Command, selector []string) (err error) {
+			if *releaseName != "" {
+				return errs.Errorf("--release is not supported by init shell: the binaries are not pinned")
+			}
+			pwd, err := ProjectDir()
 			if err != nil {
 				return err
 			}
-			storjProjectDir := os.Getenv("STORJ_PROJECT_DIR")
-			if *storjProjDir != "" {
-				storjProjectDir = *storjProjDir
-			}
-			if storjProjectDir == "" {
-				return errs.Errorf("Please set \"STORJ_PROJECT_DIR\" environment variable or add -s flag with the location of your checked out storj/storj project. (Required to use web resources")
+			// the initialization shouldn't be mixed with the changes of other storj-up executions
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
 			}
-			gatewayProjectDir := os.Getenv("GATEWAY_PROJECT_DIR")
-			if *gatewayProjDir != "" {
-				gatewayProjectDir = *gatewayProjDir
+			defer func() { err = errs.Combine(err, unlock()) }()
+			paths, err := standalonePaths(pwd, *storjProjDir, *gatewayProjDir, true)
+			if err != nil {
+				return err
 			}
-			if gatewayProjectDir == "" {
-				fmt.Println("WARNING: \"GATEWAY_PROJECT_DIR\" environment variable not set! Please set or add -g flag with the location of your checked out storj/gateway-mt project to use web resources.")
-				gatewayProjectDir = "/tmp"
+			if _, found := standalone.ProcessManagers[*processManager]; !found {
+				return errs.Errorf("unsupported process manager: %s", *processManager)
 			}
-			n, err := standalone.NewStandalone(standalone.Paths{
-				ScriptDir:  pwd,
-				StorjDir:   storjProjectDir,
-				GatewayDir: gatewayProjectDir,
-				CleanDir:   true,
-			})
+			n, err := standalone.NewStandalone(paths)
 			if err != nil {
 				return err
 			}
+			n.ProcessManager = *processManager
+			n.DryRun = DryRun
 			st, err := recipe.GetStack()
 			if err != nil {
 				return err
@@ -99,15 +116,102 @@ func initCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if DryRun {
+				return PrintDiff(currentSpecs(standalone.NewStandalone(paths)), n.Specs())
+			}
 
 			return n.Write()
 		}
 		cmd.AddCommand(shellCmd)
 	}
 
+	{
+		hybridCmd := &cobra.Command{
+			Use:   "hybrid [<selector>...]",
+			Args:  cobra.MinimumNArgs(0),
+			Short: "Generate docker-compose.yaml for the infrastructure services (databases, redis, ...) and scripts for the natively executed Storj services",
+		}
+		storjProjDir := hybridCmd.Flags().StringP("storjdir", "s", "", "Directory of the storj code.")
+		gatewayProjDir := hybridCmd.Flags().StringP("gatewaydir", "g", "", "Directory of the gateway code.")
+		processManager := hybridCmd.Flags().String("process-manager", standalone.DefaultProcessManager, processManagerHelp())
+		hybridCmd.RunE = func(cmd *cobra.Command, selector []string) (err error) {
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			// the initialization shouldn't be mixed with the changes of other storj-up executions
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+			paths, err := standalonePaths(pwd, *storjProjDir, *gatewayProjDir, true)
+			if err != nil {
+				return err
+			}
+			if _, found := standalone.ProcessManagers[*processManager]; !found {
+				return errs.Errorf("unsupported process manager: %s", *processManager)
+			}
+			n, err := hybrid.NewHybrid(paths)
+			if err != nil {
+				return err
+			}
+			n.Native().ProcessManager = *processManager
+			n.Native().DryRun = DryRun
+			st, err := recipe.GetStack()
+			if err != nil {
+				return err
+			}
+			err = runtime.ApplyRecipes(st, n, normalizedArgs(selector), 0)
+			if err != nil {
+				return err
+			}
+			err = pinRelease(n, *releaseName, *manifest)
+			if err != nil {
+				return err
+			}
+			if DryRun {
+				return PrintDiff(currentSpecs(hybrid.NewHybrid(paths)), n.Specs())
+			}
+
+			return n.Write()
+		}
+		cmd.AddCommand(hybridCmd)
+	}
+
 	return cmd
 }
 
+// pinRelease sets the images of the services to the image set of the release. Nothing is changed, if name is empty.
+func pinRelease(rt runtime.Runtime, name string, manifest string) error {
+	if name == "" {
+		return nil
+	}
+	m, err := release.Load(manifest)
+	if err != nil {
+		return err
+	}
+	r, err := m.Get(name)
+	if err != nil {
+		return err
+	}
+	changes, err := r.Apply(rt)
+	if err != nil {
+		return err
+	}
+	printReleaseChanges(os.Stdout, r, changes)
+	return nil
+}
+
+func processManagerHelp() string {
+	var names []string
+	for name := range standalone.ProcessManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "Generated process manager configuration (" + strings.Join(names, ", ") + ")."
+}
+
 func normalizedArgs(args []string) []string {
 	var res []string
 	for _, a := range args {
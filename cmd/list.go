@@ -4,26 +4,35 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs/v2"
+	"gopkg.in/yaml.v3"
 
 	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
 )
 
 func listCmd() *cobra.Command {
-	return &cobra.Command{
+	var output string
+	cmd := &cobra.Command{
 		Use:   "list",
 		Args:  cobra.NoArgs,
-		Short: "print all the configured services with user versions",
+		Short: "print all the configured services with images (or build sources), ports, persisted directories, debug state and labels",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			pwd, err := os.Getwd()
+			pwd, err := ProjectDir()
 			if err != nil {
 				return errs.Wrap(err)
 			}
-			runtime, err := FromDir(pwd)
+			rt, err := FromDir(pwd)
 			if err != nil {
 				return errs.Wrap(err)
 			}
@@ -32,18 +41,83 @@ func listCmd() *cobra.Command {
 				return errs.Wrap(err)
 			}
 
-			err = runtime.Reload(st)
+			err = rt.Reload(st)
 			if err != nil {
 				return errs.Wrap(err)
 			}
-			services := runtime.GetServices()
-			for _, s := range services {
-				fmt.Printf("%s\n", s.ID())
+			return printServices(os.Stdout, describeServices(rt.GetServices()), output)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format (table, json or yaml)")
+	return cmd
+}
+
+// describeServices returns with the summary of the services, ordered by name and instance.
+func describeServices(services []runtime.Service) []runtime.ServiceInfo {
+	sort.Slice(services, func(i, j int) bool {
+		a, b := services[i].ID(), services[j].ID()
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Instance < b.Instance
+	})
+	infos := make([]runtime.ServiceInfo, 0, len(services))
+	for _, s := range services {
+		if d, ok := s.(runtime.Describer); ok {
+			infos = append(infos, d.Describe())
+			continue
+		}
+		infos = append(infos, runtime.ServiceInfo{
+			Instance: s.ID().String(),
+			Labels:   s.Labels(),
+		})
+	}
+	return infos
+}
+
+func printServices(w io.Writer, infos []runtime.ServiceInfo, output string) error {
+	switch output {
+	case "json":
+		raw, err := json.MarshalIndent(infos, "", "  ")
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		_, err = fmt.Fprintln(w, string(raw))
+		return errs.Wrap(err)
+	case "yaml":
+		raw, err := yaml.Marshal(infos)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		_, err = w.Write(raw)
+		return errs.Wrap(err)
+	case "table", "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "INSTANCE\tSOURCE\tPORTS\tPERSISTED\tDEBUG\tLABELS")
+		for _, info := range infos {
+			debug := "-"
+			if info.Debug > 0 {
+				debug = strconv.Itoa(info.Debug)
 			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				info.Instance,
+				orDash(info.Source),
+				orDash(strings.Join(info.Ports, ",")),
+				orDash(strings.Join(info.Persisted, ",")),
+				debug,
+				orDash(strings.Join(info.Labels, ",")))
+		}
+		return errs.Wrap(tw.Flush())
+	default:
+		return errs.Errorf("unsupported output format %q (table, json or yaml)", output)
+	}
+}
 
-			return nil
-		},
+func orDash(value string) string {
+	if value == "" {
+		return "-"
 	}
+	return value
 }
 
 func init() {
@@ -0,0 +1,94 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/matrix"
+)
+
+func matrixCmd() *cobra.Command {
+	var satellites, storagenodes, uplinks []string
+	var options matrix.Options
+	var junit string
+	cmd := &cobra.Command{
+		Use:   "matrix --satellite <version>,... --storagenode <version>,... --uplink <version>,...",
+		Args:  cobra.NoArgs,
+		Short: "run a scenario with each combination of satellite, storagenode and uplink versions",
+		Long: "Generates and starts a cluster for each combination of the versions, runs the scenario, destroys the " +
+			"cluster, and prints a pass/fail table. The combinations are executed one after the other: the clusters use " +
+			"the fixed host ports of the recipes (port offsets are not supported), and the compose project name of " +
+			"storj-up, therefore a running storj-up environment should be stopped first. A version is an image tag (like " +
+			"1.131.1), or a release of the release manifest (like v1.125). Services without versions use the versions of " +
+			"the recipes. The results are also written as a JUnit XML report.\n\nScenarios: " + strings.Join(matrix.ScenarioNames(), ", "),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			combinations := matrix.Combinations(satellites, storagenodes, uplinks)
+			if DryRun {
+				for _, c := range combinations {
+					fmt.Println(c)
+				}
+				return nil
+			}
+			results, err := matrix.Run(cmd.Context(), options, combinations, func(c matrix.Combination, r *matrix.Result) {
+				switch {
+				case r == nil:
+					fmt.Printf("*** Storj-Up running %s with %s ***\n", options.Scenario, c)
+				case r.Passed():
+					fmt.Printf("*** Storj-Up %s passed ***\n", c)
+				default:
+					fmt.Printf("*** Storj-Up %s failed: %v ***\n", c, r.Err)
+				}
+			})
+			if len(results) > 0 {
+				fmt.Println()
+				err = errs.Combine(err, matrix.WriteTable(os.Stdout, results))
+				if junit != "" {
+					err = errs.Combine(err, writeJUnit(junit, results))
+				}
+			}
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, r := range results {
+				if !r.Passed() {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return errs.Errorf("%d of %d combination(s) failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringSliceVar(&satellites, "satellite", nil, "versions of the satellite services")
+	cmd.Flags().StringSliceVar(&storagenodes, "storagenode", nil, "versions of the storagenodes")
+	cmd.Flags().StringSliceVar(&uplinks, "uplink", nil, "versions of the uplink")
+	cmd.Flags().StringVar(&options.Scenario, "scenario", "upload-download", "the scenario to run")
+	cmd.Flags().StringVar(&options.Manifest, "manifest", "", "release manifest file, which extends the embedded one")
+	cmd.Flags().StringVar(&options.Dir, "dir", "", "directory of the generated clusters, which are kept for debugging (temporary directories are used by default)")
+	cmd.Flags().DurationVar(&options.Timeout, "timeout", 15*time.Minute, "time limit of a combination, including the start of the cluster")
+	cmd.Flags().StringVar(&junit, "junit", "storj-up-matrix.xml", "file of the JUnit XML report (empty to skip)")
+	return cmd
+}
+
+func init() {
+	RootCmd.AddCommand(matrixCmd())
+}
+
+func writeJUnit(file string, results []matrix.Result) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	err = matrix.WriteJUnit(f, results)
+	return errs.Combine(err, errs.Wrap(f.Close()))
+}
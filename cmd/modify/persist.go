@@ -4,6 +4,8 @@
 package modify
 
 import (
+	"strings"
+
 	"github.com/spf13/cobra"
 
 	"storj.io/storj-up/cmd"
@@ -11,14 +13,19 @@ import (
 	"storj.io/storj-up/pkg/runtime/runtime"
 )
 
+var persistType string
+
 func init() {
-	cmd.RootCmd.AddCommand(&cobra.Command{
+	persistCmd := &cobra.Command{
 		Use:   "persist <selector>...",
 		Short: "Make internal state (database files, storagenode files) persisted between restarts. ",
-		Long:  "This is done usually with mounting the directory to the houst. ." + cmd.SelectorHelp,
-		Args:  cobra.MinimumNArgs(1),
-		RunE:  cmd.ExecuteStorjUP(persist),
-	})
+		Long: "This is done usually with mounting the directory to the host (bind), or with named volumes (volume). " +
+			"tmpfs keeps the files only in memory, which is fast but the files are lost at restart. " + cmd.SelectorHelp,
+		Args: cobra.MinimumNArgs(1),
+		RunE: cmd.ExecuteStorjUP(persist),
+	}
+	persistCmd.Flags().StringVarP(&persistType, "type", "t", "", "type of the persistence ("+strings.Join(runtime.PersistTypes, ", ")+"). Default is defined by the recipe, or bind.")
+	cmd.RootCmd.AddCommand(persistCmd)
 }
 
 func persist(st recipe.Stack, rt runtime.Runtime, selectors []string) error {
@@ -28,9 +35,13 @@ func persist(st recipe.Stack, rt runtime.Runtime, selectors []string) error {
 			return err
 		}
 
+		t := persistType
+		if t == "" {
+			t = rService.PersistenceType
+		}
 		if rService.Persistence != nil {
 			for _, p := range rService.Persistence {
-				err := s.Persist(p)
+				err := s.Persist(p, t)
 				if err != nil {
 					return err
 				}
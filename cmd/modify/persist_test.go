@@ -15,6 +15,8 @@ import (
 )
 
 func TestPersistCockroach(t *testing.T) {
+	// testdata.InitCompose writes the compose file first, the rewrite shouldn't be saved to the history
+	t.Setenv("STORJUP_NO_HISTORY", "true")
 	dir := t.TempDir()
 
 	st, rt, err := testdata.InitCompose(dir)
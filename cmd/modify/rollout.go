@@ -0,0 +1,179 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package modify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+func rolloutCmd() *cobra.Command {
+	var version string
+	var batch int
+	var waitHealthy bool
+	var timeout time.Duration
+	c := &cobra.Command{
+		Use:   "rollout <selector>... --version <version>",
+		Short: "upgrade services instance by instance, to simulate a rolling upgrade with mixed-version fleets",
+		Long: "Sets the version (docker image tag) of the selected instances in batches. The containers of each batch are " +
+			"recreated, and with --wait-healthy the next batch is started only when they are running and healthy. The " +
+			"rollout is aborted at the first failure, and the remaining instances keep their versions.\n\n" +
+			"Selectors can select instances, like storagenode[1-5], storagenode[1,3,7-9] or storagenode[50%].",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if batch < 1 {
+				return errs.Errorf("--batch should be at least 1")
+			}
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+
+			rt, err := cmd.FromDir(pwd)
+			if err != nil {
+				return err
+			}
+			st, err := recipe.GetStack()
+			if err != nil {
+				return err
+			}
+			if err := rt.Reload(st); err != nil {
+				return err
+			}
+			services, err := rolloutServices(st, rt, args, version)
+			if err != nil {
+				return err
+			}
+			if len(services) == 0 {
+				fmt.Printf("all the selected services already use version %s\n", version)
+				return nil
+			}
+			batches := rolloutBatches(services, batch)
+			for i, b := range batches {
+				fmt.Printf("batch %d: %s\n", i+1, strings.Join(rolloutNames(rt, b), ", "))
+			}
+			if cmd.DryRun {
+				return nil
+			}
+
+			for i, b := range batches {
+				for _, s := range b {
+					if err := s.TransformRaw(func(composeService *types.ServiceConfig) error {
+						return updateVersion(composeService, version)
+					}); err != nil {
+						return err
+					}
+				}
+				if err := rt.Write(); err != nil {
+					return err
+				}
+				names := rolloutNames(rt, b)
+				fmt.Printf("*** Storj-Up rolling out %s to %s (batch %d/%d) ***\n", version, strings.Join(names, ", "), i+1, len(batches))
+				err := cmd.RecreateServices(c.Context(), pwd, names)
+				if err == nil && waitHealthy {
+					err = cmd.WaitHealthy(c.Context(), pwd, names, timeout)
+				}
+				if err != nil {
+					var remaining []string
+					for _, rest := range batches[i+1:] {
+						remaining = append(remaining, rolloutNames(rt, rest)...)
+					}
+					if len(remaining) == 0 {
+						return errs.Errorf("rollout is failed at batch %d: %v", i+1, err)
+					}
+					return errs.Errorf("rollout is aborted at batch %d: %v (not updated: %s)", i+1, err, strings.Join(remaining, ", "))
+				}
+			}
+			fmt.Printf("%d instance(s) are rolled out to %s\n", len(services), version)
+			return nil
+		},
+	}
+	c.Flags().StringVar(&version, "version", "", "the version (docker image tag) to roll out")
+	c.Flags().IntVar(&batch, "batch", 1, "number of instances upgraded at the same time")
+	c.Flags().BoolVar(&waitHealthy, "wait-healthy", false, "wait until the upgraded containers are running and healthy before the next batch")
+	c.Flags().DurationVar(&timeout, "timeout", 5*time.Minute, "maximum time to wait for the containers of a batch to be healthy")
+	_ = c.MarkFlagRequired("version")
+	return c
+}
+
+func init() {
+	cmd.RootCmd.AddCommand(rolloutCmd())
+}
+
+// rolloutServices returns with the selected compose services which don't use the version yet, ordered by name and
+// instance.
+func rolloutServices(st recipe.Stack, rt runtime.Runtime, selectors []string, version string) ([]*compose.Service, error) {
+	var res []*compose.Service
+	selected := map[runtime.ServiceInstance]bool{}
+	err := runtime.ModifyService(st, rt, selectors, func(s runtime.Service) error {
+		composeService, ok := s.(*compose.Service)
+		if !ok {
+			return errs.Errorf("this subcommand is supported only for compose based environments")
+		}
+		if selected[s.ID()] {
+			return nil
+		}
+		selected[s.ID()] = true
+		current := ""
+		_ = s.ChangeImage(func(image string) string {
+			current = image
+			return image
+		})
+		updated := types.ServiceConfig{Image: current}
+		_ = updateVersion(&updated, version)
+		if updated.Image != current {
+			res = append(res, composeService)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i].ID(), res[j].ID()
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Instance < b.Instance
+	})
+	return res, nil
+}
+
+func rolloutBatches(services []*compose.Service, size int) (batches [][]*compose.Service) {
+	for len(services) > size {
+		batches = append(batches, services[:size])
+		services = services[size:]
+	}
+	return append(batches, services)
+}
+
+// rolloutNames returns with the names of the compose services.
+func rolloutNames(rt runtime.Runtime, services []*compose.Service) []string {
+	var names []string
+	for _, s := range services {
+		name := fmt.Sprintf("%s%d", s.ID().Name, s.ID().Instance+1)
+		if cleaner, ok := rt.(runtime.Cleaner); ok {
+			name = cleaner.State(s, nil).Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -19,7 +19,9 @@ func versionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version <selector>... <version>",
 		Short: "set version (docker image tag) for specified services",
-		Args:  cobra.MinimumNArgs(2),
+		Long: "Sets the version (docker image tag) of the selected services. Selectors can select instances, like " +
+			"storagenode[1-5], storagenode[1,3,7-9] or storagenode[50%], to create mixed-version fleets.",
+		Args: cobra.MinimumNArgs(2),
 		RunE: cmd.ExecuteStorjUP(func(st recipe.Stack, rt runtime.Runtime, args []string) error {
 			selector, version := common.SplitArgsSelector1(args)
 			return cmd.ChangeCompose(st, rt, selector, func(composeService *types.ServiceConfig) error {
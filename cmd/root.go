@@ -13,14 +13,20 @@ import (
 	"github.com/spf13/viper"
 	"github.com/zeebo/errs/v2"
 
+	"storj.io/storj-up/pkg/common"
 	"storj.io/storj-up/pkg/recipe"
 	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/hybrid"
 	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/runtime/standalone"
 )
 
 var cfgFile string
 var rootDir string
 
+// DryRun is set by --dry-run: the changes are printed as a diff instead of being written.
+var DryRun bool
+
 // RootCmd represents the base command when called without any subcommands.
 var RootCmd = &cobra.Command{
 	Use:   "storj-up",
@@ -47,6 +53,7 @@ func init() {
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.reminderctl.yaml)")
 	RootCmd.PersistentFlags().StringVar(&rootDir, "root", "", "The directory of the project. If not set, the current directory is used.")
+	RootCmd.PersistentFlags().BoolVar(&DryRun, "dry-run", false, "Print the changes as a diff instead of writing them.")
 }
 
 func initConfig() {
@@ -72,16 +79,34 @@ func initConfig() {
 	}
 }
 
+// ProjectDir returns with the directory of the project (--root or the current directory).
+func ProjectDir() (string, error) {
+	if rootDir != "" {
+		return rootDir, nil
+	}
+	return os.Getwd()
+}
+
 // ExecuteStorjUP can execute any operation with loaded stack/runtime and write back the results.
 func ExecuteStorjUP(exec func(stack recipe.Stack, rt runtime.Runtime, args []string) error) func(cmd *cobra.Command, args []string) error {
-	return func(cmd *cobra.Command, args []string) error {
-		pwd, err := os.Getwd()
+	return executeStorjUP(exec, nil)
+}
+
+// executeStorjUP is ExecuteStorjUP with an optional operation, which is executed after the results are written
+// successfully (not in dry-run mode).
+func executeStorjUP(exec func(stack recipe.Stack, rt runtime.Runtime, args []string) error, written func(pwd string, rt runtime.Runtime) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) (err error) {
+		pwd, err := ProjectDir()
 		if err != nil {
 			return err
 		}
-		if rootDir != "" {
-			pwd = rootDir
+		// concurrent storj-up executions shouldn't overwrite each other's changes
+		unlock, err := common.LockProject(pwd)
+		if err != nil {
+			return err
 		}
+		defer func() { err = errs.Combine(err, unlock()) }()
+
 		rt, err := FromDir(pwd)
 		if err != nil {
 			return err
@@ -95,12 +120,88 @@ func ExecuteStorjUP(exec func(stack recipe.Stack, rt runtime.Runtime, args []str
 			return err
 		}
 
+		var before []runtime.ServiceSpec
+		if DryRun {
+			setDryRun(rt)
+			before, err = specs(rt)
+			if err != nil {
+				return err
+			}
+		}
+
 		err = exec(st, rt, args)
 		if err != nil {
 			return err
 		}
-		return rt.Write()
+		if DryRun {
+			after, err := specs(rt)
+			if err != nil {
+				return err
+			}
+			return PrintDiff(before, after)
+		}
+		err = rt.Write()
+		if err != nil {
+			return err
+		}
+		err = writeDebugConfigs(pwd, rt)
+		if err != nil {
+			return err
+		}
+		if written != nil {
+			return written(pwd, rt)
+		}
+		return nil
+	}
+}
+
+// PrintDiff prints the changes of a dry-run to the standard output.
+func PrintDiff(before, after []runtime.ServiceSpec) error {
+	changed, err := runtime.WriteDiff(os.Stdout, "current", before, "dry-run", after)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if !changed {
+		fmt.Println("No changes.")
+	}
+	return nil
+}
+
+// specs returns with the services of the environment.
+func specs(rt runtime.Runtime) ([]runtime.ServiceSpec, error) {
+	s, ok := rt.(runtime.Specifier)
+	if !ok {
+		return nil, errs.Errorf("--dry-run is not supported by this runtime")
+	}
+	return s.Specs(), nil
+}
+
+// setDryRun turns off the changes of the runtime, which are executed before Write (like the identities and configs of
+// the new standalone services).
+func setDryRun(rt runtime.Runtime) {
+	switch r := rt.(type) {
+	case *standalone.Standalone:
+		r.DryRun = true
+	case *hybrid.Hybrid:
+		r.Native().DryRun = true
+	}
+}
+
+// currentSpecs returns with the services of an existing environment (nil, if the environment can't be loaded), to
+// print the changes of a dry-run.
+func currentSpecs(rt runtime.Runtime, err error) []runtime.ServiceSpec {
+	if err != nil {
+		return nil
+	}
+	st, err := recipe.GetStack()
+	if err != nil {
+		return nil
+	}
+	if err := rt.Reload(st); err != nil {
+		return nil
 	}
+	before, _ := specs(rt)
+	return before
 }
 
 // ChangeCompose applies modification to compose based runtime services. Used mainly in legacy commands.
@@ -0,0 +1,130 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/hybrid"
+	"storj.io/storj-up/pkg/runtime/standalone"
+	"storj.io/storj-up/pkg/supervisor"
+)
+
+func runCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Args:  cobra.NoArgs,
+		Short: "start and supervise the services of a standalone or hybrid environment (without supervisord)",
+		Long: "Starts the generated scripts in dependency order, restarts the crashed processes and writes the output to the " +
+			"<service>/<instance>/stdout.log|stderr.log files and to the console. Use Ctrl-C to stop all the services. Containers of a hybrid environment are started the same way as by `storj-up start`.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if DryRun {
+				return errs.Errorf("--dry-run is not supported by run: it starts the services, there are no changes to print")
+			}
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			rt, err := FromDir(pwd)
+			if err != nil {
+				return err
+			}
+			var sa *standalone.Standalone
+			switch r := rt.(type) {
+			case *standalone.Standalone:
+				sa = r
+			case *hybrid.Hybrid:
+				// infrastructure services are managed by docker, only the native services are supervised.
+				if err := startServices(cmd.Context(), pwd); err != nil {
+					return errs.Errorf("couldn't start the containers of the hybrid environment: %v", err)
+				}
+				sa = r.Native()
+			default:
+				return errs.Errorf("this subcommand is supported only for standalone and hybrid environments (use `docker compose up` for compose)")
+			}
+			st, err := recipe.GetStack()
+			if err != nil {
+				return err
+			}
+			err = rt.Reload(st)
+			if err != nil {
+				return err
+			}
+
+			sup, err := supervisor.New(sa.Programs(), os.Stdout)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer cancel()
+
+			socket := filepath.Join(pwd, supervisor.SocketName)
+			listener, err := supervisor.Listen(ctx, socket)
+			if err != nil {
+				return err
+			}
+			defer func() { _ = os.Remove(socket) }()
+			go func() {
+				if err := sup.Serve(ctx, listener); err != nil {
+					fmt.Fprintln(os.Stderr, "control socket is closed:", err)
+				}
+			}()
+
+			return sup.Run(ctx)
+		},
+	}
+	cmd.AddCommand(runControlCmd("status", "print the state of the supervised services", func(c *supervisor.Client, ctx context.Context, _ ...string) ([]supervisor.Status, error) {
+		return c.Status(ctx)
+	}))
+	cmd.AddCommand(runControlCmd("start", "start the stopped services (all, if no service is specified)", (*supervisor.Client).Start))
+	cmd.AddCommand(runControlCmd("stop", "stop the services (all, if no service is specified)", (*supervisor.Client).Stop))
+	cmd.AddCommand(runControlCmd("restart", "restart the services (all, if no service is specified)", (*supervisor.Client).Restart))
+	return cmd
+}
+
+func runControlCmd(op string, short string, call func(c *supervisor.Client, ctx context.Context, names ...string) ([]supervisor.Status, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   op + " [<name>...]",
+		Short: short + ". Names are the same as the names of the generated scripts (like storagenode3)",
+		RunE: func(cmd *cobra.Command, names []string) error {
+			if DryRun {
+				return errs.Errorf("--dry-run is not supported by run %s: there are no changes to print", op)
+			}
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+			defer cancel()
+			status, err := call(supervisor.NewClient(filepath.Join(pwd, supervisor.SocketName)), ctx, names...)
+			printProcessStatus(status)
+			return err
+		},
+	}
+}
+
+func printProcessStatus(status []supervisor.Status) {
+	for _, s := range status {
+		pid := ""
+		if s.PID > 0 {
+			pid = fmt.Sprintf("pid %d", s.PID)
+		}
+		fmt.Printf("%-25s %-10s %-12s restarts: %-4d since %s %s\n", s.Name, s.State, pid, s.Restarts, s.Since.Format(time.TimeOnly), s.LastError)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(runCmd())
+}
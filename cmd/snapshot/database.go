@@ -0,0 +1,144 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package snapshot
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// cockroachBackup is the name of the backup collection in the external IO directory of cockroach (nodelocal://1/).
+const cockroachBackup = "storj-up-snapshot"
+
+// dumped are the database services which are saved with the dump tools of the databases (instead of copying the data
+// directories), when they are running.
+var dumped = []string{"cockroach", "postgres"}
+
+// databaseService is a running database container.
+type databaseService struct {
+	service runtime.Service
+	// name is the name of the compose service.
+	name string
+}
+
+// databaseDump is a dump of a database service. Path is relative to the snapshot directory.
+type databaseDump struct {
+	Service string `json:"service"`
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+}
+
+// dumpDatabase dumps all the databases of a running cockroach (full cluster backup, `cockroach dump` is not available
+// in the recent versions) or postgres (pg_dumpall) container to <dir>/databases.
+func dumpDatabase(ctx context.Context, pwd string, rt runtime.Runtime, db databaseService, dir string) (databaseDump, error) {
+	dump := databaseDump{
+		Service: db.name,
+		Kind:    db.service.ID().Name,
+	}
+	err := os.MkdirAll(filepath.Join(dir, "databases"), 0o755)
+	if err != nil {
+		return dump, errs.Wrap(err)
+	}
+	switch dump.Kind {
+	case "cockroach":
+		dump.Path = path.Join("databases", db.name)
+		extern := path.Join(rt.Get(db.service.ID(), "dir"), "extern", cockroachBackup)
+		err = composeExec(ctx, pwd, db.name, "rm", "-rf", extern)
+		if err != nil {
+			return dump, err
+		}
+		err = cockroachSQL(ctx, pwd, db.name, "BACKUP INTO 'nodelocal://1/"+cockroachBackup+"'")
+		if err == nil {
+			err = dockerCLI(ctx, pwd, nil, "compose", "cp", db.name+":"+extern, filepath.Join(dir, dump.Path))
+		}
+		return dump, errs.Combine(err, composeExec(ctx, pwd, db.name, "rm", "-rf", extern))
+	case "postgres":
+		dump.Path = path.Join("databases", db.name+".sql")
+		out, err := os.Create(filepath.Join(dir, dump.Path))
+		if err != nil {
+			return dump, errs.Wrap(err)
+		}
+		err = dockerTo(ctx, pwd, nil, out, "compose", "exec", "-T", db.name,
+			"pg_dumpall", "-U", "postgres", "-h", "localhost", "--clean", "--if-exists")
+		return dump, errs.Combine(err, errs.Wrap(out.Close()))
+	}
+	return dump, errs.Errorf("dump of %s is not supported", dump.Kind)
+}
+
+// restoreDatabase starts the (cleaned) database container and loads the dump.
+func restoreDatabase(ctx context.Context, e *engine, rt runtime.Runtime, dump databaseDump, dir string) error {
+	err := e.up(ctx, dump.Service)
+	if err != nil {
+		return err
+	}
+	pwd := e.pwd
+	switch dump.Kind {
+	case "cockroach":
+		err = waitDatabase(ctx, pwd, dump.Service, "cockroach", "sql", "--insecure", "-e", "SELECT 1")
+		if err != nil {
+			return err
+		}
+		extern := path.Join(rt.Get(runtime.NewServiceInstance(dump.Kind, 0), "dir"), "extern")
+		err = composeExec(ctx, pwd, dump.Service, "mkdir", "-p", extern)
+		if err != nil {
+			return err
+		}
+		err = dockerCLI(ctx, pwd, nil, "compose", "cp", filepath.Join(dir, dump.Path), dump.Service+":"+path.Join(extern, cockroachBackup))
+		if err == nil {
+			err = cockroachSQL(ctx, pwd, dump.Service, "RESTORE FROM LATEST IN 'nodelocal://1/"+cockroachBackup+"'")
+		}
+		return errs.Combine(err, composeExec(ctx, pwd, dump.Service, "rm", "-rf", path.Join(extern, cockroachBackup)))
+	case "postgres":
+		err = waitDatabase(ctx, pwd, dump.Service, "psql", "-U", "postgres", "-h", "localhost", "-c", "SELECT 1")
+		if err != nil {
+			return err
+		}
+		in, err := os.Open(filepath.Join(dir, dump.Path))
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		defer func() { _ = in.Close() }()
+		// the errors of the existing role (postgres) are expected, the dump is restored to a new cluster
+		return dockerCLI(ctx, pwd, in, "compose", "exec", "-T", dump.Service,
+			"psql", "-U", "postgres", "-h", "localhost", "-d", "postgres", "-q", "-f", "-")
+	}
+	return errs.Errorf("restore of %s is not supported", dump.Kind)
+}
+
+// composeExec executes a command in a running compose container.
+func composeExec(ctx context.Context, pwd string, service string, args ...string) error {
+	return dockerCLI(ctx, pwd, nil, append([]string{"compose", "exec", "-T", service}, args...)...)
+}
+
+func cockroachSQL(ctx context.Context, pwd string, service string, statement string) error {
+	return composeExec(ctx, pwd, service, "cockroach", "sql", "--insecure", "-e", statement)
+}
+
+// waitDatabase waits until the probe command is successful in the container. The database containers are started
+// in the background, and the first start (initialization) can take a while.
+func waitDatabase(ctx context.Context, pwd string, service string, probe ...string) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	for {
+		c := exec.CommandContext(ctx, "docker", append([]string{"compose", "exec", "-T", service}, probe...)...)
+		c.Dir = pwd
+		err := c.Run()
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return errs.Errorf("%s is not ready: %v", service, err)
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+)
+
+// engine manages the containers of the compose project with the Docker Engine API, or with the docker CLI if the API
+// is not available. Commands of the database containers are always executed with `docker compose exec`.
+type engine struct {
+	pwd     string
+	client  *docker.Client
+	project *types.Project
+}
+
+// newEngine loads the compose project of the directory, if the API is available.
+func newEngine(ctx context.Context, pwd string) (*engine, error) {
+	e := &engine{pwd: pwd}
+	client, err := docker.Available(ctx)
+	if err != nil {
+		return e, nil
+	}
+	e.client = client
+	if _, err := os.Stat(filepath.Join(pwd, common.ComposeFileName)); err != nil {
+		return e, nil
+	}
+	e.project, err = docker.LoadProject(pwd, common.ComposeFileName)
+	return e, err
+}
+
+// running returns with the compose services which have running containers.
+func (e *engine) running(ctx context.Context) ([]string, error) {
+	if e.project == nil {
+		return dockerOutput(ctx, e.pwd, "compose", "ps", "--services", "--status", "running")
+	}
+	states, err := e.client.Status(ctx, e.project.Name)
+	if err != nil {
+		return nil, err
+	}
+	var services []string
+	for _, state := range states {
+		if state.State == "running" && !slices.Contains(services, state.Service) {
+			services = append(services, state.Service)
+		}
+	}
+	return services, nil
+}
+
+// stop stops the containers of the services.
+func (e *engine) stop(ctx context.Context, services []string) error {
+	if e.project == nil {
+		return dockerCLI(ctx, e.pwd, nil, append([]string{"compose", "stop"}, services...)...)
+	}
+	return e.client.Stop(ctx, e.project.Name, 10, services...)
+}
+
+// start starts the existing containers of the services.
+func (e *engine) start(ctx context.Context, services []string) error {
+	if e.project == nil {
+		return dockerCLI(ctx, e.pwd, nil, append([]string{"compose", "start"}, services...)...)
+	}
+	return e.client.Start(ctx, e.project.Name, services...)
+}
+
+// create creates the containers of the services (and their dependencies), without starting them.
+func (e *engine) create(ctx context.Context, services []string) error {
+	if e.project == nil {
+		return dockerCLI(ctx, e.pwd, nil, append([]string{"compose", "create"}, services...)...)
+	}
+	return e.client.Create(ctx, e.project, printProgress, services...)
+}
+
+// up creates and starts the containers of a service and its dependencies.
+func (e *engine) up(ctx context.Context, service string) error {
+	if e.project == nil {
+		return dockerCLI(ctx, e.pwd, nil, "compose", "up", "-d", service)
+	}
+	return e.client.Up(ctx, e.project, printProgress, service)
+}
+
+func printProgress(service string, m docker.Message) {
+	fmt.Printf("%-25s %s\n", service, m)
+}
+
+// containerID returns with the ID of the container of a service, or empty string if it's not created.
+func (e *engine) containerID(ctx context.Context, service string) (string, error) {
+	if e.project == nil {
+		ids, err := dockerOutput(ctx, e.pwd, "compose", "ps", "--all", "--quiet", service)
+		if err != nil || len(ids) == 0 {
+			return "", err
+		}
+		return ids[0], nil
+	}
+	return e.client.ServiceContainer(ctx, e.project.Name, service)
+}
+
+// saveVolume archives the content of a named volume to <dir>/<volume>.tar.
+func (e *engine) saveVolume(ctx context.Context, volume string, dir string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return cmd.RunHelper(ctx, []docker.Mount{
+		{Type: types.VolumeTypeVolume, Source: volume, Target: "/volume", ReadOnly: true},
+		{Type: types.VolumeTypeBind, Source: dir, Target: "/backup"},
+	}, "tar", "-cf", "/backup/"+volume+".tar", "-C", "/volume", ".")
+}
+
+// restoreVolume recreates a named volume with the content of <dir>/<volume>.tar.
+func (e *engine) restoreVolume(ctx context.Context, volume string, dir string) error {
+	var err error
+	if e.client == nil {
+		err = dockerCLI(ctx, e.pwd, nil, "volume", "rm", "--force", volume)
+	} else {
+		err = e.client.RemoveVolume(ctx, volume)
+	}
+	if err != nil {
+		return err
+	}
+	return cmd.RunHelper(ctx, []docker.Mount{
+		{Type: types.VolumeTypeVolume, Source: volume, Target: "/volume"},
+		{Type: types.VolumeTypeBind, Source: dir, Target: "/backup", ReadOnly: true},
+	}, "tar", "-xf", "/backup/"+volume+".tar", "-C", "/volume")
+}
+
+// saveContainerDir archives a directory of a (stopped) compose container. Returns with false, if the container or the
+// directory doesn't exist.
+func (e *engine) saveContainerDir(ctx context.Context, saved containerDir, dir string) (bool, error) {
+	id, err := e.containerID(ctx, saved.Service)
+	if err != nil || id == "" {
+		return false, err
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	archive, err := os.Create(filepath.Join(dir, saved.Archive))
+	if err != nil {
+		return false, errs.Wrap(err)
+	}
+	if e.project != nil {
+		err = errs.Combine(e.client.CopyFromContainer(ctx, id, saved.Dir, archive), archive.Close())
+		if err != nil {
+			_ = os.Remove(archive.Name())
+			// the directory is created only when the service is started first
+			if docker.IsNotFound(err) {
+				return false, nil
+			}
+			return false, errs.Errorf("couldn't copy %s from %s: %v", saved.Dir, saved.Service, err)
+		}
+		return true, nil
+	}
+	stderr := &bytes.Buffer{}
+	c := exec.CommandContext(ctx, "docker", "cp", id+":"+saved.Dir, "-")
+	c.Dir = e.pwd
+	c.Stdout = archive
+	c.Stderr = stderr
+	err = errs.Combine(c.Run(), archive.Close())
+	if err != nil {
+		_ = os.Remove(archive.Name())
+		if strings.Contains(stderr.String(), "Could not find the file") {
+			return false, nil
+		}
+		return false, errs.Errorf("couldn't copy %s from %s: %v %s", saved.Dir, saved.Service, err, stderr.String())
+	}
+	return true, nil
+}
+
+// restoreContainerDir extracts the archive of a directory to the (created, but not started) compose container.
+func (e *engine) restoreContainerDir(ctx context.Context, saved containerDir, dir string) error {
+	id, err := e.containerID(ctx, saved.Service)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return errs.Errorf("container of %s is not found", saved.Service)
+	}
+	archive, err := os.Open(filepath.Join(dir, saved.Archive))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() { _ = archive.Close() }()
+	// the archive contains the directory itself
+	if e.project != nil {
+		return e.client.CopyToContainer(ctx, id, path.Dir(saved.Dir), archive)
+	}
+	return dockerCLI(ctx, e.pwd, archive, "cp", "-", id+":"+path.Dir(saved.Dir))
+}
+
+// saveArchive archives a file or directory which can't be read on the host (like the files of the containers owned by
+// root) with a helper container.
+func saveArchive(ctx context.Context, src string, archive string) error {
+	err := os.MkdirAll(filepath.Dir(archive), 0o755)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return cmd.RunHelper(ctx, []docker.Mount{
+		{Type: types.VolumeTypeBind, Source: filepath.Dir(src), Target: "/source", ReadOnly: true},
+		{Type: types.VolumeTypeBind, Source: filepath.Dir(archive), Target: "/backup"},
+	}, "tar", "-cf", "/backup/"+filepath.Base(archive), "-C", "/source", filepath.Base(src))
+}
+
+// restoreArchive replaces a file or directory with the content of an archive created by saveArchive, with a helper
+// container. Ownership of the files is preserved.
+func restoreArchive(ctx context.Context, archive string, dst string) error {
+	err := os.MkdirAll(filepath.Dir(dst), 0o755)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return cmd.RunHelper(ctx, []docker.Mount{
+		{Type: types.VolumeTypeBind, Source: filepath.Dir(dst), Target: "/target"},
+		{Type: types.VolumeTypeBind, Source: filepath.Dir(archive), Target: "/backup", ReadOnly: true},
+	}, "sh", "-c", `rm -rf "/target/$1" && tar -xf "/backup/$2" -C /target`, "sh", filepath.Base(dst), filepath.Base(archive))
+}
+
+// dockerCLI executes a docker command in the project directory.
+func dockerCLI(ctx context.Context, pwd string, stdin io.Reader, args ...string) error {
+	return dockerTo(ctx, pwd, stdin, os.Stdout, args...)
+}
+
+func dockerTo(ctx context.Context, pwd string, stdin io.Reader, stdout io.Writer, args ...string) error {
+	c := exec.CommandContext(ctx, "docker", args...)
+	c.Dir = pwd
+	c.Stdin = stdin
+	c.Stdout = stdout
+	c.Stderr = os.Stderr
+	err := c.Run()
+	if err != nil {
+		return errs.Errorf("couldn't execute docker %s: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// dockerOutput executes a docker command and returns with the non-empty lines of the output.
+func dockerOutput(ctx context.Context, pwd string, args ...string) ([]string, error) {
+	out := &bytes.Buffer{}
+	err := dockerTo(ctx, pwd, nil, out, args...)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
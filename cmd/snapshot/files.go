@@ -0,0 +1,118 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package snapshot
+
+import (
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// copyPath copies a file, a symbolic link or a directory (recursively) to dst. Parent directories of dst are created.
+// Symbolic links are copied as links (they can point to paths of the containers), other special files (like sockets)
+// are skipped.
+func copyPath(src string, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	err = os.MkdirAll(filepath.Dir(dst), 0o755)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return copyEntry(src, dst, info)
+}
+
+func copyEntry(src string, dst string, info fs.FileInfo) error {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		return errs.Wrap(os.Symlink(target, dst))
+	case info.IsDir():
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		// the copy is always writable by the owner, so it can be removed
+		err = os.MkdirAll(dst, info.Mode().Perm()|0o700)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		for _, entry := range entries {
+			child, err := entry.Info()
+			if err != nil {
+				return errs.Wrap(err)
+			}
+			err = copyEntry(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name()), child)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case info.Mode().IsRegular():
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+	return nil
+}
+
+func copyFile(src string, dst string, perm fs.FileMode) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() { _ = in.Close() }()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() { err = errs.Combine(err, out.Close()) }()
+	_, err = io.Copy(out, in)
+	return errs.Wrap(err)
+}
+
+// replacePath copies src next to dst first, and replaces dst only if the copy is complete.
+func replacePath(src string, dst string) error {
+	tmp := filepath.Join(filepath.Dir(dst), "."+filepath.Base(dst)+".restore")
+	err := os.RemoveAll(tmp)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	err = copyPath(src, tmp)
+	if err != nil {
+		return errs.Combine(err, os.RemoveAll(tmp))
+	}
+	err = os.RemoveAll(dst)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return errs.Wrap(os.Rename(tmp, dst))
+}
+
+// relative returns with the path relative to the project directory. Paths outside the project are not supported.
+func relative(pwd string, path string) (string, error) {
+	rel, err := filepath.Rel(pwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errs.Errorf("%s is outside of the project directory", path)
+	}
+	return rel, nil
+}
+
+// reachable checks if a TCP connection can be opened to the address.
+func reachable(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
@@ -0,0 +1,703 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package snapshot contains the commands to save and restore the full state of an environment.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/cmd"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/common/atomicfile"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/hybrid"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/runtime/standalone"
+)
+
+// Dir is the directory of the snapshots, inside the project directory.
+const Dir = ".snapshots"
+
+const manifestName = "snapshot.json"
+
+// backupName is the directory of the state saved before a restore (inside Dir), hidden from the list of snapshots.
+const backupName = ".restore-backup"
+
+// descriptors are the files which define the environment.
+var descriptors = []string{common.ComposeFileName, standalone.StateFileName}
+
+// manifest describes the content of a saved snapshot. All the paths are relative to the project directory.
+type manifest struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	// Files are the descriptors of the environment (docker-compose.yaml, standalone state).
+	Files []string `json:"files,omitempty"`
+	// Paths are the persisted files and directories on the host.
+	Paths []string `json:"paths,omitempty"`
+	// Archives are the persisted paths which can't be read on the host (like the files of the containers owned by
+	// root). They are saved to archives/<path>.tar with a helper container, with the ownership of the files.
+	Archives []string `json:"archives,omitempty"`
+	// Volumes are the named docker volumes.
+	Volumes []string `json:"volumes,omitempty"`
+	// Containers are the persisted directories which are stored only in the containers.
+	Containers []containerDir `json:"containers,omitempty"`
+	// Spanner are the exported databases of the spanner emulator.
+	Spanner []string `json:"spanner,omitempty"`
+	// Databases are the dumps of the database services. Their data directories are not saved.
+	Databases []databaseDump `json:"databases,omitempty"`
+}
+
+// containerDir is a directory of a compose service, saved as a tar archive.
+type containerDir struct {
+	Service string `json:"service"`
+	Dir     string `json:"dir"`
+	Archive string `json:"archive"`
+}
+
+// environment is the loaded runtime with the state of all the services.
+type environment struct {
+	rt         runtime.Runtime
+	containers []string
+	processes  []string
+	states     []runtime.ServiceState
+	spanner    []runtime.Service
+	databases  []databaseService
+}
+
+func init() {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Args:  cobra.NoArgs,
+		Short: "save and restore the full state of the environment (persisted data, volumes, databases and the configuration)",
+	}
+	snapshotCmd.AddCommand(saveCmd(), restoreCmd(), listCmd(), deleteCmd())
+	cmd.RootCmd.AddCommand(snapshotCmd)
+}
+
+func saveCmd() *cobra.Command {
+	var force bool
+	saveCmd := &cobra.Command{
+		Use:   "save <name>",
+		Short: "save the state of the environment",
+		Long: "Save the configuration and the data of all the services to " + Dir + "/<name>. The spanner emulator is exported " +
+			"first (it stores everything in memory), and the running cockroach and postgres containers are dumped (BACKUP, " +
+			"pg_dumpall), then the services are stopped while the persisted directories, volumes and container directories " +
+			"are copied. The stopped services are started again at the end.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if cmd.DryRun {
+				return errs.Errorf("--dry-run is not supported by snapshot")
+			}
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+			return save(c.Context(), pwd, args[0], force)
+		},
+	}
+	saveCmd.Flags().BoolVarP(&force, "force", "f", false, "overwrite the existing snapshot with the same name")
+	return saveCmd
+}
+
+func restoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "restore a saved state of the environment",
+		Long: "Stop the services, remove their current data and restore the configuration and data of the snapshot. " +
+			"Containers are recreated (but not started) with the saved directories. The spanner emulator and the database " +
+			"containers are started to import the saved databases. The current state is saved first, and restored if " +
+			"the restore fails.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(c *cobra.Command, args []string) (err error) {
+			if cmd.DryRun {
+				return errs.Errorf("--dry-run is not supported by snapshot")
+			}
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			unlock, err := common.LockProject(pwd)
+			if err != nil {
+				return err
+			}
+			defer func() { err = errs.Combine(err, unlock()) }()
+			return restore(c.Context(), pwd, args[0])
+		},
+	}
+}
+
+func listCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "list the saved snapshots",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			snapshots, err := list(pwd)
+			if err != nil {
+				return err
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(tw, "NAME\tCREATED\tCONTENT")
+			for _, m := range snapshots {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", m.Name, m.Created.Format(time.DateTime), m.summary())
+			}
+			return errs.Wrap(tw.Flush())
+		},
+	}
+}
+
+func deleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "delete a saved snapshot",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			pwd, err := cmd.ProjectDir()
+			if err != nil {
+				return err
+			}
+			dir, err := snapshotDir(pwd, args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := readManifest(dir); err != nil {
+				return err
+			}
+			return errs.Wrap(os.RemoveAll(dir))
+		},
+	}
+}
+
+// save saves the snapshot to a temporary directory, which is renamed at the end.
+func save(ctx context.Context, pwd string, name string, force bool) error {
+	target, err := snapshotDir(pwd, name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err == nil && !force {
+		return errs.Errorf("snapshot %s already exists (use --force to overwrite it)", name)
+	}
+	m, err := saveTo(ctx, pwd, target, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Snapshot %s is saved (%s)\n", name, m.summary())
+	return nil
+}
+
+// saveTo saves the snapshot to the target directory. Existing target is replaced.
+func saveTo(ctx context.Context, pwd string, target string, name string) (m manifest, err error) {
+	env, err := load(pwd)
+	if err != nil {
+		return m, err
+	}
+
+	err = os.MkdirAll(filepath.Join(pwd, Dir), 0o755)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Join(pwd, Dir), "."+name+".*")
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	m = manifest{
+		Name:    name,
+		Created: time.Now(),
+	}
+
+	// spanner emulator keeps everything in memory, it's exported while it's running.
+	for _, s := range env.spanner {
+		address := env.rt.GetHost(s.ID(), "external") + ":9010"
+		if !reachable(address) {
+			fmt.Printf("Spanner emulator is not running at %s, databases are not saved\n", address)
+			continue
+		}
+		fmt.Println("Exporting spanner databases from " + address)
+		databases, err := exportSpanner(ctx, address, spannerInstance(s), filepath.Join(tmp, "spanner"))
+		if err != nil {
+			return m, err
+		}
+		m.Spanner = append(m.Spanner, databases...)
+	}
+
+	e, err := newEngine(ctx, pwd)
+	if err != nil {
+		return m, err
+	}
+	var running []string
+	if len(env.containers) > 0 {
+		running, err = e.running(ctx)
+		if err != nil {
+			return m, err
+		}
+	}
+
+	// databases are dumped with their own tools while they are running, data directories of stopped databases are copied.
+	dumpedServices := map[string]bool{}
+	for _, db := range env.databases {
+		if !slices.Contains(running, db.name) {
+			continue
+		}
+		fmt.Println("Dumping the databases of " + db.name)
+		dump, err := dumpDatabase(ctx, pwd, env.rt, db, tmp)
+		if err != nil {
+			return m, err
+		}
+		m.Databases = append(m.Databases, dump)
+		dumpedServices[db.name] = true
+	}
+
+	if len(env.containers) > 0 {
+		fmt.Println("Stopping the containers")
+		err = e.stop(ctx, env.containers)
+		if err != nil {
+			return m, err
+		}
+		defer func() {
+			if len(running) > 0 {
+				err = errs.Combine(err, e.start(ctx, running))
+			}
+		}()
+	}
+	if len(env.processes) > 0 {
+		cmd.StopProcesses(ctx, pwd, env.processes)
+		defer cmd.StartProcesses(ctx, pwd, env.processes)
+	}
+
+	for _, file := range descriptors {
+		if _, err := os.Stat(filepath.Join(pwd, file)); err != nil {
+			continue
+		}
+		err = copyPath(filepath.Join(pwd, file), filepath.Join(tmp, "files", file))
+		if err != nil {
+			return m, err
+		}
+		m.Files = append(m.Files, file)
+	}
+
+	for _, state := range env.states {
+		paths := append([]string{}, state.Config...)
+		if !dumpedServices[state.Name] {
+			paths = append(paths, state.Paths...)
+		}
+		for _, path := range paths {
+			// bind mounts are created only when the service is started first
+			if _, err := os.Lstat(path); os.IsNotExist(err) {
+				continue
+			}
+			rel, err := relative(pwd, path)
+			if err != nil {
+				fmt.Println(err.Error() + ", skipped")
+				continue
+			}
+			err = copyPath(path, filepath.Join(tmp, "data", rel))
+			if errors.Is(err, fs.ErrPermission) {
+				fmt.Printf("%s can't be read (%v), saving it with a container\n", rel, err)
+				err = os.RemoveAll(filepath.Join(tmp, "data", rel))
+				if err != nil {
+					return m, errs.Wrap(err)
+				}
+				err = saveArchive(ctx, path, filepath.Join(tmp, "archives", rel+".tar"))
+				if err != nil {
+					return m, err
+				}
+				m.Archives = append(m.Archives, rel)
+				continue
+			}
+			if err != nil {
+				return m, err
+			}
+			m.Paths = append(m.Paths, rel)
+		}
+		if dumpedServices[state.Name] {
+			continue
+		}
+		for _, volume := range state.Volumes {
+			fmt.Println("Saving volume " + volume)
+			err = e.saveVolume(ctx, volume, filepath.Join(tmp, "volumes"))
+			if err != nil {
+				return m, err
+			}
+			m.Volumes = append(m.Volumes, volume)
+		}
+		for i, dir := range state.Internal {
+			saved := containerDir{
+				Service: state.Name,
+				Dir:     dir,
+				Archive: fmt.Sprintf("%s-%d.tar", state.Name, i),
+			}
+			fmt.Printf("Saving %s of %s\n", dir, state.Name)
+			found, err := e.saveContainerDir(ctx, saved, filepath.Join(tmp, "containers"))
+			if err != nil {
+				return m, err
+			}
+			if found {
+				m.Containers = append(m.Containers, saved)
+			}
+		}
+	}
+
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	err = atomicfile.WriteFile(filepath.Join(tmp, manifestName), raw, 0o644)
+	if err != nil {
+		return m, err
+	}
+	err = os.RemoveAll(target)
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	return m, errs.Wrap(os.Rename(tmp, target))
+}
+
+// restore replaces the current environment with the content of the snapshot. Everything is checked before the first
+// modification, and the current state is saved first: the environment is rolled back to it, if the restore fails.
+func restore(ctx context.Context, pwd string, name string) error {
+	dir, err := snapshotDir(pwd, name)
+	if err != nil {
+		return err
+	}
+	m, err := readManifest(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range m.Files {
+		if _, err := os.Stat(filepath.Join(dir, "files", file)); err != nil {
+			return errs.Errorf("snapshot %s is incomplete: %v", name, err)
+		}
+	}
+	for _, path := range m.Paths {
+		if _, err := os.Lstat(filepath.Join(dir, "data", path)); err != nil {
+			return errs.Errorf("snapshot %s is incomplete: %v", name, err)
+		}
+	}
+	for _, path := range m.Archives {
+		if _, err := os.Stat(filepath.Join(dir, "archives", path+".tar")); err != nil {
+			return errs.Errorf("snapshot %s is incomplete: %v", name, err)
+		}
+	}
+	for _, dump := range m.Databases {
+		if _, err := os.Stat(filepath.Join(dir, dump.Path)); err != nil {
+			return errs.Errorf("snapshot %s is incomplete: %v", name, err)
+		}
+	}
+
+	backup := ""
+	if _, err := cmd.FromDir(pwd); err == nil {
+		backup = filepath.Join(pwd, Dir, backupName)
+		fmt.Println("Saving the current state")
+		if _, err := saveTo(ctx, pwd, backup, backupName); err != nil {
+			return errs.Errorf("couldn't save the current state: %v", err)
+		}
+	}
+	err = apply(ctx, pwd, dir, m)
+	if err != nil && backup != "" {
+		fmt.Printf("Restore is failed (%v), rolling back to the previous state\n", err)
+		previous, rollbackErr := readManifest(backup)
+		if rollbackErr == nil {
+			rollbackErr = apply(ctx, pwd, backup, previous)
+		}
+		if rollbackErr != nil {
+			return errs.Errorf("restore is failed: %v, and the rollback is failed: %v (previous state is saved to %s)", err, rollbackErr, backup)
+		}
+		return errs.Combine(errs.Errorf("restore is failed, previous state is restored: %v", err), errs.Wrap(os.RemoveAll(backup)))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Snapshot %s is restored, services can be started again\n", name)
+	if backup != "" {
+		return errs.Wrap(os.RemoveAll(backup))
+	}
+	return nil
+}
+
+// apply replaces the current environment with the content of a checked snapshot directory.
+func apply(ctx context.Context, pwd string, dir string, m manifest) error {
+	// current services are stopped and their data is removed, so nothing is left from the current state.
+	_, err := cmd.FromDir(pwd)
+	if err == nil {
+		err = cmd.CleanData(ctx, pwd, nil)
+		if err != nil {
+			return err
+		}
+	}
+	for _, file := range descriptors {
+		if !slices.Contains(m.Files, file) {
+			if err := os.Remove(filepath.Join(pwd, file)); err != nil && !os.IsNotExist(err) {
+				return errs.Wrap(err)
+			}
+		}
+	}
+
+	for _, file := range m.Files {
+		raw, err := os.ReadFile(filepath.Join(dir, "files", file))
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		err = atomicfile.WriteFile(filepath.Join(pwd, file), raw, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+	if slices.Contains(m.Files, standalone.StateFileName) {
+		err = regenerate(pwd)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, path := range m.Paths {
+		fmt.Println("Restoring " + path)
+		err = replacePath(filepath.Join(dir, "data", path), filepath.Join(pwd, path))
+		if err != nil {
+			return err
+		}
+	}
+	for _, path := range m.Archives {
+		fmt.Println("Restoring " + path)
+		err = restoreArchive(ctx, filepath.Join(dir, "archives", path+".tar"), filepath.Join(pwd, path))
+		if err != nil {
+			return err
+		}
+	}
+
+	// the compose file of the snapshot is already restored
+	e, err := newEngine(ctx, pwd)
+	if err != nil {
+		return err
+	}
+	for _, volume := range m.Volumes {
+		fmt.Println("Restoring volume " + volume)
+		err = e.restoreVolume(ctx, volume, filepath.Join(dir, "volumes"))
+		if err != nil {
+			return err
+		}
+	}
+
+	var services []string
+	for _, c := range m.Containers {
+		if !slices.Contains(services, c.Service) {
+			services = append(services, c.Service)
+		}
+	}
+	if len(services) > 0 {
+		err = e.create(ctx, services)
+		if err != nil {
+			return err
+		}
+		for _, c := range m.Containers {
+			fmt.Printf("Restoring %s of %s\n", c.Dir, c.Service)
+			err = e.restoreContainerDir(ctx, c, filepath.Join(dir, "containers"))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(m.Spanner) > 0 {
+		err = restoreSpanner(ctx, e, filepath.Join(dir, "spanner"), m.Spanner)
+		if err != nil {
+			return err
+		}
+	}
+	if len(m.Databases) > 0 {
+		rt, err := cmd.FromDir(pwd)
+		if err != nil {
+			return err
+		}
+		for _, dump := range m.Databases {
+			fmt.Println("Restoring the databases of " + dump.Service)
+			err = restoreDatabase(ctx, e, rt, dump, dir)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restoreSpanner starts the spanner emulator (if it's a container) and imports the saved databases.
+func restoreSpanner(ctx context.Context, e *engine, dir string, databases []string) error {
+	env, err := load(e.pwd)
+	if err != nil {
+		return err
+	}
+	if len(env.spanner) == 0 {
+		return errs.Errorf("snapshot contains spanner databases, but there is no spanner service")
+	}
+	s := env.spanner[0]
+	if slices.Contains(env.containers, s.ID().Name) {
+		err = e.up(ctx, s.ID().Name)
+		if err != nil {
+			return err
+		}
+	}
+	address := env.rt.GetHost(s.ID(), "external") + ":9010"
+	fmt.Println("Importing spanner databases to " + address)
+	return importSpanner(ctx, address, spannerInstance(s), dir, databases)
+}
+
+// regenerate writes the scripts of the standalone services from the restored state file.
+func regenerate(pwd string) error {
+	rt, err := cmd.FromDir(pwd)
+	if err != nil {
+		return err
+	}
+	st, err := recipe.GetStack()
+	if err != nil {
+		return err
+	}
+	err = rt.Reload(st)
+	if err != nil {
+		return err
+	}
+	switch r := rt.(type) {
+	case *standalone.Standalone:
+		return r.Write()
+	case *hybrid.Hybrid:
+		return r.Native().Write()
+	}
+	return nil
+}
+
+// load reads the environment and collects the state of all the services.
+func load(pwd string) (*environment, error) {
+	rt, err := cmd.FromDir(pwd)
+	if err != nil {
+		return nil, err
+	}
+	st, err := recipe.GetStack()
+	if err != nil {
+		return nil, err
+	}
+	err = rt.Reload(st)
+	if err != nil {
+		return nil, err
+	}
+	cleaner, ok := rt.(runtime.Cleaner)
+	if !ok {
+		return nil, errs.Errorf("snapshot is not supported by this runtime")
+	}
+	env := &environment{rt: rt}
+	for _, s := range rt.GetServices() {
+		var persisted []string
+		if rService, err := st.FindRecipeByName(s.ID().Name); err == nil {
+			persisted = rService.Persistence
+		}
+		state := cleaner.State(s, persisted)
+		if state.Name == "" {
+			continue
+		}
+		if state.Container {
+			env.containers = append(env.containers, state.Name)
+		} else {
+			env.processes = append(env.processes, state.Name)
+		}
+		env.states = append(env.states, state)
+		if s.ID().Name == "spanner" {
+			env.spanner = append(env.spanner, s)
+		}
+		if state.Container && slices.Contains(dumped, s.ID().Name) {
+			env.databases = append(env.databases, databaseService{service: s, name: state.Name})
+		}
+	}
+	slices.Sort(env.containers)
+	slices.Sort(env.processes)
+	return env, nil
+}
+
+// list returns with the saved snapshots, ordered by name.
+func list(pwd string) ([]manifest, error) {
+	entries, err := os.ReadDir(filepath.Join(pwd, Dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var res []manifest
+	for _, entry := range entries {
+		// temporary directories of interrupted saves
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		m, err := readManifest(filepath.Join(pwd, Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func readManifest(dir string) (manifest, error) {
+	var m manifest
+	raw, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if os.IsNotExist(err) {
+		return m, errs.Errorf("snapshot %s is not found", filepath.Base(dir))
+	}
+	if err != nil {
+		return m, errs.Wrap(err)
+	}
+	err = json.Unmarshal(raw, &m)
+	if err != nil {
+		return m, errs.Errorf("couldn't parse %s: %v", filepath.Join(dir, manifestName), err)
+	}
+	return m, nil
+}
+
+// snapshotDir returns with the directory of a named snapshot.
+func snapshotDir(pwd string, name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return "", errs.Errorf("invalid snapshot name: %q", name)
+	}
+	return filepath.Join(pwd, Dir, name), nil
+}
+
+// summary returns with the number of the saved items.
+func (m manifest) summary() string {
+	var parts []string
+	add := func(n int, kind string) {
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	add(len(m.Files), "descriptors")
+	add(len(m.Paths)+len(m.Archives), "paths")
+	add(len(m.Volumes), "volumes")
+	add(len(m.Containers), "container dirs")
+	add(len(m.Spanner), "spanner databases")
+	add(len(m.Databases), "database dumps")
+	if len(parts) == 0 {
+		return "empty"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/docker"
+)
+
+func TestList(t *testing.T) {
+	pwd := t.TempDir()
+	snapshots, err := list(pwd)
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
+	for _, name := range []string{"uploaded", "disqualified"} {
+		dir, err := snapshotDir(pwd, name)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		raw, err := json.Marshal(manifest{Name: name, Created: time.Now(), Files: []string{"docker-compose.yaml"}, Spanner: []string{"master", "metainfo"}})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, manifestName), raw, 0o644))
+	}
+	// interrupted save
+	require.NoError(t, os.MkdirAll(filepath.Join(pwd, Dir, ".broken.123"), 0o755))
+
+	snapshots, err = list(pwd)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	require.Equal(t, "disqualified", snapshots[0].Name)
+	require.Equal(t, "1 descriptors, 2 spanner databases", snapshots[0].summary())
+
+	_, err = readManifest(filepath.Join(pwd, Dir, "missing"))
+	require.ErrorContains(t, err, "snapshot missing is not found")
+
+	_, err = snapshotDir(pwd, "../escape")
+	require.Error(t, err)
+	_, err = snapshotDir(pwd, ".hidden")
+	require.Error(t, err)
+}
+
+func TestCopyAndReplace(t *testing.T) {
+	pwd := t.TempDir()
+	src := filepath.Join(pwd, "storagenode1", "storj")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "blobs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "blobs", "piece"), []byte("data"), 0o644))
+	// links are copied as links, even if the target exists only in the container
+	require.NoError(t, os.Symlink("blobs/piece", filepath.Join(src, "current")))
+	require.NoError(t, os.Symlink("/var/lib/storj/identity", filepath.Join(src, "identity")))
+
+	rel, err := relative(pwd, src)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join("storagenode1", "storj"), rel)
+	_, err = relative(pwd, filepath.Dir(pwd))
+	require.Error(t, err)
+
+	saved := filepath.Join(pwd, Dir, "test", "data", rel)
+	require.NoError(t, copyPath(src, saved))
+
+	// data written after the snapshot is removed by the restore
+	require.NoError(t, os.WriteFile(filepath.Join(src, "blobs", "new"), []byte("new"), 0o644))
+	require.NoError(t, replacePath(saved, src))
+
+	content, err := os.ReadFile(filepath.Join(src, "blobs", "piece"))
+	require.NoError(t, err)
+	require.Equal(t, "data", string(content))
+	require.NoFileExists(t, filepath.Join(src, "blobs", "new"))
+	content, err = os.ReadFile(filepath.Join(src, "current"))
+	require.NoError(t, err)
+	require.Equal(t, "data", string(content))
+	target, err := os.Readlink(filepath.Join(src, "identity"))
+	require.NoError(t, err)
+	require.Equal(t, "/var/lib/storj/identity", target)
+	require.NoDirExists(t, filepath.Join(pwd, "storagenode1", ".storj.restore"))
+}
+
+func TestArchive(t *testing.T) {
+	// files which can't be read on the host are archived with a helper container
+	bin := t.TempDir()
+	args := filepath.Join(bin, "args")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte("#!/bin/sh\necho \"$@\" >> "+args+"\n"), 0o755))
+	t.Setenv("PATH", bin)
+	t.Setenv(docker.CLIEnv, "true")
+
+	pwd := t.TempDir()
+	src := filepath.Join(pwd, "postgres", "data")
+	saved := filepath.Join(pwd, Dir, "test", "archives", "postgres", "data.tar")
+	require.NoError(t, saveArchive(context.Background(), src, saved))
+	require.DirExists(t, filepath.Dir(saved))
+	require.NoError(t, restoreArchive(context.Background(), saved, src))
+
+	raw, err := os.ReadFile(args)
+	require.NoError(t, err)
+	require.Equal(t, "run --rm "+
+		"--mount type=bind,source="+filepath.Dir(src)+",target=/source,readonly "+
+		"--mount type=bind,source="+filepath.Dir(saved)+",target=/backup "+
+		"busybox tar -cf /backup/data.tar -C /source data\n"+
+		"run --rm "+
+		"--mount type=bind,source="+filepath.Dir(src)+",target=/target "+
+		"--mount type=bind,source="+filepath.Dir(saved)+",target=/backup,readonly "+
+		"busybox sh -c rm -rf \"/target/$1\" && tar -xf \"/backup/$2\" -C /target sh data data.tar\n", string(raw))
+}
+
+func TestTableNames(t *testing.T) {
+	require.Equal(t, []string{"projects", "buckets", "objects"}, tableNames([]string{
+		"CREATE TABLE projects (\n  id BYTES(16) NOT NULL,\n) PRIMARY KEY(id)",
+		"CREATE INDEX projects_by_name ON projects(name)",
+		"CREATE TABLE `buckets` (\n  name BYTES(MAX) NOT NULL,\n) PRIMARY KEY(name)",
+		"create table objects (\n  key BYTES(MAX),\n) PRIMARY KEY(key)",
+	}))
+}
+
+func TestRestoreRollback(t *testing.T) {
+	// volumes can't be restored without docker
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv(docker.CLIEnv, "true")
+	pwd := t.TempDir()
+	current := "name: current\nservices: {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(pwd, "docker-compose.yaml"), []byte(current), 0o644))
+
+	dir, err := snapshotDir(pwd, "broken")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "files"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "files", "docker-compose.yaml"), []byte("name: broken\nservices: {}\n"), 0o644))
+	raw, err := json.Marshal(manifest{Name: "broken", Files: []string{"docker-compose.yaml"}, Volumes: []string{"data"}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, manifestName), raw, 0o644))
+
+	err = restore(context.Background(), pwd, "broken")
+	require.ErrorContains(t, err, "previous state is restored")
+	content, err := os.ReadFile(filepath.Join(pwd, "docker-compose.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, current, string(content))
+	require.NoDirExists(t, filepath.Join(pwd, Dir, backupName))
+}
+
+func TestAdminClient(t *testing.T) {
+	t.Setenv("SPANNER_EMULATOR_HOST", "")
+	admin, err := adminClient(context.Background(), "localhost:9010")
+	require.NoError(t, err)
+	require.NoError(t, admin.Close())
+	// the environment of the process (and the other clients) is not changed
+	require.Empty(t, os.Getenv("SPANNER_EMULATOR_HOST"))
+}
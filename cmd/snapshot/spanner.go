@@ -0,0 +1,341 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	database "cloud.google.com/go/spanner/admin/database/apiv1"
+	"cloud.google.com/go/spanner/admin/database/apiv1/databasepb"
+	"cloud.google.com/go/spanner/apiv1/spannerpb"
+	"github.com/zeebo/errs/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// insertBatch is the number of the rows inserted in one transaction.
+const insertBatch = 200
+
+var createTable = regexp.MustCompile("(?is)^\\s*CREATE\\s+TABLE\\s+`?([\\w.]+)`?")
+
+// spannerDatabase is the exported form of a spanner database.
+type spannerDatabase struct {
+	DDL    []string       `json:"ddl"`
+	Tables []spannerTable `json:"tables,omitempty"`
+}
+
+// spannerTable contains the rows of a table. Types and values are stored in the protobuf JSON format.
+type spannerTable struct {
+	Name    string              `json:"name"`
+	Columns []string            `json:"columns"`
+	Types   []json.RawMessage   `json:"types"`
+	Rows    [][]json.RawMessage `json:"rows"`
+}
+
+// spannerInstance returns with the instance path of the emulator, based on the environment of the spanner service.
+func spannerInstance(s runtime.Service) string {
+	project, instance := "test-project", "test-instance"
+	if v := s.GetENV()["PROJECT_ID"]; v != nil && *v != "" {
+		project = *v
+	}
+	if v := s.GetENV()["INSTANCE_NAME"]; v != nil && *v != "" {
+		instance = *v
+	}
+	return fmt.Sprintf("projects/%s/instances/%s", project, instance)
+}
+
+// exportSpanner exports all the databases of the emulator instance to <dir>/<database>.json.
+func exportSpanner(ctx context.Context, address string, instance string, dir string) (names []string, err error) {
+	admin, err := adminClient(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errs.Combine(err, admin.Close()) }()
+
+	databases, err := listDatabases(ctx, admin, instance)
+	if err != nil {
+		return nil, err
+	}
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	for _, name := range databases {
+		exported, err := exportDatabase(ctx, admin, address, instance+"/databases/"+name)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.Marshal(exported)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, name+".json"), raw, 0o644)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func exportDatabase(ctx context.Context, admin *database.DatabaseAdminClient, address string, dbPath string) (_ spannerDatabase, err error) {
+	var exported spannerDatabase
+	ddl, err := admin.GetDatabaseDdl(ctx, &databasepb.GetDatabaseDdlRequest{Database: dbPath})
+	if err != nil {
+		return exported, errs.Errorf("couldn't read the schema of %s: %v", dbPath, err)
+	}
+	exported.DDL = ddl.GetStatements()
+
+	client, err := spanner.NewClient(ctx, dbPath, emulatorOptions(address)...)
+	if err != nil {
+		return exported, errs.Wrap(err)
+	}
+	defer client.Close()
+
+	// all the tables are read from the same snapshot
+	tx := client.ReadOnlyTransaction()
+	defer tx.Close()
+	for _, table := range tableNames(exported.DDL) {
+		t, err := exportTable(ctx, tx, table)
+		if err != nil {
+			return exported, errs.Errorf("couldn't export %s of %s: %v", table, dbPath, err)
+		}
+		if len(t.Rows) > 0 {
+			exported.Tables = append(exported.Tables, t)
+		}
+	}
+	return exported, nil
+}
+
+func exportTable(ctx context.Context, tx *spanner.ReadOnlyTransaction, table string) (spannerTable, error) {
+	t := spannerTable{Name: table}
+	// generated columns can't be inserted
+	err := tx.Query(ctx, spanner.Statement{
+		SQL: "SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = '' AND TABLE_NAME = @table " +
+			"AND IS_GENERATED = 'NEVER' ORDER BY ORDINAL_POSITION",
+		Params: map[string]any{"table": table},
+	}).Do(func(row *spanner.Row) error {
+		var column string
+		if err := row.Column(0, &column); err != nil {
+			return err
+		}
+		t.Columns = append(t.Columns, column)
+		return nil
+	})
+	if err != nil || len(t.Columns) == 0 {
+		return t, err
+	}
+
+	var quoted []string
+	for _, column := range t.Columns {
+		quoted = append(quoted, "`"+column+"`")
+	}
+	err = tx.Query(ctx, spanner.NewStatement("SELECT "+strings.Join(quoted, ", ")+" FROM `"+table+"`")).Do(func(row *spanner.Row) error {
+		values := make([]json.RawMessage, row.Size())
+		var types []json.RawMessage
+		for i := range values {
+			var v spanner.GenericColumnValue
+			if err := row.Column(i, &v); err != nil {
+				return err
+			}
+			raw, err := protojson.Marshal(v.Value)
+			if err != nil {
+				return err
+			}
+			values[i] = raw
+			if t.Types == nil {
+				raw, err := protojson.Marshal(v.Type)
+				if err != nil {
+					return err
+				}
+				types = append(types, raw)
+			}
+		}
+		if t.Types == nil {
+			t.Types = types
+		}
+		t.Rows = append(t.Rows, values)
+		return nil
+	})
+	return t, err
+}
+
+// importSpanner waits until the emulator is available and imports the saved databases. Databases which are not
+// created by the emulator are created.
+func importSpanner(ctx context.Context, address string, instance string, dir string, names []string) (err error) {
+	admin, err := adminClient(ctx, address)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, admin.Close()) }()
+
+	// the emulator image creates the instance and the default databases at startup
+	var existing []string
+	for attempt := 0; attempt < 60; attempt++ {
+		existing, err = listDatabases(ctx, admin, instance)
+		if err == nil && !slices.ContainsFunc(names, func(name string) bool { return !slices.Contains(existing, name) }) {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		raw, err := os.ReadFile(filepath.Join(dir, name+".json"))
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		var saved spannerDatabase
+		err = json.Unmarshal(raw, &saved)
+		if err != nil {
+			return errs.Errorf("couldn't parse the export of %s: %v", name, err)
+		}
+		dbPath := instance + "/databases/" + name
+		if slices.Contains(existing, name) {
+			err = updateSchema(ctx, admin, dbPath, saved.DDL)
+		} else {
+			err = createDatabase(ctx, admin, instance, name, saved.DDL)
+		}
+		if err != nil {
+			return err
+		}
+		err = importTables(ctx, address, dbPath, saved.Tables)
+		if err != nil {
+			return errs.Errorf("couldn't import the data of %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func createDatabase(ctx context.Context, admin *database.DatabaseAdminClient, instance string, name string, ddl []string) error {
+	op, err := admin.CreateDatabase(ctx, &databasepb.CreateDatabaseRequest{
+		Parent:          instance,
+		CreateStatement: "CREATE DATABASE `" + name + "`",
+		ExtraStatements: ddl,
+	})
+	if err != nil {
+		return errs.Errorf("couldn't create %s: %v", name, err)
+	}
+	_, err = op.Wait(ctx)
+	return errs.Wrap(err)
+}
+
+func updateSchema(ctx context.Context, admin *database.DatabaseAdminClient, dbPath string, ddl []string) error {
+	current, err := admin.GetDatabaseDdl(ctx, &databasepb.GetDatabaseDdlRequest{Database: dbPath})
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if len(current.GetStatements()) > 0 {
+		return errs.Errorf("spanner database %s is not empty", dbPath)
+	}
+	if len(ddl) == 0 {
+		return nil
+	}
+	op, err := admin.UpdateDatabaseDdl(ctx, &databasepb.UpdateDatabaseDdlRequest{
+		Database:   dbPath,
+		Statements: ddl,
+	})
+	if err != nil {
+		return errs.Errorf("couldn't create the schema of %s: %v", dbPath, err)
+	}
+	return errs.Wrap(op.Wait(ctx))
+}
+
+// importTables inserts the rows in the order of the tables (parent tables are created first).
+func importTables(ctx context.Context, address string, dbPath string, tables []spannerTable) error {
+	client, err := spanner.NewClient(ctx, dbPath, emulatorOptions(address)...)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer client.Close()
+
+	for _, t := range tables {
+		types := make([]*spannerpb.Type, len(t.Types))
+		for i, raw := range t.Types {
+			types[i] = &spannerpb.Type{}
+			if err := protojson.Unmarshal(raw, types[i]); err != nil {
+				return errs.Wrap(err)
+			}
+		}
+		for start := 0; start < len(t.Rows); start += insertBatch {
+			var mutations []*spanner.Mutation
+			for _, row := range t.Rows[start:min(start+insertBatch, len(t.Rows))] {
+				values := make([]any, len(row))
+				for i, raw := range row {
+					value := &structpb.Value{}
+					if err := protojson.Unmarshal(raw, value); err != nil {
+						return errs.Wrap(err)
+					}
+					values[i] = spanner.GenericColumnValue{Type: types[i], Value: value}
+				}
+				mutations = append(mutations, spanner.Insert(t.Name, t.Columns, values))
+			}
+			if _, err := client.Apply(ctx, mutations); err != nil {
+				return errs.Errorf("%s: %v", t.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// adminClient creates a database admin client for the emulator.
+func adminClient(ctx context.Context, address string) (*database.DatabaseAdminClient, error) {
+	admin, err := database.NewDatabaseAdminClient(ctx, emulatorOptions(address)...)
+	return admin, errs.Wrap(err)
+}
+
+// emulatorOptions are the client options of the emulator: insecure connection without authentication.
+func emulatorOptions(address string) []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(address),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())),
+	}
+}
+
+// listDatabases returns with the short names of the databases of an instance.
+func listDatabases(ctx context.Context, admin *database.DatabaseAdminClient, instance string) ([]string, error) {
+	var names []string
+	it := admin.ListDatabases(ctx, &databasepb.ListDatabasesRequest{Parent: instance})
+	for {
+		db, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return names, nil
+		}
+		if err != nil {
+			return nil, errs.Errorf("couldn't list the databases of %s: %v", instance, err)
+		}
+		names = append(names, path.Base(db.GetName()))
+	}
+}
+
+// tableNames returns with the created tables, in the order of the schema statements.
+func tableNames(ddl []string) []string {
+	var tables []string
+	for _, statement := range ddl {
+		if match := createTable.FindStringSubmatch(statement); match != nil {
+			tables = append(tables, match[1])
+		}
+	}
+	return tables
+}
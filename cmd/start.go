@@ -4,29 +4,43 @@
 package cmd
 
 import (
-	"bufio"
-	"errors"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	osruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs/v2"
 	"golang.org/x/exp/slices"
 
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+	"storj.io/storj-up/pkg/gobuild"
 	"storj.io/storj-up/pkg/recipe"
 	"storj.io/storj-up/pkg/runtime/runtime"
 )
 
+// defaultBuildParallelism is the default number of concurrent builds of the local binaries.
+const defaultBuildParallelism = 4
+
 func startCmd() *cobra.Command {
-	return &cobra.Command{
+	var parallel int
+	cmd := &cobra.Command{
 		Use:   "start",
 		Args:  cobra.NoArgs,
 		Short: "build and start all services",
+		Long: "Builds the local binaries of the services (see STORJ_UP_LOCAL_BINARY_SOURCE) for the platform of the docker " +
+			"daemon, and starts the services. Binaries are built in parallel, and skipped if their sources are not changed " +
+			"since the last successful build. cgo is enabled for cross-compilation only if a dependency requires it, which " +
+			"can be overridden with STORJ_UP_LOCAL_BINARY_CGO=on|off|auto.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if DryRun {
+				return errs.Errorf("--dry-run is not supported by start: it builds and starts the services, there are no changes to print")
+			}
 			pwd, err := os.Getwd()
 			if err != nil {
 				return errs.Wrap(err)
@@ -44,92 +58,202 @@ func startCmd() *cobra.Command {
 			if err != nil {
 				return errs.Wrap(err)
 			}
-			err = buildServices(runtime.GetServices())
+			err = buildServices(cmd.Context(), pwd, runtime.GetServices(), parallel)
 			if err != nil {
 				return errs.Wrap(err)
 			}
-			err = startServices()
+			err = startServices(cmd.Context(), pwd)
 			if err != nil {
 				return errs.Wrap(err)
 			}
 			return nil
 		},
 	}
+	cmd.Flags().IntVar(&parallel, "parallel", defaultBuildParallelism, "maximum number of concurrent builds")
+	return cmd
 }
 
 func init() {
 	RootCmd.AddCommand(startCmd())
 }
 
-func buildServices(services []runtime.Service) error {
-	var buildpaths []string
+// buildServices installs the local binaries of the containers, which have the local go/bin directory mounted.
+func buildServices(ctx context.Context, dir string, services []runtime.Service, parallel int) error {
+	var builds []gobuild.Build
+	mounts := map[gobuild.Build][]string{}
+	var platform gobuild.Platform
 	for _, service := range services {
-		for _, mount := range service.GetVolumes() {
-			if mount.MountType == "bind" && filepath.Dir(mount.Target) == filepath.Clean("/var/lib/storj/go/bin") {
-				var codeSource string
-				var foundCodeSource bool
-				serviceCodeSource, foundCodeSource := service.GetENV()["STORJ_UP_LOCAL_BINARY_SOURCE"]
-				if foundCodeSource {
-					codeSource = *serviceCodeSource
-				} else {
-					codeSource, foundCodeSource = os.LookupEnv("STORJ_UP_LOCAL_" + service.ID().Name)
-				}
-				if foundCodeSource && len(codeSource) > 0 && !slices.Contains(buildpaths, codeSource) {
-					buildpaths = append(buildpaths, codeSource)
-					var cmd *exec.Cmd
-					if osruntime.GOOS != "linux" {
-						cmd = exec.Command("go", "install")
-						cmd.Env = os.Environ()
-						cmd.Env = append(cmd.Env, "GOOS=linux")
-						cmd.Env = append(cmd.Env, "GOARCH=amd64")
-						if strings.Contains(strings.ToLower(service.ID().Name), "storagenode") {
-							cmd.Env = append(cmd.Env, "CGO_ENABLED=1")
-						}
-					} else {
-						cmd = exec.Command("go", "install")
-					}
-					if cmd == nil {
-						return errors.New("unable to run go build command")
-					}
-
-					err := runCommand(cmd, filepath.Clean(codeSource))
-					if err != nil {
-						return err
-					}
-				}
-			}
+		mount, found := localBinaryMount(service)
+		if !found {
+			continue
+		}
+		if platform == (gobuild.Platform{}) {
+			platform = containerPlatform(ctx)
+		}
+		build, found, err := localBinary(service, platform)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if !slices.Contains(builds, build) {
+			builds = append(builds, build)
 		}
+		mounts[build] = append(mounts[build], mount)
 	}
-	return nil
+	_, err := installBinaries(ctx, dir, builds, mounts, parallel)
+	return err
+}
+
+// localBinarySource returns with the directory of the Go main package of the service, configured with the
+// STORJ_UP_LOCAL_BINARY_SOURCE environment variable of the service, or with STORJ_UP_LOCAL_<service> of the OS.
+func localBinarySource(service runtime.Service) (string, bool) {
+	if source, found := service.GetENV()["STORJ_UP_LOCAL_BINARY_SOURCE"]; found && source != nil && *source != "" {
+		return *source, true
+	}
+	source, found := os.LookupEnv("STORJ_UP_LOCAL_" + service.ID().Name)
+	return source, found && source != ""
 }
 
-func startServices() error {
-	cmd := exec.Command("docker", "compose", "up", "-d")
-	curDir, err := os.Getwd()
+// localBinary returns with the build of the local binary of the service for the platform. The cgo strategy is
+// configured with STORJ_UP_LOCAL_BINARY_CGO (of the service, or of the OS).
+func localBinary(service runtime.Service, platform gobuild.Platform) (gobuild.Build, bool, error) {
+	source, found := localBinarySource(service)
+	if !found {
+		return gobuild.Build{}, false, nil
+	}
+	source, err := filepath.Abs(source)
 	if err != nil {
-		return err
+		return gobuild.Build{}, false, errs.Wrap(err)
+	}
+	cgo := os.Getenv("STORJ_UP_LOCAL_BINARY_CGO")
+	if v := service.GetENV()["STORJ_UP_LOCAL_BINARY_CGO"]; v != nil && *v != "" {
+		cgo = *v
 	}
-	return runCommand(cmd, curDir)
+	switch cgo {
+	case "":
+		cgo = gobuild.CGOAuto
+	case gobuild.CGOAuto, gobuild.CGOOn, gobuild.CGOOff:
+	default:
+		return gobuild.Build{}, false, errs.Errorf("invalid STORJ_UP_LOCAL_BINARY_CGO of %s: %s (auto, on or off)", service.ID().Name, cgo)
+	}
+	return gobuild.Build{Source: source, Platform: platform, CGO: cgo}, true, nil
 }
 
-func runCommand(cmd *exec.Cmd, runPath string) error {
-	stderr, _ := cmd.StderrPipe()
-	fmt.Println("*** Storj-Up Running " + strings.Join(cmd.Args, " ") + " from " + runPath + " ***")
-	cmd.Dir = runPath
-	err := cmd.Start()
+// localBinaryMount returns with the host path of the binary, if the local go/bin directory is mounted to the
+// container of the service (see `storj-up local-bin`).
+func localBinaryMount(service runtime.Service) (string, bool) {
+	for _, mount := range service.GetVolumes() {
+		if mount.MountType == "bind" && filepath.Dir(mount.Target) == filepath.Clean("/var/lib/storj/go/bin") {
+			return mount.Source, true
+		}
+	}
+	return "", false
+}
+
+// containerPlatform returns with the platform of the docker daemon. If it's not available, linux with the
+// architecture of the host is used.
+func containerPlatform(ctx context.Context) gobuild.Platform {
+	if client, err := docker.Available(ctx); err == nil {
+		if version, err := client.Version(ctx); err == nil && version.Os != "" && version.Arch != "" {
+			return gobuild.Platform{OS: version.Os, Arch: version.Arch}
+		}
+	}
+	out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Os}}/{{.Server.Arch}}").Output()
+	if err == nil {
+		if os, arch, found := strings.Cut(strings.TrimSpace(string(out)), "/"); found && os != "" && arch != "" {
+			return gobuild.Platform{OS: os, Arch: arch}
+		}
+	}
+	return gobuild.Platform{OS: "linux", Arch: osruntime.GOARCH}
+}
+
+// installBinaries installs the binaries in parallel, and prints the results. mounts are the mounted paths of the
+// binaries, which are compared to the installed binary.
+func installBinaries(ctx context.Context, dir string, builds []gobuild.Build, mounts map[gobuild.Build][]string, parallel int) ([]gobuild.Result, error) {
+	if len(builds) == 0 {
+		return nil, nil
+	}
+	builder := &gobuild.Builder{
+		Parallel:  parallel,
+		CacheFile: filepath.Join(dir, gobuild.CacheFileName),
+	}
+	return builder.Install(ctx, builds, func(result gobuild.Result) {
+		switch {
+		case result.Err != nil:
+			fmt.Printf("*** Storj-Up build of %s failed ***\n%v\n", result.Build, result.Err)
+			return
+		case result.Skipped:
+			fmt.Printf("*** Storj-Up %s is up to date: %s ***\n", result.Build, result.Target)
+		default:
+			fmt.Printf("*** Storj-Up built %s (cgo: %t) in %s: %s ***\n", result.Build, result.CGO, result.Duration.Round(time.Millisecond), result.Target)
+		}
+		for _, mount := range mounts[result.Build] {
+			if filepath.Clean(mount) != result.Target {
+				fmt.Printf("*** Storj-Up warning: %s is mounted to the container, but the binary is installed to %s "+
+					"(use `storj-up local-bin -d %s`) ***\n", mount, result.Target, filepath.Dir(result.Target))
+			}
+		}
+	})
+}
+
+// startServices creates and starts the containers of the compose file in the directory, with the Docker Engine API.
+// `docker compose up -d` is used, if the API is not available.
+func startServices(ctx context.Context, dir string) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		fmt.Printf("*** Storj-Up using the docker CLI (%v) ***\n", err)
+		return runCommand(exec.CommandContext(ctx, "docker", "compose", "up", "-d"), dir)
+	}
+	project, err := docker.LoadProject(dir, common.ComposeFileName)
 	if err != nil {
 		return err
 	}
+	err = client.Up(ctx, project, func(service string, m docker.Message) {
+		fmt.Printf("%-25s %s\n", service, m)
+	})
+	if err != nil {
+		return err
+	}
+	states, err := client.Status(ctx, project.Name)
+	if err != nil {
+		return err
+	}
+	printContainerStates(states)
+	for _, state := range states {
+		if state.State == "exited" && state.ExitCode != 0 || state.State == "dead" {
+			return errs.Errorf("container %s is %s", state.Name, state)
+		}
+	}
+	return nil
+}
 
-	scanner := bufio.NewScanner(stderr)
-	for scanner.Scan() {
-		m := scanner.Text()
-		fmt.Println(m)
+// RecreateServices recreates and starts the containers of compose services (without their dependencies), with the
+// Docker Engine API. `docker compose up -d --no-deps --force-recreate` is used, if the API is not available.
+func RecreateServices(ctx context.Context, dir string, services []string) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		args := append([]string{"compose", "up", "-d", "--no-deps", "--force-recreate"}, services...)
+		return runCommand(exec.CommandContext(ctx, "docker", args...), dir)
 	}
-	err = cmd.Wait()
+	project, err := docker.LoadProject(dir, common.ComposeFileName)
 	if err != nil {
 		return err
 	}
+	return client.Recreate(ctx, project, services, func(service string, m docker.Message) {
+		fmt.Printf("%-25s %s\n", service, m)
+	})
+}
 
+func runCommand(cmd *exec.Cmd, runPath string) error {
+	fmt.Println("*** Storj-Up Running " + strings.Join(cmd.Args, " ") + " from " + runPath + " ***")
+	cmd.Dir = runPath
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return errs.Errorf("couldn't execute %s: %v", strings.Join(cmd.Args, " "), err)
+	}
 	return nil
 }
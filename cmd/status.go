@@ -0,0 +1,100 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+)
+
+func statusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Args:  cobra.NoArgs,
+		Short: "print the state, health and exit code of the containers of the compose environment",
+		Long: "Prints the state of the containers with the Docker Engine API. `docker compose ps --all` is used, if the API " +
+			"is not available or " + docker.CLIEnv + " is set.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			pwd, err := ProjectDir()
+			if err != nil {
+				return err
+			}
+			client, err := docker.Available(cmd.Context())
+			if err != nil {
+				return runCommand(exec.CommandContext(cmd.Context(), "docker", "compose", "ps", "--all"), pwd)
+			}
+			name, err := docker.ProjectName(pwd, common.ComposeFileName)
+			if err != nil {
+				return err
+			}
+			states, err := client.Status(cmd.Context(), name)
+			if err != nil {
+				return err
+			}
+			if len(states) == 0 {
+				fmt.Println("No containers are created (use `storj-up start`).")
+				return nil
+			}
+			printContainerStates(states)
+			return nil
+		},
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd())
+}
+
+// WaitHealthy waits until the containers of the compose services are running and healthy (if they have health
+// checks). It returns with an error if any of them is failed, or is not ready within the timeout.
+func WaitHealthy(ctx context.Context, dir string, services []string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	status := func(ctx context.Context) ([]docker.ServiceState, error) {
+		out, err := exec.CommandContext(ctx, "docker", "compose", "--project-directory", dir, "ps", "--all", "--format", "json").Output()
+		if err != nil {
+			return nil, errs.Errorf("couldn't get the state of the containers: %v", err)
+		}
+		return docker.ParseComposePS(out)
+	}
+	if client, err := docker.Available(ctx); err == nil {
+		name, err := docker.ProjectName(dir, common.ComposeFileName)
+		if err != nil {
+			return err
+		}
+		status = func(ctx context.Context) ([]docker.ServiceState, error) {
+			return client.Status(ctx, name)
+		}
+	}
+	return docker.WaitReady(ctx, status, services, 2*time.Second)
+}
+
+func printContainerStates(states []docker.ServiceState) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SERVICE\tCONTAINER\tSTATE\tHEALTH\tEXIT CODE")
+	for _, s := range states {
+		health, exitCode := s.Health, "-"
+		if health == "" {
+			health = "-"
+		}
+		if s.State == "exited" || s.State == "dead" {
+			exitCode = fmt.Sprint(s.ExitCode)
+		}
+		if s.Error != "" {
+			exitCode += " (" + s.Error + ")"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Service, s.Name, s.State, health, exitCode)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/release"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+func upgradeCmd() *cobra.Command {
+	var to, manifest string
+	cmd := &cobra.Command{
+		Use:   "upgrade --to <release>",
+		Args:  cobra.NoArgs,
+		Short: "set the images of the services to the known-compatible image set of a release",
+		Long: "Sets the images of the services (storj, edge, spanner-emulator, ...) to the image set of a release, defined by " +
+			"the release manifest. Services with other images (like the images built from source) are not changed. The " +
+			"changed services are reported, and a warning is printed if database migrations would be skipped. The " +
+			"change can be reverted with `storj-up undo`.",
+		RunE: ExecuteStorjUP(func(st recipe.Stack, rt runtime.Runtime, _ []string) error {
+			m, err := release.Load(manifest)
+			if err != nil {
+				return err
+			}
+			target, err := m.Get(to)
+			if err != nil {
+				return err
+			}
+			current, found := m.Detect(images(rt))
+			changes, err := target.Apply(rt)
+			if err != nil {
+				return err
+			}
+			printReleaseChanges(os.Stdout, target, changes)
+			if !found {
+				fmt.Println("WARNING: the current release is unknown, the database migrations are not checked")
+			} else if warning := release.MigrationWarning(current, target); warning != "" {
+				fmt.Println("WARNING: " + warning)
+			}
+			return nil
+		}),
+	}
+	cmd.Flags().StringVar(&to, "to", "", "the release to upgrade to (like v1.125, or a release of --manifest)")
+	cmd.Flags().StringVar(&manifest, "manifest", "", "release manifest file, which extends the embedded one")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func init() {
+	RootCmd.AddCommand(upgradeCmd())
+}
+
+// images returns with the images of the services.
+func images(rt runtime.Runtime) []string {
+	var res []string
+	for _, s := range rt.GetServices() {
+		_ = s.ChangeImage(func(image string) string {
+			res = append(res, image)
+			return image
+		})
+	}
+	return res
+}
+
+func printReleaseChanges(w io.Writer, r release.Release, changes []release.Change) {
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(w, "all the services already use the images of %s\n", r.Name)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%d service(s) changed to the images of %s:\n", len(changes), r.Name)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range changes {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\t-> %s\n", c.Service, c.From, c.To)
+	}
+	_ = tw.Flush()
+}
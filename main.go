@@ -6,9 +6,11 @@ package main
 import (
 	"storj.io/storj-up/cmd"
 	_ "storj.io/storj-up/cmd/build"
+	_ "storj.io/storj-up/cmd/bundle"
 	_ "storj.io/storj-up/cmd/container"
 	_ "storj.io/storj-up/cmd/history"
 	_ "storj.io/storj-up/cmd/modify"
+	_ "storj.io/storj-up/cmd/snapshot"
 )
 
 func main() {
@@ -0,0 +1,40 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package atomicfile writes files via a temporary file and rename, so readers (and crashes) never see half-written files.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// WriteFile writes data to the named file, like os.WriteFile. The data is written to a temporary file in the same
+// directory first, which is renamed to the final name when it's fully written.
+func WriteFile(name string, data []byte, perm os.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = f.Sync(); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = f.Chmod(perm); err != nil {
+		return errs.Wrap(err)
+	}
+	if err = f.Close(); err != nil {
+		return errs.Wrap(err)
+	}
+	return errs.Wrap(os.Rename(f.Name(), name))
+}
@@ -0,0 +1,31 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "docker-compose.yaml")
+
+	require.NoError(t, WriteFile(name, []byte("first"), 0644))
+	require.NoError(t, WriteFile(name, []byte("second"), 0755))
+
+	content, err := os.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(content))
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.Error(t, WriteFile(filepath.Join(dir, "missing", "file"), []byte("x"), 0644))
+}
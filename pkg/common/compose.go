@@ -7,6 +7,8 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/compose-spec/compose-go/v2/cli"
 	"github.com/compose-spec/compose-go/v2/loader"
@@ -14,6 +16,7 @@ import (
 	"github.com/goccy/go-yaml"
 	"github.com/zeebo/errs/v2"
 
+	"storj.io/storj-up/pkg/common/atomicfile"
 	"storj.io/storj-up/pkg/common/composedb"
 )
 
@@ -71,26 +74,21 @@ func CreateBind(source string, target string) types.ServiceVolumeConfig {
 }
 
 // WriteComposeFile persists current docker-compose project to docker-compose.yaml.
+// The previous version is saved to the history before the file is replaced.
 func WriteComposeFile(dir string, compose *types.Project) error {
 	prevCompose, _ := LoadComposeFromFile(dir, ComposeFileName)
-	err := WriteComposeFileNoHistory(dir, compose)
-	if err != nil {
-		return err
-	}
-	if os.Getenv("STORJUP_NO_HISTORY") != "" {
-		return nil
-	}
-	if prevCompose != nil {
-		prevComposeBytes, err := yaml.Marshal(prevCompose)
+	if prevCompose != nil && os.Getenv("STORJUP_NO_HISTORY") == "" {
+		// the original file is saved, to keep the parts which are not loaded to the project
+		prevComposeBytes, err := os.ReadFile(filepath.Join(dir, ComposeFileName))
 		if err != nil {
-			return err
+			return errs.Wrap(err)
 		}
 		_, err = Store.SaveCurrentVersion(prevComposeBytes)
 		if err != nil {
 			return err
 		}
 	}
-	return nil
+	return WriteComposeFileNoHistory(dir, compose)
 }
 
 // WriteComposeFileNoHistory persists current docker-compose project to docker-compose.yaml without saving a record
@@ -100,8 +98,144 @@ func WriteComposeFileNoHistory(dir string, compose *types.Project) error {
 	if err != nil {
 		return errs.Wrap(err)
 	}
-	if err = os.WriteFile(filepath.Join(dir, ComposeFileName), resolvedServices, 0o644); err != nil {
+	existing, err := os.ReadFile(filepath.Join(dir, ComposeFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return errs.Wrap(err)
+	}
+	var previous *types.Project
+	if len(existing) > 0 {
+		previous, _ = LoadComposeFromFile(dir, ComposeFileName)
+	}
+	resolvedServices, err = mergeCompose(resolvedServices, existing, previous, compose)
+	if err != nil {
 		return err
 	}
-	return nil
+	return atomicfile.WriteFile(filepath.Join(dir, ComposeFileName), resolvedServices, 0o644)
+}
+
+// managedKeys are the top-level keys of the compose file generated by storj-up. (Named volumes of the services
+// are also added to the top-level volumes.)
+var managedKeys = []string{"name", "services", "networks"}
+
+// VolumeExtension marks the top-level volumes declared by storj-up. They are removed when no service uses them.
+const VolumeExtension = "x-storj-up"
+
+// mergeCompose adds the content which is not managed by storj-up to the generated compose file. If the previous
+// version of the file could be loaded, the file is spliced (see spliceCompose): the unchanged services and keys keep
+// their original text. Otherwise, the other top-level keys of the existing file (volumes, configs, secrets, x-*
+// extensions, ...) and the x-* extensions of the project are added to the generated file.
+func mergeCompose(generated []byte, existing []byte, previous *types.Project, compose *types.Project) ([]byte, error) {
+	if len(existing) > 0 && previous != nil {
+		merged, ok, err := spliceCompose(generated, existing, previous, compose)
+		if err != nil || ok {
+			return merged, err
+		}
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(generated, &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	changed := false
+
+	if len(existing) > 0 {
+		var previous yaml.MapSlice
+		// an unparsable file is replaced, same as without merge
+		if err := yaml.UnmarshalWithOptions(existing, &previous, yaml.UseOrderedMap()); err == nil {
+			for _, item := range previous {
+				if key, _ := item.Key.(string); !slices.Contains(managedKeys, key) {
+					doc = append(doc, item)
+					changed = true
+				}
+			}
+		}
+	}
+	ix := slices.IndexFunc(doc, func(item yaml.MapItem) bool {
+		return item.Key == "volumes"
+	})
+	var volumes yaml.MapSlice
+	if ix >= 0 {
+		volumes, _ = doc[ix].Value.(yaml.MapSlice)
+	}
+	if declared := declareVolumes(volumes, compose); !equal(declared, volumes) {
+		switch {
+		case ix >= 0 && len(declared) == 0:
+			doc = slices.Delete(doc, ix, ix+1)
+		case ix >= 0:
+			doc[ix].Value = declared
+		default:
+			doc = append(doc, yaml.MapItem{Key: "volumes", Value: declared})
+		}
+		changed = true
+	}
+	for _, item := range extensionItems(compose.Extensions) {
+		if !hasKey(doc, item.Key) {
+			doc = append(doc, item)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return generated, nil
+	}
+	return yaml.Marshal(doc)
+}
+
+// declareVolumes returns with the top-level volumes: the named volumes used by the services are added (marked with
+// VolumeExtension), and the unused ones declared by storj-up are removed.
+func declareVolumes(volumes yaml.MapSlice, compose *types.Project) yaml.MapSlice {
+	used := usedVolumes(compose)
+	var declared yaml.MapSlice
+	for _, item := range volumes {
+		config, _ := item.Value.(yaml.MapSlice)
+		if name, _ := item.Key.(string); slices.Contains(used, name) || value(config, VolumeExtension) != true {
+			declared = append(declared, item)
+		}
+	}
+	for _, name := range used {
+		if !hasKey(declared, name) {
+			declared = append(declared, yaml.MapItem{Key: name, Value: declaredVolume()})
+		}
+	}
+	return declared
+}
+
+// usedVolumes returns with the named volumes of the services, ordered by name.
+func usedVolumes(compose *types.Project) []string {
+	var names []string
+	for _, service := range compose.Services {
+		for _, v := range service.Volumes {
+			if v.Type == types.VolumeTypeVolume && v.Source != "" && !slices.Contains(names, v.Source) {
+				names = append(names, v.Source)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func declaredVolume() yaml.MapSlice {
+	return yaml.MapSlice{{Key: VolumeExtension, Value: true}}
+}
+
+// extensionItems returns with the x-* extensions, ordered by name.
+func extensionItems(extensions types.Extensions) (items yaml.MapSlice) {
+	for key, value := range extensions {
+		if strings.HasPrefix(key, "x-") {
+			items = append(items, yaml.MapItem{Key: key, Value: value})
+		}
+	}
+	slices.SortFunc(items, func(a, b yaml.MapItem) int {
+		return strings.Compare(a.Key.(string), b.Key.(string))
+	})
+	return items
+}
+
+func hasKey(doc yaml.MapSlice, key any) bool {
+	for _, item := range doc {
+		if item.Key == key {
+			return true
+		}
+	}
+	return false
 }
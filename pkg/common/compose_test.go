@@ -4,8 +4,11 @@
 package common
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +20,115 @@ func Test_ResolveService(t *testing.T) {
 		expected,
 		services)
 }
+
+func TestWriteComposeFilePreservesUnknownContent(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ComposeFileName), []byte(`name: storj-up
+services:
+  satellite-api:
+    image: img.dev.storj.io/storjup/storj
+    x-owner: me
+volumes:
+  data: {}
+secrets:
+  token:
+    file: ./token.txt
+x-common:
+  restart: always
+`), 0644))
+
+	project, err := LoadComposeFromFile(dir, ComposeFileName)
+	require.NoError(t, err)
+	service := project.Services["satellite-api"]
+	service.Image = "storj"
+	project.Services["satellite-api"] = service
+	require.NoError(t, WriteComposeFileNoHistory(dir, project))
+
+	raw, err := os.ReadFile(filepath.Join(dir, ComposeFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "image: storj\n")
+	require.Contains(t, string(raw), "x-owner: me")
+	require.Contains(t, string(raw), "volumes:\n  data: {}")
+	require.Contains(t, string(raw), "file: ./token.txt")
+	require.Contains(t, string(raw), "x-common:\n  restart: always")
+
+	reloaded, err := LoadComposeFromFile(dir, ComposeFileName)
+	require.NoError(t, err)
+	require.Contains(t, reloaded.Volumes, "data")
+	require.Contains(t, reloaded.Secrets, "token")
+	require.Contains(t, reloaded.Extensions, "x-common")
+}
+
+func TestWriteComposeFileKeepsInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ComposeFileName), []byte(`name: storj-up
+x-common: &common
+  restart: always
+services:
+  # the API
+  satellite-api:
+    <<: *common
+    image: ${SATELLITE_IMAGE:-img.dev.storj.io/storjup/storj:1.125.2}
+    environment:
+      STORJ_LOG_LEVEL: ${LOG_LEVEL:-info}
+    volumes:
+      - ./identities:/var/lib/storj/identities
+  storagenode:
+    image: img.dev.storj.io/storjup/storj:1.125.2
+volumes:
+  data: {}
+`), 0644))
+
+	update := func(name string, change func(s *types.ServiceConfig)) string {
+		project, err := LoadComposeFromFile(dir, ComposeFileName)
+		require.NoError(t, err)
+		service := project.Services[name]
+		change(&service)
+		project.Services[name] = service
+		require.NoError(t, WriteComposeFileNoHistory(dir, project))
+		raw, err := os.ReadFile(filepath.Join(dir, ComposeFileName))
+		require.NoError(t, err)
+		return string(raw)
+	}
+
+	// unchanged services are kept as is
+	raw := update("storagenode", func(s *types.ServiceConfig) {
+		s.Environment = types.NewMappingWithEquals([]string{"STORJ_LOG_LEVEL=debug"})
+	})
+	require.Contains(t, raw, "x-common: &common\n  restart: always\n")
+	require.Contains(t, raw, `  # the API
+  satellite-api:
+    <<: *common
+    image: ${SATELLITE_IMAGE:-img.dev.storj.io/storjup/storj:1.125.2}
+    environment:
+      STORJ_LOG_LEVEL: ${LOG_LEVEL:-info}
+    volumes:
+      - ./identities:/var/lib/storj/identities
+`)
+	require.Contains(t, raw, "STORJ_LOG_LEVEL: debug")
+
+	// only the changed keys are replaced
+	raw = update("satellite-api", func(s *types.ServiceConfig) {
+		s.Environment["STORJ_DEBUG_ADDR"] = &[]string{"0.0.0.0:11111"}[0]
+	})
+	require.Contains(t, raw, "    <<: *common\n    image: ${SATELLITE_IMAGE:-img.dev.storj.io/storjup/storj:1.125.2}\n")
+	require.Contains(t, raw, "    volumes:\n      - ./identities:/var/lib/storj/identities\n")
+	require.Contains(t, raw, "STORJ_DEBUG_ADDR: 0.0.0.0:11111")
+	require.NotContains(t, raw, "restart: always\n    ")
+
+	project, err := LoadComposeFromFile(dir, ComposeFileName)
+	require.NoError(t, err)
+	require.Equal(t, "img.dev.storj.io/storjup/storj:1.125.2", project.Services["satellite-api"].Image)
+	require.Equal(t, "always", project.Services["satellite-api"].Restart)
+
+	// named volumes are declared while they are used, hand-written ones are kept
+	raw = update("storagenode", func(s *types.ServiceConfig) {
+		s.Volumes = append(s.Volumes, types.ServiceVolumeConfig{Type: types.VolumeTypeVolume, Source: "storagenode-data", Target: "/var/lib/storj"})
+	})
+	require.Contains(t, raw, "volumes:\n  data: {}\n  storagenode-data:\n    x-storj-up: true\n")
+	raw = update("storagenode", func(s *types.ServiceConfig) {
+		s.Volumes = nil
+	})
+	require.NotContains(t, raw, "storagenode-data")
+	require.Contains(t, raw, "volumes:\n  data: {}\n")
+}
@@ -6,10 +6,16 @@ package composedb
 import (
 	"os"
 	"path/filepath"
+	"strings"
+
+	"storj.io/storj-up/pkg/common/atomicfile"
 )
 
+// HistoryDir is the directory of the stored versions, relative to the current directory.
+const HistoryDir = ".history"
+
 const (
-	composeHistoryRelativePath = "./.history"
+	composeHistoryRelativePath = "./" + HistoryDir
 	composeHistoryFileEXT      = ".yaml"
 )
 
@@ -26,7 +32,7 @@ func (db FileDatabase) Write(filename string, data []byte) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(path, filename)+composeHistoryFileEXT, data, 0o644)
+	return atomicfile.WriteFile(filepath.Join(path, filename)+composeHistoryFileEXT, data, 0o644)
 }
 
 // Read implements the Reader interface for a flat filesystem database.
@@ -73,6 +79,10 @@ func (db FileDatabase) GetObjectVersions() ([]Version, error) {
 	versions := make([]Version, 0, len(files))
 	for _, file := range files {
 		filename := file.Name()
+		// temporary files of interrupted writes
+		if strings.HasPrefix(filename, ".") {
+			continue
+		}
 		info, err := file.Info()
 		if err != nil {
 			return nil, err
@@ -48,10 +48,18 @@ func (s ComposeHistory) RestoreLatestVersion() ([]byte, error) {
 	return bytes, nil
 }
 
-// RestoreVersion  returns the requested version of the saved compose history.
+// RestoreVersion returns the requested version of the saved compose history. The version is kept in the history.
 func (s ComposeHistory) RestoreVersion(version string) ([]byte, error) {
-	// todo add functionality for selective file history restore
-	return nil, fmt.Errorf("Unsupported")
+	objectNames, err := getObjectNamesSortedByLatest(s.DB)
+	if err != nil {
+		return nil, err
+	}
+	for _, objectName := range objectNames {
+		if objectName.ID == version {
+			return s.DB.Read(version)
+		}
+	}
+	return nil, fmt.Errorf("version %s is not found in the history", version)
 }
 
 // ListVersions  lists the latest stored versions of the compose history files.
@@ -83,6 +91,21 @@ func (s ComposeHistory) SaveCurrentVersion(bytes []byte) (string, error) {
 	return objectName, nil
 }
 
+// Clear deletes all the stored versions.
+func (s ComposeHistory) Clear() error {
+	objectNames, err := s.DB.GetObjectVersions()
+	if err != nil {
+		return err
+	}
+	for _, objectName := range objectNames {
+		err = s.DB.Delete(objectName.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func empty(db Database) bool {
 	objectNames, _ := db.GetObjectVersions()
 	return len(objectNames) == 0
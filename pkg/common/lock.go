@@ -0,0 +1,30 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// LockFileName is the name of the file used to serialize the modifications of one environment.
+const LockFileName = ".storj-up.lock"
+
+// LockProject acquires an exclusive lock of the environment in the directory. It waits until the other storj-up
+// processes release the lock. The returned function releases the lock.
+func LockProject(dir string) (unlock func() error, err error) {
+	f, err := os.OpenFile(filepath.Join(dir, LockFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, errs.Errorf("couldn't lock %s: %v", f.Name(), err)
+	}
+	return func() error {
+		return errs.Combine(errs.Wrap(unlockFile(f)), errs.Wrap(f.Close()))
+	}, nil
+}
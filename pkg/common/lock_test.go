@@ -0,0 +1,42 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockProject(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockProject(dir)
+	require.NoError(t, err)
+
+	locked := make(chan func() error)
+	go func() {
+		second, err := LockProject(dir)
+		if err != nil {
+			close(locked)
+			return
+		}
+		locked <- second
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock is acquired twice")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, unlock())
+	select {
+	case second, ok := <-locked:
+		require.True(t, ok)
+		require.NoError(t, second())
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock is not released")
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package common
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/zeebo/errs/v2"
+)
+
+// block is a key of a block style mapping in the source file, with the lines of the key and the value (and the
+// comments until the next key).
+type block struct {
+	key   string
+	node  *ast.MappingValueNode
+	start int
+	end   int
+}
+
+// splice is a rewrite of an existing compose file, which keeps the original text of the parts which are not
+// changed by storj-up (interpolated variables, anchors, merge keys, relative paths, comments, ...).
+type splice struct {
+	lines []string
+	out   strings.Builder
+}
+
+// spliceCompose merges the generated compose file to the text of the existing one. Services (and the keys of the
+// services) are replaced only if they are changed compared to the previous version (the project loaded from the
+// existing file), unknown keys are kept. Returns false, if the existing file is not a block style mapping.
+func spliceCompose(generated []byte, existing []byte, previous *types.Project, compose *types.Project) ([]byte, bool, error) {
+	file, err := parser.ParseBytes(existing, parser.ParseComments)
+	if err != nil || len(file.Docs) != 1 {
+		return nil, false, nil
+	}
+	s := &splice{lines: strings.SplitAfter(strings.TrimSuffix(string(existing), "\n"), "\n")}
+	top, ok := s.blocks(file.Docs[0].Body, len(s.lines))
+	if !ok || len(top) == 0 {
+		return nil, false, nil
+	}
+
+	var current, before yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions(generated, &current, yaml.UseOrderedMap()); err != nil {
+		return nil, false, errs.Wrap(err)
+	}
+	raw, err := yaml.Marshal(&ComposeFile{Name: previous.Name, Services: previous.Services, Networks: previous.Networks})
+	if err != nil {
+		return nil, false, errs.Wrap(err)
+	}
+	if err := yaml.UnmarshalWithOptions(raw, &before, yaml.UseOrderedMap()); err != nil {
+		return nil, false, errs.Wrap(err)
+	}
+
+	s.keep(0, top[0].start)
+	for _, b := range top {
+		switch b.key {
+		case "services":
+			err = s.services(b, value(before, "services"), value(current, "services"))
+		case "volumes":
+			err = s.volumes(b, compose)
+		default:
+			err = s.item(b, before, current, 0)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	for _, item := range current {
+		if !found(top, item.Key) {
+			err = s.render(item.Key, item.Value, 0)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if !found(top, "volumes") {
+		if volumes := declareVolumes(nil, compose); len(volumes) > 0 {
+			err = s.render("volumes", volumes, 0)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	for _, item := range extensionItems(compose.Extensions) {
+		if !found(top, item.Key) {
+			err = s.render(item.Key, item.Value, 0)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return []byte(s.out.String()), true, nil
+}
+
+// services merges the services: removed services are deleted, new services are added to the end, and the changed
+// services are merged key by key.
+func (s *splice) services(b block, before any, current any) error {
+	beforeServices, _ := before.(yaml.MapSlice)
+	currentServices, _ := current.(yaml.MapSlice)
+	children, ok := s.blocks(b.node.Value, b.end)
+	if !ok || len(children) == 0 {
+		return s.render(b.key, current, 0)
+	}
+	s.keep(b.start, children[0].start)
+	indent := column(children[0])
+	for _, child := range children {
+		if !hasKey(currentServices, child.key) {
+			continue
+		}
+		beforeService, _ := value(beforeServices, child.key).(yaml.MapSlice)
+		currentService, _ := value(currentServices, child.key).(yaml.MapSlice)
+		if beforeService != nil && equal(beforeService, currentService) {
+			s.keep(child.start, child.end)
+			continue
+		}
+		keys, ok := s.blocks(child.node.Value, child.end)
+		if !ok || len(keys) == 0 {
+			if err := s.render(child.key, currentService, indent); err != nil {
+				return err
+			}
+			continue
+		}
+		s.keep(child.start, keys[0].start)
+		for _, k := range keys {
+			if err := s.item(k, beforeService, currentService, column(k)); err != nil {
+				return err
+			}
+		}
+		for _, item := range currentService {
+			if !found(keys, item.Key) && (!hasKey(beforeService, item.Key) || !equal(value(beforeService, item.Key), item.Value)) {
+				if err := s.render(item.Key, item.Value, column(keys[0])); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, item := range currentServices {
+		if !found(children, item.Key) {
+			if err := s.render(item.Key, item.Value, indent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// item keeps a key of the existing file if it's not changed (or it's unknown for storj-up), replaces it if it's
+// changed, and deletes it if it's removed.
+func (s *splice) item(b block, before yaml.MapSlice, current yaml.MapSlice, indent int) error {
+	switch {
+	case !hasKey(current, b.key) && hasKey(before, b.key):
+		return nil
+	case !hasKey(current, b.key), equal(value(before, b.key), value(current, b.key)):
+		s.keep(b.start, b.end)
+		return nil
+	default:
+		return s.render(b.key, value(current, b.key), indent)
+	}
+}
+
+// volumes declares the named volumes used by the services, and removes the unused volumes which are declared by
+// storj-up. Other volumes are kept.
+func (s *splice) volumes(b block, compose *types.Project) error {
+	used := usedVolumes(compose)
+	entries, ok := s.blocks(b.node.Value, b.end)
+	if !ok {
+		var volumes yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions([]byte(strings.Join(s.lines[b.start:b.end], "")), &volumes, yaml.UseOrderedMap()); err != nil {
+			s.keep(b.start, b.end)
+			return nil
+		}
+		existing, _ := value(volumes, b.key).(yaml.MapSlice)
+		if declared := declareVolumes(existing, compose); len(declared) > 0 {
+			return s.render(b.key, declared, 0)
+		}
+		return nil
+	}
+	var kept []block
+	for _, entry := range entries {
+		if slices.Contains(used, entry.key) || !s.declared(entry) {
+			kept = append(kept, entry)
+		}
+	}
+	var missing []string
+	for _, name := range used {
+		if !found(entries, name) {
+			missing = append(missing, name)
+		}
+	}
+	if len(kept) == 0 && len(missing) == 0 {
+		return nil
+	}
+	indent := 2
+	if len(entries) > 0 {
+		indent = column(entries[0])
+		s.keep(b.start, entries[0].start)
+	} else {
+		s.keep(b.start, b.end)
+	}
+	for _, entry := range kept {
+		s.keep(entry.start, entry.end)
+	}
+	for _, name := range missing {
+		if err := s.render(name, declaredVolume(), indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declared checks if a top-level volume is declared by storj-up.
+func (s *splice) declared(entry block) bool {
+	var volume yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(strings.Join(s.lines[entry.start:entry.end], "")), &volume, yaml.UseOrderedMap()); err != nil {
+		return false
+	}
+	config, _ := value(volume, entry.key).(yaml.MapSlice)
+	return value(config, VolumeExtension) == true
+}
+
+// blocks returns with the keys of a block style mapping, or false if the node is not a block style mapping. end is
+// the end of the parent block.
+func (s *splice) blocks(node ast.Node, end int) ([]block, bool) {
+	var values []*ast.MappingValueNode
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if n.IsFlowStyle {
+			return nil, false
+		}
+		values = n.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{n}
+	default:
+		return nil, false
+	}
+	var blocks []block
+	for _, v := range values {
+		blocks = append(blocks, block{key: v.Key.GetToken().Value, node: v, start: v.Key.GetToken().Position.Line - 1})
+	}
+	for ix := range blocks {
+		blocks[ix].end = end
+		if ix+1 < len(blocks) {
+			blocks[ix].end = blocks[ix+1].start
+		}
+		if blocks[ix].start < 0 || blocks[ix].start > blocks[ix].end {
+			return nil, false
+		}
+	}
+	return blocks, true
+}
+
+// keep copies the original lines.
+func (s *splice) keep(start int, end int) {
+	for _, line := range s.lines[start:end] {
+		s.out.WriteString(line)
+	}
+	if end > start && !strings.HasSuffix(s.lines[end-1], "\n") {
+		s.out.WriteString("\n")
+	}
+}
+
+// render writes a generated key with the indentation.
+func (s *splice) render(key any, value any, indent int) error {
+	raw, err := yaml.Marshal(yaml.MapSlice{{Key: key, Value: value}})
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	for _, line := range strings.SplitAfter(string(raw), "\n") {
+		if strings.TrimSpace(line) != "" {
+			s.out.WriteString(strings.Repeat(" ", indent))
+		}
+		s.out.WriteString(line)
+	}
+	return nil
+}
+
+func column(b block) int {
+	return b.node.Key.GetToken().Position.Column - 1
+}
+
+// found checks if one of the blocks has the key.
+func found(blocks []block, key any) bool {
+	return slices.ContainsFunc(blocks, func(b block) bool { return b.key == key })
+}
+
+func value(m yaml.MapSlice, key any) any {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+func equal(a any, b any) bool {
+	rawA, errA := yaml.Marshal(a)
+	rawB, errB := yaml.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(rawA, rawB)
+}
@@ -9,37 +9,6 @@ import (
 	"storj.io/storj-up/pkg/recipe"
 )
 
-// BuildDict stores the name of the container to build for Storj services.
-var BuildDict = map[string]string{
-	"authservice":          "app-edge",
-	"gateway-mt":           "app-edge",
-	"linksharing":          "app-edge",
-	"satellite-admin":      "app-storj",
-	"satellite-api":        "app-storj",
-	"satellite-core":       "app-storj",
-	"satellite-bf":         "app-storj",
-	"satellite-gc":         "app-storj",
-	"satellite-audit":      "app-storj",
-	"satellite-rangedloop": "app-storj",
-	"satellite-repair":     "app-storj",
-	"storagenode":          "app-storj",
-	"uplink":               "app-storj",
-	"versioncontrol":       "app-storj",
-}
-
-// ResolveBuilds returns with the required docker images to build (as keys in the maps).
-func ResolveBuilds(services []string) (map[string]string, error) {
-	result := make(map[string]string)
-	resolvedServices, err := ResolveServices(services)
-	if err != nil {
-		return result, err
-	}
-	for _, service := range resolvedServices {
-		result[BuildDict[service]] = ""
-	}
-	return result, nil
-}
-
 // ResolveServices replaces group definition with exact services in the list.
 func ResolveServices(selectors []string) ([]string, error) {
 	var res []string
@@ -0,0 +1,193 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package docker is a minimal client of the Docker Engine API. It manages the containers of compose projects
+// through the local socket, without the docker CLI.
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+)
+
+const (
+	// DefaultSocket is the socket of the docker daemon, if DOCKER_HOST is not set.
+	DefaultSocket = "/var/run/docker.sock"
+	// CLIEnv is the environment variable to use the docker CLI instead of the API (if it's not empty).
+	CLIEnv = "STORJUP_DOCKER_CLI"
+)
+
+// Client is a Docker Engine API client.
+type Client struct {
+	http *http.Client
+	base string
+}
+
+// Error is an error response of the Docker Engine API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsNotFound checks if the error is a 404 response of the API.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New creates a client for DOCKER_HOST, or the default socket (/var/run/docker.sock, or ~/.docker/run/docker.sock
+// of Docker Desktop). TLS connections are not supported.
+func New() (*Client, error) {
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" || os.Getenv("DOCKER_CERT_PATH") != "" {
+		return nil, errs.Errorf("TLS connections to the docker daemon are not supported")
+	}
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = "unix://" + DefaultSocket
+		if _, err := os.Stat(DefaultSocket); err != nil {
+			if home, err := os.UserHomeDir(); err == nil {
+				desktop := filepath.Join(home, ".docker", "run", "docker.sock")
+				if _, err := os.Stat(desktop); err == nil {
+					host = "unix://" + desktop
+				}
+			}
+		}
+	}
+	return NewClient(host)
+}
+
+// NewClient creates a client for a docker host (unix:///path/to/socket or tcp://host:port).
+func NewClient(host string) (*Client, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, errs.Errorf("invalid docker host %s: %v", host, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		}
+		return &Client{http: &http.Client{Transport: transport}, base: "http://docker"}, nil
+	case "tcp", "http":
+		return &Client{http: &http.Client{}, base: "http://" + u.Host}, nil
+	default:
+		return nil, errs.Errorf("unsupported docker host: %s", host)
+	}
+}
+
+// Available creates a client with New, and checks if the daemon is reachable. Returns with error, if the docker CLI
+// is forced with STORJUP_DOCKER_CLI.
+func Available(ctx context.Context) (*Client, error) {
+	if os.Getenv(CLIEnv) != "" {
+		return nil, errs.Errorf("docker CLI is selected with %s", CLIEnv)
+	}
+	c, err := New()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return c, c.Ping(ctx)
+}
+
+// Ping checks if the daemon is available.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Version is the version and platform of the docker daemon.
+type Version struct {
+	Version    string `json:"Version"`
+	APIVersion string `json:"ApiVersion"`
+	// Os and Arch use the names of GOOS and GOARCH (like linux and arm64).
+	Os   string `json:"Os"`
+	Arch string `json:"Arch"`
+}
+
+// Version returns with the version and platform of the daemon.
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	var version Version
+	err := c.call(ctx, http.MethodGet, "/version", nil, nil, &version)
+	return version, err
+}
+
+// do sends a request, and returns with the response if the status code is successful. Body is sent as JSON, unless
+// it's an io.Reader.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body any) (*http.Response, error) {
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+		contentType = "application/x-tar"
+	default:
+		raw, err := json.Marshal(body)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		reader = bytes.NewReader(raw)
+		contentType = "application/json"
+	}
+	target := c.base + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errs.Errorf("couldn't connect to the docker daemon: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		defer func() { _ = resp.Body.Close() }()
+		raw, _ := io.ReadAll(resp.Body)
+		var message struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(raw, &message) != nil || message.Message == "" {
+			message.Message = string(bytes.TrimSpace(raw))
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Message: message.Message}
+	}
+	return resp, nil
+}
+
+// call sends a request, and decodes the JSON response to out (if it's not nil).
+func (c *Client) call(ctx context.Context, method string, path string, query url.Values, body any, out any) error {
+	resp, err := c.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if out == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return errs.Wrap(err)
+	}
+	return errs.Wrap(json.NewDecoder(resp.Body).Decode(out))
+}
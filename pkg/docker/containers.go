@@ -0,0 +1,294 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// Container is the summary of a container, as listed by the API.
+type Container struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+}
+
+// Name returns with the name of the container, without the leading slash.
+func (c Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// ContainerDetails is the result of the container inspection.
+type ContainerDetails struct {
+	ID     string         `json:"Id"`
+	Name   string         `json:"Name"`
+	Image  string         `json:"Image"`
+	State  ContainerState `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+}
+
+// ContainerState is the runtime state of a container.
+type ContainerState struct {
+	// Status is one of created, running, paused, restarting, removing, exited or dead.
+	Status   string `json:"Status"`
+	Running  bool   `json:"Running"`
+	ExitCode int    `json:"ExitCode"`
+	Error    string `json:"Error"`
+	Health   *struct {
+		Status string `json:"Status"`
+	} `json:"Health,omitempty"`
+}
+
+// HealthStatus returns with the status of the health check, or empty string if there is no health check.
+func (s ContainerState) HealthStatus() string {
+	if s.Health == nil {
+		return ""
+	}
+	return s.Health.Status
+}
+
+// ContainerConfig is the configuration of a new container.
+type ContainerConfig struct {
+	Image        string              `json:"Image"`
+	Cmd          []string            `json:"Cmd,omitempty"`
+	Entrypoint   []string            `json:"Entrypoint,omitempty"`
+	Env          []string            `json:"Env,omitempty"`
+	Labels       map[string]string   `json:"Labels,omitempty"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts,omitempty"`
+	WorkingDir   string              `json:"WorkingDir,omitempty"`
+	User         string              `json:"User,omitempty"`
+	Hostname     string              `json:"Hostname,omitempty"`
+	Healthcheck  *Healthcheck        `json:"Healthcheck,omitempty"`
+
+	HostConfig       HostConfig       `json:"HostConfig"`
+	NetworkingConfig NetworkingConfig `json:"NetworkingConfig"`
+}
+
+// Healthcheck is the health check of a container. Durations are in nanoseconds.
+type Healthcheck struct {
+	Test        []string `json:"Test,omitempty"`
+	Interval    int64    `json:"Interval,omitempty"`
+	Timeout     int64    `json:"Timeout,omitempty"`
+	StartPeriod int64    `json:"StartPeriod,omitempty"`
+	Retries     int      `json:"Retries,omitempty"`
+}
+
+// HostConfig is the host specific configuration of a container.
+type HostConfig struct {
+	PortBindings  map[string][]PortBinding `json:"PortBindings,omitempty"`
+	Mounts        []Mount                  `json:"Mounts,omitempty"`
+	RestartPolicy RestartPolicy            `json:"RestartPolicy"`
+	Privileged    bool                     `json:"Privileged,omitempty"`
+	CapAdd        []string                 `json:"CapAdd,omitempty"`
+	ExtraHosts    []string                 `json:"ExtraHosts,omitempty"`
+}
+
+// PortBinding is a published port.
+type PortBinding struct {
+	HostIP   string `json:"HostIp,omitempty"`
+	HostPort string `json:"HostPort"`
+}
+
+// Mount is a bind mount, volume or tmpfs of a container.
+type Mount struct {
+	Type     string `json:"Type"`
+	Source   string `json:"Source,omitempty"`
+	Target   string `json:"Target"`
+	ReadOnly bool   `json:"ReadOnly,omitempty"`
+}
+
+// RestartPolicy is the restart policy of a container (no, always, unless-stopped or on-failure).
+type RestartPolicy struct {
+	Name              string `json:"Name,omitempty"`
+	MaximumRetryCount int    `json:"MaximumRetryCount,omitempty"`
+}
+
+// NetworkingConfig defines the networks of a new container.
+type NetworkingConfig struct {
+	EndpointsConfig map[string]EndpointSettings `json:"EndpointsConfig,omitempty"`
+}
+
+// EndpointSettings is the configuration of a container in a network.
+type EndpointSettings struct {
+	Aliases []string `json:"Aliases,omitempty"`
+}
+
+// ListContainers returns with all the containers (including the stopped ones) which have all the given labels
+// (key=value, or just key).
+func (c *Client) ListContainers(ctx context.Context, labels ...string) ([]Container, error) {
+	query := url.Values{"all": {"true"}}
+	if len(labels) > 0 {
+		filters, err := json.Marshal(map[string][]string{"label": labels})
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		query.Set("filters", string(filters))
+	}
+	var containers []Container
+	err := c.call(ctx, http.MethodGet, "/containers/json", query, nil, &containers)
+	return containers, err
+}
+
+// InspectContainer returns with the details of a container (by ID or name).
+func (c *Client) InspectContainer(ctx context.Context, id string) (ContainerDetails, error) {
+	var details ContainerDetails
+	err := c.call(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, nil, &details)
+	return details, err
+}
+
+// CreateContainer creates a new container, and returns with the ID.
+func (c *Client) CreateContainer(ctx context.Context, name string, config ContainerConfig) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := c.call(ctx, http.MethodPost, "/containers/create", url.Values{"name": {name}}, config, &created)
+	return created.ID, err
+}
+
+// StartContainer starts a container. Starting a running container is not an error.
+func (c *Client) StartContainer(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/start", nil, nil, nil)
+}
+
+// StopContainer stops a container, and kills it after the timeout (in seconds). Stopping a stopped container is not
+// an error.
+func (c *Client) StopContainer(ctx context.Context, id string, timeout int) error {
+	query := url.Values{"t": {strconv.Itoa(timeout)}}
+	return c.call(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/stop", query, nil, nil)
+}
+
+// RestartContainer restarts a container, and kills it after the timeout (in seconds), if it doesn't stop.
+func (c *Client) RestartContainer(ctx context.Context, id string, timeout int) error {
+	query := url.Values{"t": {strconv.Itoa(timeout)}}
+	return c.call(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/restart", query, nil, nil)
+}
+
+// RemoveContainer removes a container. Running containers are removed only with force, anonymous volumes only with
+// volumes.
+func (c *Client) RemoveContainer(ctx context.Context, id string, force bool, volumes bool) error {
+	query := url.Values{"force": {strconv.FormatBool(force)}, "v": {strconv.FormatBool(volumes)}}
+	return c.call(ctx, http.MethodDelete, "/containers/"+url.PathEscape(id), query, nil, nil)
+}
+
+// EnsureNetwork creates a bridge network with the labels, if it doesn't exist yet.
+func (c *Client) EnsureNetwork(ctx context.Context, name string, labels map[string]string) error {
+	err := c.call(ctx, http.MethodGet, "/networks/"+url.PathEscape(name), nil, nil, nil)
+	if !IsNotFound(err) {
+		return err
+	}
+	return c.call(ctx, http.MethodPost, "/networks/create", nil, map[string]any{
+		"Name":   name,
+		"Driver": "bridge",
+		"Labels": labels,
+	}, nil)
+}
+
+// ConnectNetwork connects a container to an additional network.
+func (c *Client) ConnectNetwork(ctx context.Context, network string, id string, aliases []string) error {
+	return c.call(ctx, http.MethodPost, "/networks/"+url.PathEscape(network)+"/connect", nil, map[string]any{
+		"Container":      id,
+		"EndpointConfig": EndpointSettings{Aliases: aliases},
+	}, nil)
+}
+
+// EnsureVolume creates a local volume with the labels, if it doesn't exist yet.
+func (c *Client) EnsureVolume(ctx context.Context, name string, labels map[string]string) error {
+	err := c.call(ctx, http.MethodGet, "/volumes/"+url.PathEscape(name), nil, nil, nil)
+	if !IsNotFound(err) {
+		return err
+	}
+	return c.call(ctx, http.MethodPost, "/volumes/create", nil, map[string]any{
+		"Name":   name,
+		"Labels": labels,
+	}, nil)
+}
+
+// RemoveVolume removes a volume. Removing a missing volume is not an error.
+func (c *Client) RemoveVolume(ctx context.Context, name string) error {
+	err := c.call(ctx, http.MethodDelete, "/volumes/"+url.PathEscape(name), url.Values{"force": {"true"}}, nil, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// WaitContainer waits until the container is stopped, and returns with the exit code.
+func (c *Client) WaitContainer(ctx context.Context, id string) (int, error) {
+	var result struct {
+		StatusCode int `json:"StatusCode"`
+		Error      *struct {
+			Message string `json:"Message"`
+		} `json:"Error"`
+	}
+	err := c.call(ctx, http.MethodPost, "/containers/"+url.PathEscape(id)+"/wait", nil, nil, &result)
+	if err == nil && result.Error != nil && result.Error.Message != "" {
+		err = errs.Errorf("%s", result.Error.Message)
+	}
+	return result.StatusCode, err
+}
+
+// RunContainer creates and starts a one-off container, waits until it's stopped and removes it. The image is pulled,
+// if it's not available locally. Non-zero exit code is returned as an error.
+func (c *Client) RunContainer(ctx context.Context, config ContainerConfig) (err error) {
+	exists, err := c.ImageExists(ctx, config.Image)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = c.PullImage(ctx, config.Image, nil)
+		if err != nil {
+			return errs.Errorf("couldn't pull %s: %v", config.Image, err)
+		}
+	}
+	id, err := c.CreateContainer(ctx, "", config)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errs.Combine(err, c.RemoveContainer(context.WithoutCancel(ctx), id, true, true)) }()
+	err = c.StartContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	exitCode, err := c.WaitContainer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		return errs.Errorf("%s exited with %d", strings.Join(append(config.Entrypoint, config.Cmd...), " "), exitCode)
+	}
+	return nil
+}
+
+// CopyFromContainer writes the file or directory of a container to w, as a tar archive.
+func (c *Client) CopyFromContainer(ctx context.Context, id string, path string, w io.Writer) error {
+	resp, err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/archive", url.Values{"path": {path}}, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, err = io.Copy(w, resp.Body)
+	return errs.Wrap(err)
+}
+
+// CopyToContainer extracts a tar archive to a directory of a container.
+func (c *Client) CopyToContainer(ctx context.Context, id string, dir string, archive io.Reader) error {
+	return c.call(ctx, http.MethodPut, "/containers/"+url.PathEscape(id)+"/archive", url.Values{"path": {dir}}, archive, nil)
+}
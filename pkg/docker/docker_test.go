@@ -0,0 +1,480 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine implements the subset of the Docker Engine API used by Up and Status.
+type fakeEngine struct {
+	mu         sync.Mutex
+	images     map[string]bool
+	pulled     []string
+	networks   map[string]bool
+	volumes    map[string]bool
+	containers map[string]*fakeContainer
+	nextID     int
+}
+
+type fakeContainer struct {
+	id       string
+	name     string
+	config   ContainerConfig
+	state    string
+	exitCode int
+}
+
+func newFakeEngine(t *testing.T) (*Client, *fakeEngine) {
+	e := &fakeEngine{
+		images:     map[string]bool{},
+		networks:   map[string]bool{},
+		volumes:    map[string]bool{},
+		containers: map[string]*fakeContainer{},
+	}
+	server := httptest.NewServer(e.handler())
+	t.Cleanup(server.Close)
+	c, err := NewClient("tcp://" + strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	return c, e
+}
+
+func (e *fakeEngine) handler() http.Handler {
+	mux := http.NewServeMux()
+	notFound := func(w http.ResponseWriter, message string) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": message})
+	}
+	mux.HandleFunc("GET /_ping", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("OK"))
+	})
+	mux.HandleFunc("GET /images/{name...}", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		name, tag := splitImage(strings.TrimSuffix(r.PathValue("name"), "/json"))
+		if !e.images[name+":"+tag] {
+			notFound(w, "No such image")
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("POST /images/create", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		image := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+		e.images[image] = true
+		e.pulled = append(e.pulled, image)
+		_, _ = fmt.Fprintf(w, `{"status":"Pulling from %s"}`+"\n"+`{"status":"Downloaded newer image"}`+"\n", image)
+	})
+	for _, kind := range []string{"networks", "volumes"} {
+		objects := e.networks
+		if kind == "volumes" {
+			objects = e.volumes
+		}
+		mux.HandleFunc("GET /"+kind+"/{name}", func(w http.ResponseWriter, r *http.Request) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			if !objects[r.PathValue("name")] {
+				notFound(w, "not found")
+				return
+			}
+			_, _ = w.Write([]byte("{}"))
+		})
+		mux.HandleFunc("POST /"+kind+"/create", func(w http.ResponseWriter, r *http.Request) {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			var body struct{ Name string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			objects[body.Name] = true
+			_, _ = w.Write([]byte("{}"))
+		})
+	}
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var filters map[string][]string
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		var list []Container
+	next:
+		for _, c := range e.containers {
+			for _, label := range filters["label"] {
+				key, value, _ := strings.Cut(label, "=")
+				if c.config.Labels[key] != value {
+					continue next
+				}
+			}
+			list = append(list, Container{ID: c.id, Names: []string{"/" + c.name}, State: c.state, Labels: c.config.Labels})
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	})
+	mux.HandleFunc("POST /containers/create", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		var config ContainerConfig
+		_ = json.NewDecoder(r.Body).Decode(&config)
+		e.nextID++
+		c := &fakeContainer{id: fmt.Sprintf("id%d", e.nextID), name: r.URL.Query().Get("name"), config: config, state: "created"}
+		e.containers[c.id] = c
+		_ = json.NewEncoder(w).Encode(map[string]string{"Id": c.id})
+	})
+	container := func(w http.ResponseWriter, r *http.Request) *fakeContainer {
+		c := e.containers[r.PathValue("id")]
+		if c == nil {
+			notFound(w, "No such container")
+		}
+		return c
+	}
+	mux.HandleFunc("GET /containers/{id}/json", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if c := container(w, r); c != nil {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"Id":    c.id,
+				"Name":  "/" + c.name,
+				"State": ContainerState{Status: c.state, Running: c.state == "running", ExitCode: c.exitCode},
+			})
+		}
+	})
+	mux.HandleFunc("POST /containers/{id}/start", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if c := container(w, r); c != nil {
+			c.state = "running"
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	mux.HandleFunc("POST /containers/{id}/stop", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if c := container(w, r); c != nil {
+			c.state = "exited"
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	// one-off containers exit with the code of their first argument
+	mux.HandleFunc("POST /containers/{id}/wait", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if c := container(w, r); c != nil {
+			c.state = "exited"
+			if len(c.config.Cmd) > 0 {
+				c.exitCode, _ = strconv.Atoi(c.config.Cmd[0])
+			}
+			_ = json.NewEncoder(w).Encode(map[string]int{"StatusCode": c.exitCode})
+		}
+	})
+	mux.HandleFunc("DELETE /containers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if c := container(w, r); c != nil {
+			delete(e.containers, c.id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	return mux
+}
+
+const testCompose = `
+services:
+  db:
+    image: postgres
+    volumes:
+      - pgdata:/var/lib/postgresql/data
+  app:
+    image: storjlabs/storj:1.0
+    depends_on:
+      - db
+    environment:
+      STORJ_DATABASE: postgres://db
+    ports:
+      - 7777:7777
+    volumes:
+      - ./bin:/var/lib/storj/go/bin
+    scale: 2
+volumes:
+  pgdata:
+`
+
+func writeTestProject(t *testing.T, dir string, compose string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yaml"), []byte(compose), 0o644))
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.Background()
+	client, e := newFakeEngine(t)
+	dir := filepath.Join(t.TempDir(), "My_Env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	writeTestProject(t, dir, testCompose)
+
+	project, err := LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "my_env", project.Name)
+
+	var events []string
+	progress := func(service string, m Message) {
+		events = append(events, service+" "+m.String())
+	}
+	require.NoError(t, client.Up(ctx, project, progress))
+	require.ElementsMatch(t, []string{"postgres:latest", "storjlabs/storj:1.0"}, e.pulled)
+	require.True(t, e.networks["my_env_default"])
+	require.True(t, e.volumes["my_env_pgdata"])
+	require.DirExists(t, filepath.Join(dir, "bin"))
+	require.Contains(t, events, "db my_env-db-1 Started")
+	require.Less(t, indexOf(events, "db my_env-db-1 Started"), indexOf(events, "app my_env-app-1 Created"))
+
+	states, err := client.Status(ctx, project.Name)
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+	require.Equal(t, "app", states[0].Service)
+	require.Equal(t, 1, states[0].Number)
+	require.Equal(t, "running", states[0].State)
+	require.Equal(t, "my_env-app-2", states[1].Name)
+	require.Equal(t, "db", states[2].Service)
+
+	for _, c := range e.containers {
+		if c.name == "my_env-app-1" {
+			require.Contains(t, c.config.Env, "STORJ_DATABASE=postgres://db")
+			require.Equal(t, []PortBinding{{HostPort: "7777"}}, c.config.HostConfig.PortBindings["7777/tcp"])
+			require.Equal(t, "app", c.config.Labels[ServiceLabel])
+			require.Equal(t, []string{"app"}, c.config.NetworkingConfig.EndpointsConfig["my_env_default"].Aliases)
+		}
+	}
+
+	// unchanged configuration: nothing is created, stopped containers are started
+	for _, c := range e.containers {
+		if c.name == "my_env-db-1" {
+			c.state = "exited"
+			c.exitCode = 137
+		}
+	}
+	states, err = client.Status(ctx, project.Name)
+	require.NoError(t, err)
+	require.Equal(t, "exited (137)", states[2].String())
+
+	events = nil
+	require.NoError(t, client.Up(ctx, project, progress))
+	require.Equal(t, []string{"db my_env-db-1 Started"}, events)
+
+	// changed environment and scale: the replica is recreated, the extra replica is removed
+	writeTestProject(t, dir, strings.ReplaceAll(strings.ReplaceAll(testCompose, "postgres://db", "postgres://db2"), "scale: 2", "scale: 1"))
+	project, err = LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	events = nil
+	require.NoError(t, client.Up(ctx, project, progress))
+	require.Equal(t, []string{
+		"app my_env-app-1 Recreating",
+		"app my_env-app-1 Created",
+		"app my_env-app-1 Started",
+		"app my_env-app-2 Removing",
+	}, events)
+	require.Len(t, e.containers, 2)
+}
+
+func TestProjectName(t *testing.T) {
+	ctx := context.Background()
+	client, e := newFakeEngine(t)
+	dir := filepath.Join(t.TempDir(), "My_Env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	// the top-level name (written by storj-up) has priority over the directory, the same way as in docker compose
+	writeTestProject(t, dir, "name: storj-up\n"+testCompose)
+
+	name, err := ProjectName(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "storj-up", name)
+	project, err := LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "storj-up", project.Name)
+	require.NoError(t, client.Up(ctx, project, nil))
+	require.True(t, e.networks["storj-up_default"])
+	states, err := client.Status(ctx, name)
+	require.NoError(t, err)
+	require.Len(t, states, 3)
+	require.Equal(t, "storj-up-app-1", states[0].Name)
+
+	t.Setenv("COMPOSE_PROJECT_NAME", "other")
+	name, err = ProjectName(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "other", name)
+
+	_, err = ProjectName(dir, "missing.yaml")
+	require.Error(t, err)
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSplitImage(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"postgres":                   {"postgres", "latest"},
+		"storjlabs/storj:1.0":        {"storjlabs/storj", "1.0"},
+		"localhost:5000/storj":       {"localhost:5000/storj", "latest"},
+		"localhost:5000/storj:dev":   {"localhost:5000/storj", "dev"},
+		"img@sha256:0123456789abcde": {"img", "sha256:0123456789abcde"},
+	} {
+		name, tag := splitImage(image)
+		require.Equal(t, expected, [2]string{name, tag}, image)
+	}
+}
+
+func TestError(t *testing.T) {
+	client, _ := newFakeEngine(t)
+	_, err := client.InspectContainer(context.Background(), "missing")
+	require.True(t, IsNotFound(err))
+	require.Equal(t, "No such container", err.Error())
+}
+
+func TestRecreate(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newFakeEngine(t)
+	dir := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	writeTestProject(t, dir, testCompose)
+	project, err := LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.NoError(t, client.Up(ctx, project, nil))
+
+	// only the containers of the selected service are recreated, even without configuration change
+	var events []string
+	require.NoError(t, client.Recreate(ctx, project, []string{"db"}, func(service string, m Message) {
+		events = append(events, service+" "+m.String())
+	}))
+	require.Equal(t, []string{"db env-db-1 Recreating", "db env-db-1 Created", "db env-db-1 Started"}, events)
+	require.Error(t, client.Recreate(ctx, project, []string{"missing"}, nil))
+}
+
+func TestServices(t *testing.T) {
+	ctx := context.Background()
+	client, e := newFakeEngine(t)
+	dir := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	writeTestProject(t, dir, testCompose)
+	project, err := LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+
+	// dependencies are created too, but nothing is started
+	require.NoError(t, client.Create(ctx, project, nil, "app"))
+	require.Len(t, e.containers, 3)
+	for _, c := range e.containers {
+		require.Equal(t, "created", c.state)
+	}
+
+	require.NoError(t, client.Start(ctx, project.Name, "db"))
+	require.NoError(t, client.Up(ctx, project, nil, "db"))
+	states, err := client.Status(ctx, project.Name)
+	require.NoError(t, err)
+	require.Equal(t, []string{"created", "created", "running"}, []string{states[0].State, states[1].State, states[2].State})
+
+	id, err := client.ServiceContainer(ctx, project.Name, "app")
+	require.NoError(t, err)
+	require.Equal(t, "env-app-1", e.containers[id].name)
+	id, err = client.ServiceContainer(ctx, project.Name, "missing")
+	require.NoError(t, err)
+	require.Empty(t, id)
+
+	require.NoError(t, client.Start(ctx, project.Name))
+	require.NoError(t, client.Stop(ctx, project.Name, 10, "app"))
+	states, err = client.Status(ctx, project.Name)
+	require.NoError(t, err)
+	require.Equal(t, []string{"exited", "exited", "running"}, []string{states[0].State, states[1].State, states[2].State})
+}
+
+func TestRunContainer(t *testing.T) {
+	ctx := context.Background()
+	client, e := newFakeEngine(t)
+
+	require.NoError(t, client.RunContainer(ctx, ContainerConfig{Image: "busybox", Cmd: []string{"0"}}))
+	require.Equal(t, []string{"busybox:latest"}, e.pulled)
+	require.Empty(t, e.containers)
+
+	err := client.RunContainer(ctx, ContainerConfig{Image: "busybox", Cmd: []string{"2", "fail"}})
+	require.ErrorContains(t, err, "2 fail exited with 2")
+	require.Len(t, e.pulled, 1)
+	require.Empty(t, e.containers)
+}
+
+func TestUpWithoutHash(t *testing.T) {
+	ctx := context.Background()
+	client, e := newFakeEngine(t)
+	dir := filepath.Join(t.TempDir(), "env")
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	writeTestProject(t, dir, testCompose)
+	project, err := LoadProject(dir, "docker-compose.yaml")
+	require.NoError(t, err)
+	require.NoError(t, client.Up(ctx, project, nil))
+
+	// container created by docker compose: the configuration is unknown
+	for _, c := range e.containers {
+		if c.name == "env-db-1" {
+			delete(c.config.Labels, ConfigHashLabel)
+		}
+	}
+	var events []string
+	require.NoError(t, client.Up(ctx, project, func(service string, m Message) {
+		events = append(events, service+" "+m.String())
+	}))
+	require.Equal(t, []string{"db env-db-1 Recreating", "db env-db-1 Created", "db env-db-1 Started"}, events)
+}
+
+func TestWaitReady(t *testing.T) {
+	ctx := context.Background()
+	polls := 0
+	states := [][]ServiceState{
+		{{Service: "a", State: "created"}, {Service: "b", State: "running", Health: "starting"}},
+		{{Service: "a", State: "running"}, {Service: "b", State: "running", Health: "starting"}},
+		{{Service: "a", State: "running"}, {Service: "b", State: "running", Health: "healthy"}, {Service: "c", State: "exited"}},
+	}
+	status := func(context.Context) ([]ServiceState, error) {
+		polls++
+		return states[min(polls, len(states))-1], nil
+	}
+	require.NoError(t, WaitReady(ctx, status, []string{"a", "b"}, time.Millisecond))
+	require.Equal(t, 3, polls)
+
+	// failed container
+	polls = 0
+	states = [][]ServiceState{{{Service: "a", Name: "env-a-1", State: "exited", ExitCode: 1}}}
+	err := WaitReady(ctx, status, []string{"a"}, time.Millisecond)
+	require.ErrorContains(t, err, "env-a-1 is exited (1)")
+
+	// missing container
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	require.ErrorContains(t, WaitReady(ctx, status, []string{"b"}, time.Millisecond), "containers of b are not created")
+}
+
+func TestParseComposePS(t *testing.T) {
+	expected := []ServiceState{
+		{Service: "storagenode1", Name: "env-storagenode1-1", ID: "abc", State: "running", Health: "healthy"},
+		{Service: "storagenode2", Name: "env-storagenode2-1", ID: "def", State: "exited", ExitCode: 2},
+	}
+	lines := `{"ID":"abc","Name":"env-storagenode1-1","Service":"storagenode1","State":"running","Health":"healthy","ExitCode":0}
+{"ID":"def","Name":"env-storagenode2-1","Service":"storagenode2","State":"exited","Health":"","ExitCode":2}
+`
+	states, err := ParseComposePS([]byte(lines))
+	require.NoError(t, err)
+	require.Equal(t, expected, states)
+
+	states, err = ParseComposePS([]byte("[" + strings.Join(strings.Split(strings.TrimSpace(lines), "\n"), ",") + "]"))
+	require.NoError(t, err)
+	require.Equal(t, expected, states)
+}
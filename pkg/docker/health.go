@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// Ready returns true if the container is running, and healthy if it has a health check. The error is not nil, if the
+// container is failed (exited, dead or unhealthy).
+func (s ServiceState) Ready() (bool, error) {
+	switch {
+	case s.State == "exited" || s.State == "dead":
+		return false, errs.Errorf("%s is %s", s.Name, s)
+	case s.Health == "unhealthy":
+		return false, errs.Errorf("%s is unhealthy", s.Name)
+	case s.State != "running":
+		return false, nil
+	}
+	return s.Health == "" || s.Health == "healthy", nil
+}
+
+// WaitReady polls the state of the containers of the services until all of them are ready (see ServiceState.Ready).
+// It returns with an error if any of them is failed, or if the context is canceled.
+func WaitReady(ctx context.Context, status func(ctx context.Context) ([]ServiceState, error), services []string, interval time.Duration) error {
+	wanted := map[string]bool{}
+	for _, service := range services {
+		wanted[service] = true
+	}
+	for {
+		states, err := status(ctx)
+		if err != nil {
+			return err
+		}
+		ready := map[string]bool{}
+		pending := false
+		for _, state := range states {
+			if !wanted[state.Service] {
+				continue
+			}
+			ok, err := state.Ready()
+			if err != nil {
+				return err
+			}
+			pending = pending || !ok
+			ready[state.Service] = true
+		}
+		var missing []string
+		for _, service := range services {
+			if !ready[service] {
+				missing = append(missing, service)
+			}
+		}
+		if !pending && len(missing) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if len(missing) > 0 {
+				return errs.Errorf("containers of %s are not created: %v", strings.Join(missing, ", "), ctx.Err())
+			}
+			return errs.Errorf("containers are not ready: %v", ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ParseComposePS parses the output of `docker compose ps --format json`, which is a JSON array (older versions) or
+// one JSON object per line.
+func ParseComposePS(out []byte) ([]ServiceState, error) {
+	type container struct {
+		ID       string
+		Name     string
+		Service  string
+		State    string
+		Health   string
+		ExitCode int
+	}
+	var containers []container
+	out = bytes.TrimSpace(out)
+	if bytes.HasPrefix(out, []byte("[")) {
+		if err := json.Unmarshal(out, &containers); err != nil {
+			return nil, errs.Wrap(err)
+		}
+	} else {
+		for _, line := range bytes.Split(out, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			var c container
+			if err := json.Unmarshal(line, &c); err != nil {
+				return nil, errs.Wrap(err)
+			}
+			containers = append(containers, c)
+		}
+	}
+	states := make([]ServiceState, 0, len(containers))
+	for _, c := range containers {
+		states = append(states, ServiceState{
+			Service:  c.Service,
+			Name:     c.Name,
+			ID:       c.ID,
+			State:    c.State,
+			Health:   c.Health,
+			ExitCode: c.ExitCode,
+		})
+	}
+	return states, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package docker
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// Message is a progress message of an image pull or build.
+type Message struct {
+	ID       string `json:"id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Progress string `json:"progress,omitempty"`
+	Stream   string `json:"stream,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// String returns with the human-readable form of the message.
+func (m Message) String() string {
+	if m.Stream != "" {
+		return strings.TrimRight(m.Stream, "\n")
+	}
+	parts := make([]string, 0, 3)
+	for _, part := range []string{m.ID, m.Status, m.Progress} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ImageExists checks if an image (name:tag or ID) is available locally.
+func (c *Client) ImageExists(ctx context.Context, image string) (bool, error) {
+	err := c.call(ctx, http.MethodGet, "/images/"+image+"/json", nil, nil, nil)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PullImage pulls an image from the registry. Progress is called with each message (it can be nil).
+func (c *Client) PullImage(ctx context.Context, image string, progress func(Message)) error {
+	name, tag := splitImage(image)
+	query := url.Values{"fromImage": {name}, "tag": {tag}}
+	return c.stream(ctx, http.MethodPost, "/images/create", query, nil, progress)
+}
+
+// BuildImage builds an image from a local build context, and tags it. Progress is called with each message (it can
+// be nil).
+func (c *Client) BuildImage(ctx context.Context, contextDir string, dockerfile string, tag string, args map[string]string, progress func(Message)) error {
+	query := url.Values{"t": {tag}, "rm": {"true"}}
+	if dockerfile != "" {
+		query.Set("dockerfile", dockerfile)
+	}
+	if len(args) > 0 {
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		query.Set("buildargs", string(raw))
+	}
+	reader, writer := io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(archiveDir(contextDir, writer))
+	}()
+	defer func() { _ = reader.Close() }()
+	return c.stream(ctx, http.MethodPost, "/build", query, reader, progress)
+}
+
+// stream sends a request, and reads the response as a stream of JSON progress messages. Error messages of the
+// stream are returned as errors.
+func (c *Client) stream(ctx context.Context, method string, path string, query url.Values, body any, progress func(Message)) error {
+	resp, err := c.do(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var m Message
+		err := decoder.Decode(&m)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		if m.Error != "" {
+			return errs.Errorf("%s", m.Error)
+		}
+		if progress != nil {
+			progress(m)
+		}
+	}
+}
+
+// splitImage splits an image reference to name and tag. The tag is latest, if not specified.
+func splitImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		name, digest, _ := strings.Cut(image, "@")
+		return name, digest
+	}
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, "latest"
+}
+
+// archiveDir writes the content of a directory to w as a tar archive.
+func archiveDir(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err = os.Readlink(path)
+			if err != nil {
+				return err
+			}
+		}
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return errs.Wrap(tw.Close())
+}
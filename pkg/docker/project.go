@@ -0,0 +1,629 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/zeebo/errs/v2"
+)
+
+// Labels of the containers, networks and volumes. They are the same as the labels of docker compose, therefore the
+// containers created by `docker compose up` and by Up are interchangeable.
+const (
+	ProjectLabel    = "com.docker.compose.project"
+	ServiceLabel    = "com.docker.compose.service"
+	NumberLabel     = "com.docker.compose.container-number"
+	OneoffLabel     = "com.docker.compose.oneoff"
+	WorkingDirLabel = "com.docker.compose.project.working_dir"
+	NetworkLabel    = "com.docker.compose.network"
+	VolumeLabel     = "com.docker.compose.volume"
+	// ConfigHashLabel is the hash of the container configuration, used to detect the changed services.
+	ConfigHashLabel = "io.storj.up.config-hash"
+)
+
+// Progress is called with the progress messages of the image pulls and builds, and with the state changes of the
+// containers (like Created or Started).
+type Progress func(service string, m Message)
+
+// ServiceState is the state of a container of a compose project.
+type ServiceState struct {
+	Service  string
+	Number   int
+	Name     string
+	ID       string
+	State    string
+	Health   string
+	ExitCode int
+	Error    string
+}
+
+// ProjectName returns with the compose project name of a compose file, the same way as docker compose does:
+// COMPOSE_PROJECT_NAME, the top-level name of the compose file (storj-up for the generated files), or the normalized
+// name of the directory. The containers created by Up and by the docker compose CLI belong to the same project.
+func ProjectName(dir string, filename string) (string, error) {
+	project, err := LoadProject(dir, filename)
+	if err != nil {
+		return "", err
+	}
+	return project.Name, nil
+}
+
+// LoadProject loads a compose file with the environment and the project name used by docker compose (see
+// ProjectName).
+func LoadProject(dir string, filename string) (*types.Project, error) {
+	options, err := cli.NewProjectOptions([]string{filepath.Join(dir, filename)},
+		cli.WithWorkingDirectory(dir), cli.WithOsEnv, cli.WithDotEnv)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	project, err := options.LoadProject(context.Background())
+	return project, errs.Wrap(err)
+}
+
+// Up creates (or recreates, if the configuration is changed) and starts the containers of the project in dependency
+// order. Missing images are pulled, or built if the service has a build section. Containers of removed replicas are
+// removed. Only the given services and their dependencies are started, if any service is given.
+func (c *Client) Up(ctx context.Context, project *types.Project, progress Progress, services ...string) error {
+	return c.up(ctx, project, progress, services, true)
+}
+
+// Create creates (or recreates, if the configuration is changed) the containers of the services and their
+// dependencies like Up, but the new containers are not started.
+func (c *Client) Create(ctx context.Context, project *types.Project, progress Progress, services ...string) error {
+	return c.up(ctx, project, progress, services, false)
+}
+
+func (c *Client) up(ctx context.Context, project *types.Project, progress Progress, services []string, start bool) error {
+	if progress == nil {
+		progress = func(string, Message) {}
+	}
+	existing, err := c.projectContainers(ctx, project.Name)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+	return project.ForEachService(services, func(name string, service *types.ServiceConfig) error {
+		return c.upService(ctx, project, name, service, existing[name], false, start, progress)
+	})
+}
+
+// Recreate recreates and starts the containers of the services, even if their configuration is not changed. Other
+// services (and the dependencies) are not changed.
+func (c *Client) Recreate(ctx context.Context, project *types.Project, services []string, progress Progress) error {
+	if progress == nil {
+		progress = func(string, Message) {}
+	}
+	existing, err := c.projectContainers(ctx, project.Name)
+	if err != nil {
+		return err
+	}
+	for _, name := range services {
+		service, err := project.GetService(name)
+		if err != nil {
+			return errs.Wrap(err)
+		}
+		err = c.upService(ctx, project, name, &service, existing[name], true, true, progress)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upService creates (or recreates, if the configuration is changed or force is true) and starts (if start is true) the
+// containers of a service. existing are the current containers of the service by replica number.
+func (c *Client) upService(ctx context.Context, project *types.Project, name string, service *types.ServiceConfig, existing map[int]Container, force bool, start bool, progress Progress) error {
+	if start {
+		err := c.waitDependencies(ctx, project.Name, service)
+		if err != nil {
+			return err
+		}
+	}
+	image, err := c.ensureImage(ctx, project, service, func(m Message) { progress(name, m) })
+	if err != nil {
+		return errs.Errorf("couldn't prepare the image of %s: %v", name, err)
+	}
+	config, err := c.containerConfig(ctx, project, service, image)
+	if err != nil {
+		return errs.Errorf("couldn't configure %s: %v", name, err)
+	}
+	hash, err := configHash(config)
+	if err != nil {
+		return err
+	}
+	// only one network can be set at creation, the others are connected later
+	networks := serviceNetworks(project, service)
+	config.NetworkingConfig = NetworkingConfig{EndpointsConfig: map[string]EndpointSettings{
+		networks[0].name: {Aliases: networks[0].aliases},
+	}}
+
+	replicas := service.GetScale()
+	for number := 1; number <= replicas; number++ {
+		config.Labels[NumberLabel] = strconv.Itoa(number)
+		config.Labels[ConfigHashLabel] = hash
+		containerName := service.ContainerName
+		if containerName == "" || replicas > 1 {
+			containerName = fmt.Sprintf("%s-%s-%d", project.Name, name, number)
+		}
+
+		// containers without hash (like the ones created by docker compose) are recreated, their configuration is unknown
+		current, found := existing[number]
+		if found && (force || current.Labels[ConfigHashLabel] != hash) {
+			progress(name, Message{ID: containerName, Status: "Recreating"})
+			err = c.RemoveContainer(ctx, current.ID, true, false)
+			if err != nil {
+				return err
+			}
+			found = false
+		}
+		id := current.ID
+		if !found {
+			id, err = c.CreateContainer(ctx, containerName, config)
+			if err != nil {
+				return errs.Errorf("couldn't create %s: %v", containerName, err)
+			}
+			for _, network := range networks[1:] {
+				err = c.ConnectNetwork(ctx, network.name, id, network.aliases)
+				if err != nil {
+					return errs.Errorf("couldn't connect %s to %s: %v", containerName, network.name, err)
+				}
+			}
+			progress(name, Message{ID: containerName, Status: "Created"})
+		}
+		if start && (!found || current.State != "running") {
+			err = c.StartContainer(ctx, id)
+			if err != nil {
+				return errs.Errorf("couldn't start %s: %v", containerName, err)
+			}
+			progress(name, Message{ID: containerName, Status: "Started"})
+		}
+	}
+	for number, current := range existing {
+		if number > replicas {
+			progress(name, Message{ID: current.Name(), Status: "Removing"})
+			err = c.RemoveContainer(ctx, current.ID, true, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stop stops the containers of the services of a project, or all the containers if no service is given. The timeout
+// is in seconds.
+func (c *Client) Stop(ctx context.Context, projectName string, timeout int, services ...string) error {
+	containers, err := c.serviceContainers(ctx, projectName, services)
+	if err != nil {
+		return err
+	}
+	var group errs.Group
+	for _, container := range containers {
+		if container.State == "running" {
+			group.Add(c.StopContainer(ctx, container.ID, timeout))
+		}
+	}
+	return group.Err()
+}
+
+// Start starts the existing containers of the services of a project, or all the containers if no service is given.
+func (c *Client) Start(ctx context.Context, projectName string, services ...string) error {
+	containers, err := c.serviceContainers(ctx, projectName, services)
+	if err != nil {
+		return err
+	}
+	var group errs.Group
+	for _, container := range containers {
+		if container.State != "running" {
+			group.Add(c.StartContainer(ctx, container.ID))
+		}
+	}
+	return group.Err()
+}
+
+// ServiceContainer returns with the ID of the first container of a service, or empty string if it's not created.
+func (c *Client) ServiceContainer(ctx context.Context, projectName string, service string) (string, error) {
+	containers, err := c.serviceContainers(ctx, projectName, []string{service})
+	if err != nil || len(containers) == 0 {
+		return "", err
+	}
+	first := containers[0]
+	for _, container := range containers[1:] {
+		number, _ := strconv.Atoi(container.Labels[NumberLabel])
+		if current, _ := strconv.Atoi(first.Labels[NumberLabel]); number < current {
+			first = container
+		}
+	}
+	return first.ID, nil
+}
+
+// Restart restarts the containers of the services of a project. The timeout is in seconds.
+func (c *Client) Restart(ctx context.Context, projectName string, services []string, timeout int) error {
+	var group errs.Group
+	for _, service := range services {
+		containers, err := c.ListContainers(ctx, ProjectLabel+"="+projectName, ServiceLabel+"="+service)
+		if err != nil {
+			return err
+		}
+		if len(containers) == 0 {
+			group.Add(errs.Errorf("container of %s is not created", service))
+		}
+		for _, container := range containers {
+			group.Add(c.RestartContainer(ctx, container.ID, timeout))
+		}
+	}
+	return group.Err()
+}
+
+// Status returns with the state of all the containers of a project, ordered by service and replica number.
+func (c *Client) Status(ctx context.Context, projectName string) ([]ServiceState, error) {
+	containers, err := c.ListContainers(ctx, ProjectLabel+"="+projectName)
+	if err != nil {
+		return nil, err
+	}
+	var states []ServiceState
+	for _, container := range containers {
+		if container.Labels[OneoffLabel] == "True" {
+			continue
+		}
+		details, err := c.InspectContainer(ctx, container.ID)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		number, _ := strconv.Atoi(container.Labels[NumberLabel])
+		states = append(states, ServiceState{
+			Service:  container.Labels[ServiceLabel],
+			Number:   number,
+			Name:     container.Name(),
+			ID:       container.ID,
+			State:    details.State.Status,
+			Health:   details.State.HealthStatus(),
+			ExitCode: details.State.ExitCode,
+			Error:    details.State.Error,
+		})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Service != states[j].Service {
+			return states[i].Service < states[j].Service
+		}
+		return states[i].Number < states[j].Number
+	})
+	return states, nil
+}
+
+// serviceContainers returns with the (non one-off) containers of the services, or all the containers of the project
+// if no service is given.
+func (c *Client) serviceContainers(ctx context.Context, projectName string, services []string) ([]Container, error) {
+	containers, err := c.ListContainers(ctx, ProjectLabel+"="+projectName)
+	if err != nil {
+		return nil, err
+	}
+	var selected []Container
+	for _, container := range containers {
+		if container.Labels[OneoffLabel] == "True" {
+			continue
+		}
+		if len(services) == 0 || slices.Contains(services, container.Labels[ServiceLabel]) {
+			selected = append(selected, container)
+		}
+	}
+	return selected, nil
+}
+
+// projectContainers returns with the (non one-off) containers of the project, by service and replica number.
+func (c *Client) projectContainers(ctx context.Context, projectName string) (map[string]map[int]Container, error) {
+	containers, err := c.ListContainers(ctx, ProjectLabel+"="+projectName)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]map[int]Container{}
+	for _, container := range containers {
+		if container.Labels[OneoffLabel] == "True" {
+			continue
+		}
+		service := container.Labels[ServiceLabel]
+		number, err := strconv.Atoi(container.Labels[NumberLabel])
+		if err != nil {
+			continue
+		}
+		if result[service] == nil {
+			result[service] = map[int]Container{}
+		}
+		result[service][number] = container
+	}
+	return result, nil
+}
+
+// waitDependencies waits until the dependencies of the service are healthy or completed, if it's required by the
+// condition of the dependency.
+func (c *Client) waitDependencies(ctx context.Context, projectName string, service *types.ServiceConfig) error {
+	for dependency, config := range service.DependsOn {
+		if config.Condition != types.ServiceConditionHealthy && config.Condition != types.ServiceConditionCompletedSuccessfully {
+			continue
+		}
+		for {
+			ready, err := c.dependencyReady(ctx, projectName, dependency, config.Condition)
+			if err != nil {
+				return errs.Errorf("dependency %s of %s failed: %v", dependency, service.Name, err)
+			}
+			if ready {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Client) dependencyReady(ctx context.Context, projectName string, service string, condition string) (bool, error) {
+	containers, err := c.ListContainers(ctx, ProjectLabel+"="+projectName, ServiceLabel+"="+service)
+	if err != nil {
+		return false, err
+	}
+	for _, container := range containers {
+		details, err := c.InspectContainer(ctx, container.ID)
+		if err != nil {
+			return false, err
+		}
+		switch condition {
+		case types.ServiceConditionHealthy:
+			if details.State.HealthStatus() == "unhealthy" {
+				return false, errs.Errorf("container %s is unhealthy", container.Name())
+			}
+			if details.State.HealthStatus() != "healthy" {
+				return false, nil
+			}
+		case types.ServiceConditionCompletedSuccessfully:
+			if details.State.Running || details.State.Status == "created" {
+				return false, nil
+			}
+			if details.State.ExitCode != 0 {
+				return false, errs.Errorf("container %s exited with %d", container.Name(), details.State.ExitCode)
+			}
+		}
+	}
+	return len(containers) > 0, nil
+}
+
+// ensureImage pulls or builds the image of the service, if it's not available locally.
+func (c *Client) ensureImage(ctx context.Context, project *types.Project, service *types.ServiceConfig, progress func(Message)) (string, error) {
+	image := service.Image
+	if image == "" {
+		image = project.Name + "-" + service.Name
+	}
+	exists, err := c.ImageExists(ctx, image)
+	if err != nil || exists {
+		return image, err
+	}
+	if service.Build != nil {
+		args := map[string]string{}
+		for key, value := range service.Build.Args {
+			if value != nil {
+				args[key] = *value
+			}
+		}
+		return image, c.BuildImage(ctx, service.Build.Context, service.Build.Dockerfile, image, args, progress)
+	}
+	return image, c.PullImage(ctx, image, progress)
+}
+
+// containerConfig converts the compose service to the configuration of the container. Labels of the replica number
+// and config hash are not included.
+func (c *Client) containerConfig(ctx context.Context, project *types.Project, service *types.ServiceConfig, image string) (ContainerConfig, error) {
+	config := ContainerConfig{
+		Image:      image,
+		Cmd:        service.Command,
+		Entrypoint: service.Entrypoint,
+		WorkingDir: service.WorkingDir,
+		User:       service.User,
+		Hostname:   service.Hostname,
+		Labels: map[string]string{
+			ProjectLabel:    project.Name,
+			ServiceLabel:    service.Name,
+			OneoffLabel:     "False",
+			WorkingDirLabel: project.WorkingDir,
+		},
+		HostConfig: HostConfig{
+			Privileged: service.Privileged,
+			CapAdd:     service.CapAdd,
+			ExtraHosts: service.ExtraHosts.AsList(":"),
+		},
+	}
+	if service.Restart != "" {
+		name, retries, _ := strings.Cut(service.Restart, ":")
+		config.HostConfig.RestartPolicy.Name = name
+		config.HostConfig.RestartPolicy.MaximumRetryCount, _ = strconv.Atoi(retries)
+	}
+	for key, value := range service.Labels {
+		config.Labels[key] = value
+	}
+	for key, value := range service.Environment {
+		if value != nil {
+			config.Env = append(config.Env, key+"="+*value)
+		}
+	}
+	sort.Strings(config.Env)
+	sort.Strings(config.HostConfig.ExtraHosts)
+
+	for _, port := range service.Ports {
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		key := fmt.Sprintf("%d/%s", port.Target, protocol)
+		if config.ExposedPorts == nil {
+			config.ExposedPorts = map[string]struct{}{}
+			config.HostConfig.PortBindings = map[string][]PortBinding{}
+		}
+		config.ExposedPorts[key] = struct{}{}
+		if port.Published != "" {
+			config.HostConfig.PortBindings[key] = append(config.HostConfig.PortBindings[key], PortBinding{
+				HostIP:   port.HostIP,
+				HostPort: port.Published,
+			})
+		}
+	}
+
+	for _, volume := range service.Volumes {
+		mount := Mount{Type: volume.Type, Source: volume.Source, Target: volume.Target, ReadOnly: volume.ReadOnly}
+		switch volume.Type {
+		case types.VolumeTypeBind:
+			// docker compose creates the missing host directories, the API doesn't
+			if _, err := os.Stat(volume.Source); os.IsNotExist(err) {
+				if err := os.MkdirAll(volume.Source, 0o755); err != nil {
+					return config, errs.Wrap(err)
+				}
+			}
+		case types.VolumeTypeVolume:
+			if volume.Source == "" {
+				break
+			}
+			name := project.Name + "_" + volume.Source
+			external := false
+			if v, ok := project.Volumes[volume.Source]; ok {
+				if v.Name != "" {
+					name = v.Name
+				}
+				external = bool(v.External)
+			}
+			if !external {
+				err := c.EnsureVolume(ctx, name, map[string]string{ProjectLabel: project.Name, VolumeLabel: volume.Source})
+				if err != nil {
+					return config, err
+				}
+			}
+			mount.Source = name
+		case types.VolumeTypeTmpfs:
+			mount.Source = ""
+		default:
+			return config, errs.Errorf("unsupported volume type: %s", volume.Type)
+		}
+		config.HostConfig.Mounts = append(config.HostConfig.Mounts, mount)
+	}
+	for _, target := range service.Tmpfs {
+		config.HostConfig.Mounts = append(config.HostConfig.Mounts, Mount{Type: types.VolumeTypeTmpfs, Target: target})
+	}
+
+	if hc := service.HealthCheck; hc != nil {
+		config.Healthcheck = &Healthcheck{Test: hc.Test}
+		if hc.Disable {
+			config.Healthcheck.Test = []string{"NONE"}
+		}
+		if hc.Interval != nil {
+			config.Healthcheck.Interval = int64(*hc.Interval)
+		}
+		if hc.Timeout != nil {
+			config.Healthcheck.Timeout = int64(*hc.Timeout)
+		}
+		if hc.StartPeriod != nil {
+			config.Healthcheck.StartPeriod = int64(*hc.StartPeriod)
+		}
+		if hc.Retries != nil {
+			config.Healthcheck.Retries = int(*hc.Retries)
+		}
+	}
+
+	for _, network := range serviceNetworks(project, service) {
+		if network.external {
+			continue
+		}
+		err := c.EnsureNetwork(ctx, network.name, map[string]string{ProjectLabel: project.Name, NetworkLabel: network.key})
+		if err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+type serviceNetwork struct {
+	key      string
+	name     string
+	aliases  []string
+	external bool
+}
+
+// serviceNetworks returns with the networks of the service, ordered by name. Services without networks use the
+// default network of the project.
+func serviceNetworks(project *types.Project, service *types.ServiceConfig) []serviceNetwork {
+	keys := make([]string, 0, len(service.Networks))
+	for key := range service.Networks {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		keys = append(keys, "default")
+	}
+	sort.Strings(keys)
+	var networks []serviceNetwork
+	for _, key := range keys {
+		network := serviceNetwork{
+			key:     key,
+			name:    project.Name + "_" + key,
+			aliases: []string{service.Name},
+		}
+		if config, ok := project.Networks[key]; ok {
+			if config.Name != "" {
+				network.name = config.Name
+			}
+			network.external = bool(config.External)
+		}
+		if config := service.Networks[key]; config != nil {
+			network.aliases = append(network.aliases, config.Aliases...)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// configHash is the hash of the container configuration, without the labels which are set per replica.
+func configHash(config ContainerConfig) (string, error) {
+	labels := map[string]string{}
+	for key, value := range config.Labels {
+		if key != NumberLabel && key != ConfigHashLabel {
+			labels[key] = value
+		}
+	}
+	config.Labels = labels
+	config.NetworkingConfig = NetworkingConfig{}
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// String returns with the short, human-readable state of the container (like "running (healthy)" or "exited (1)").
+func (s ServiceState) String() string {
+	state := s.State
+	switch {
+	case s.Health != "":
+		state += " (" + s.Health + ")"
+	case s.State == "exited" || s.State == "dead":
+		state += fmt.Sprintf(" (%d)", s.ExitCode)
+	}
+	return strings.TrimSpace(state)
+}
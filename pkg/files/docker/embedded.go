@@ -18,6 +18,24 @@ var StorjDocker []byte
 //go:embed edge.Dockerfile
 var EdgeDocker []byte
 
+// StorjscanDocker is a Dockerfile for storjscan.
+//
+//go:embed storjscan.Dockerfile
+var StorjscanDocker []byte
+
+// Embedded returns with an embedded Dockerfile by name (like storj.Dockerfile).
+func Embedded(name string) ([]byte, bool) {
+	switch name {
+	case "storj.Dockerfile":
+		return StorjDocker, true
+	case "edge.Dockerfile":
+		return EdgeDocker, true
+	case "storjscan.Dockerfile":
+		return StorjscanDocker, true
+	}
+	return nil, false
+}
+
 //go:embed build.last
 var buildTag string
 
@@ -0,0 +1,372 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package gobuild installs the local binaries of the services (go install), in parallel, for the platform of the
+// containers or of the host. Builds are skipped if their inputs are not changed since the last successful build.
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common/atomicfile"
+)
+
+// CacheFileName is the name of the file which stores the inputs of the last successful builds.
+const CacheFileName = ".storj-up.builds.json"
+
+// CGO strategies of a binary.
+const (
+	// CGOAuto uses the default of go for the host, and enables cgo for cross-compilation only if a (non-standard)
+	// dependency has cgo files.
+	CGOAuto = "auto"
+	// CGOOn always enables cgo. Cross-compilation requires a C cross compiler (set with CC).
+	CGOOn = "on"
+	// CGOOff always disables cgo.
+	CGOOff = "off"
+)
+
+// Platform is the target of a build.
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// Host is the platform of the host.
+func Host() Platform {
+	return Platform{OS: goruntime.GOOS, Arch: goruntime.GOARCH}
+}
+
+// String implements fmt.Stringer.
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// Build is a binary to install.
+type Build struct {
+	// Source is the directory of the main package.
+	Source   string
+	Platform Platform
+	// CGO is one of CGOAuto (default), CGOOn and CGOOff.
+	CGO string
+}
+
+// String implements fmt.Stringer.
+func (b Build) String() string {
+	return b.Source + " (" + b.Platform.String() + ")"
+}
+
+// key identifies the build in the cache.
+func (b Build) key() string {
+	cgo := b.CGO
+	if cgo == "" {
+		cgo = CGOAuto
+	}
+	return b.Source + "|" + b.Platform.String() + "|" + cgo
+}
+
+// Result is the outcome of a build.
+type Result struct {
+	Build Build
+	// Target is the installed binary.
+	Target string
+	// CGO is true if cgo is required (or forced) for the binary.
+	CGO      bool
+	Skipped  bool
+	Duration time.Duration
+	// Output is the output of the go command.
+	Output string
+	Err    error
+}
+
+// Builder installs binaries with go install.
+type Builder struct {
+	// Parallel is the maximum number of concurrent builds (1 if not set).
+	Parallel int
+	// CacheFile is the file of the inputs of the last successful builds. Builds are never skipped, if empty.
+	CacheFile string
+	// Env are additional environment variables of the go command.
+	Env []string
+
+	mu sync.Mutex
+}
+
+type cacheEntry struct {
+	Inputs string    `json:"inputs"`
+	Target string    `json:"target"`
+	Built  time.Time `json:"built"`
+}
+
+// Install builds the binaries, and returns with the result of each build (in the same order). The error is not nil,
+// if any of the builds failed.
+func (b *Builder) Install(ctx context.Context, builds []Build, done func(Result)) ([]Result, error) {
+	parallel := b.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	cache := b.loadCache()
+	results := make([]Result, len(builds))
+	limit := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, build := range builds {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			results[i] = b.install(ctx, build, cache)
+			if done != nil {
+				b.mu.Lock()
+				done(results[i])
+				b.mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	var group errs.Group
+	for _, result := range results {
+		if result.Err != nil {
+			group.Add(errs.Errorf("build of %s failed: %v", result.Build, result.Err))
+		}
+	}
+	group.Add(b.saveCache(cache))
+	return results, group.Err()
+}
+
+// install builds one binary, unless its inputs are not changed.
+func (b *Builder) install(ctx context.Context, build Build, cache map[string]cacheEntry) Result {
+	start := time.Now()
+	if build.Platform == (Platform{}) {
+		build.Platform = Host()
+	}
+	result := Result{Build: build}
+	inputs, err := b.inspect(ctx, build, &result)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	b.mu.Lock()
+	previous, found := cache[build.key()]
+	b.mu.Unlock()
+	if found && previous.Inputs == inputs && previous.Target == result.Target {
+		if info, err := os.Stat(result.Target); err == nil && !info.ModTime().After(previous.Built) {
+			result.Skipped = true
+			return result
+		}
+	}
+
+	out := &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "go", "install")
+	cmd.Dir = build.Source
+	cmd.Env = b.env(build, cgoEnv(build, result.CGO))
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err = cmd.Run()
+	result.Output = out.String()
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = errs.Errorf("%v\n%s", err, strings.TrimSpace(result.Output))
+		if result.CGO && build.Platform != Host() && os.Getenv("CC") == "" {
+			result.Err = errs.Errorf("%v\ncgo is enabled for the cross-compilation to %s: set CC to a C cross compiler, or disable cgo "+
+				"(STORJ_UP_LOCAL_BINARY_CGO=off)", result.Err, build.Platform)
+		}
+		return result
+	}
+
+	b.mu.Lock()
+	cache[build.key()] = cacheEntry{Inputs: inputs, Target: result.Target, Built: time.Now()}
+	b.mu.Unlock()
+	return result
+}
+
+// listedPackage is the subset of the `go list -json` output used to calculate the inputs of a build.
+type listedPackage struct {
+	Dir        string
+	ImportPath string
+	Target     string
+	Standard   bool
+	GoFiles    []string
+	CgoFiles   []string
+	CFiles     []string
+	HFiles     []string
+	EmbedFiles []string
+	Module     *struct {
+		Path    string
+		Version string
+		Dir     string
+		GoMod   string
+	}
+}
+
+// inspect lists the dependencies of the build, decides if cgo is required, and returns with the hash of the inputs:
+// the files of the packages outside the module cache, the versions of the modules, the go version and the build
+// environment.
+func (b *Builder) inspect(ctx context.Context, build Build, result *Result) (string, error) {
+	out, err := b.goCommand(ctx, build, "list", "-e", "-deps", "-json=Dir,ImportPath,Target,Standard,GoFiles,CgoFiles,CFiles,HFiles,EmbedFiles,Module", ".")
+	if err != nil {
+		return "", err
+	}
+
+	var packages []listedPackage
+	decoder := json.NewDecoder(strings.NewReader(out))
+	for decoder.More() {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err != nil {
+			return "", errs.Wrap(err)
+		}
+		packages = append(packages, pkg)
+	}
+	if len(packages) == 0 {
+		return "", errs.Errorf("no Go package is found in %s", build.Source)
+	}
+
+	usesCgo := false
+	for _, pkg := range packages {
+		if !pkg.Standard && len(pkg.CgoFiles) > 0 {
+			usesCgo = true
+		}
+	}
+	switch build.CGO {
+	case CGOOn:
+		result.CGO = true
+	case CGOOff:
+		result.CGO = false
+	default:
+		result.CGO = usesCgo
+	}
+	// the main package is the last one
+	result.Target = packages[len(packages)-1].Target
+
+	version, err := b.goCommand(ctx, build, "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	_, _ = fmt.Fprintln(hash, build.Platform, result.CGO, strings.TrimSpace(version), os.Getenv("GOFLAGS"), strings.Join(b.Env, " "))
+	for _, pkg := range packages {
+		if pkg.Standard {
+			continue
+		}
+		_, _ = fmt.Fprintln(hash, pkg.ImportPath)
+		if pkg.Module != nil {
+			_, _ = fmt.Fprintln(hash, pkg.Module.Path, pkg.Module.Version)
+			// modules of the module cache are immutable
+			if pkg.Module.Version != "" && strings.Contains(pkg.Dir, "@"+pkg.Module.Version) {
+				continue
+			}
+			if pkg.Module.GoMod != "" {
+				if err := hashFile(hash, pkg.Module.GoMod); err != nil {
+					return "", err
+				}
+				if err := hashFile(hash, filepath.Join(filepath.Dir(pkg.Module.GoMod), "go.sum")); err != nil {
+					return "", err
+				}
+			}
+		}
+		var files []string
+		for _, list := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.CFiles, pkg.HFiles, pkg.EmbedFiles} {
+			files = append(files, list...)
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			if err := hashFile(hash, filepath.Join(pkg.Dir, file)); err != nil {
+				return "", err
+			}
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// goCommand executes a go command with the cgo enabled environment of the build (to list the cgo files too), and
+// returns with the output.
+func (b *Builder) goCommand(ctx context.Context, build Build, args ...string) (string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = build.Source
+	cmd.Env = b.env(build, "1")
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", errs.Errorf("couldn't execute go %s in %s: %v %s", strings.Join(args, " "), build.Source, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// env returns with the environment of the go command. CGO_ENABLED is not set, if cgo is empty.
+func (b *Builder) env(build Build, cgo string) []string {
+	env := append(os.Environ(), b.Env...)
+	env = append(env, "GOOS="+build.Platform.OS, "GOARCH="+build.Platform.Arch)
+	if cgo != "" {
+		env = append(env, "CGO_ENABLED="+cgo)
+	}
+	return env
+}
+
+// cgoEnv returns with the value of CGO_ENABLED. It's empty (the default of go is used), if the strategy is automatic
+// and the binary is built for the host.
+func cgoEnv(build Build, enabled bool) string {
+	if (build.CGO == "" || build.CGO == CGOAuto) && build.Platform == Host() {
+		return ""
+	}
+	if enabled {
+		return "1"
+	}
+	return "0"
+}
+
+// hashFile writes the name and the content of a file to the hash. Missing files are hashed by name only.
+func hashFile(w io.Writer, path string) error {
+	_, _ = fmt.Fprintln(w, path)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	defer func() { _ = f.Close() }()
+	_, err = io.Copy(w, f)
+	return errs.Wrap(err)
+}
+
+func (b *Builder) loadCache() map[string]cacheEntry {
+	cache := map[string]cacheEntry{}
+	if b.CacheFile == "" {
+		return cache
+	}
+	raw, err := os.ReadFile(b.CacheFile)
+	if err != nil {
+		return cache
+	}
+	// an invalid cache file means that everything should be rebuilt
+	_ = json.Unmarshal(raw, &cache)
+	return cache
+}
+
+func (b *Builder) saveCache(cache map[string]cacheEntry) error {
+	if b.CacheFile == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(b.CacheFile, raw, 0o644)
+}
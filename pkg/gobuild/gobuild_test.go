@@ -0,0 +1,131 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func testModule(t *testing.T) string {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib\"\n")
+	writeFile(t, filepath.Join(dir, "cgo", "cgo.go"), "package cgo\n\n// int one() { return 1; }\nimport \"C\"\n\nfunc One() int { return int(C.one()) }\n")
+	writeFile(t, filepath.Join(dir, "cmd", "a", "main.go"), "package main\n\nimport \"example.com/app/lib\"\n\nfunc main() { println(lib.Name) }\n")
+	writeFile(t, filepath.Join(dir, "cmd", "b", "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(dir, "cmd", "c", "main.go"), "package main\n\nimport \"example.com/app/cgo\"\n\nfunc main() { println(cgo.One()) }\n")
+	return dir
+}
+
+func TestInstall(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires the go command")
+	}
+	ctx := context.Background()
+	dir := testModule(t)
+	bin := t.TempDir()
+	builder := &Builder{
+		Parallel:  2,
+		CacheFile: filepath.Join(t.TempDir(), CacheFileName),
+		Env:       []string{"GOBIN=" + bin},
+	}
+	builds := []Build{
+		{Source: filepath.Join(dir, "cmd", "a")},
+		{Source: filepath.Join(dir, "cmd", "b")},
+	}
+	skipped := func(results []Result) (res []bool) {
+		for _, r := range results {
+			res = append(res, r.Skipped)
+		}
+		return res
+	}
+
+	results, err := builder.Install(ctx, builds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, false}, skipped(results))
+	require.Equal(t, filepath.Join(bin, "a"), results[0].Target)
+	require.FileExists(t, filepath.Join(bin, "a"))
+	require.FileExists(t, filepath.Join(bin, "b"))
+
+	// nothing is changed
+	var done []string
+	results, err = builder.Install(ctx, builds, func(r Result) { done = append(done, r.Build.Source) })
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true}, skipped(results))
+	require.ElementsMatch(t, []string{builds[0].Source, builds[1].Source}, done)
+
+	// only the dependent binary is rebuilt
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib2\"\n")
+	results, err = builder.Install(ctx, builds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true}, skipped(results))
+
+	// removed binary is rebuilt
+	require.NoError(t, os.Remove(filepath.Join(bin, "b")))
+	results, err = builder.Install(ctx, builds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false}, skipped(results))
+
+	// build errors are returned with the output, and the failed build is retried next time
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name =\n")
+	results, err = builder.Install(ctx, builds, nil)
+	require.Error(t, err)
+	require.Error(t, results[0].Err)
+	require.Contains(t, results[0].Output, "lib.go")
+	require.NoError(t, results[1].Err)
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib3\"\n")
+	results, err = builder.Install(ctx, builds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []bool{false, true}, skipped(results))
+
+	// reverting to the inputs of the last successful build doesn't require a build
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name =\n")
+	_, err = builder.Install(ctx, builds, nil)
+	require.Error(t, err)
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib3\"\n")
+	results, err = builder.Install(ctx, builds, nil)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true}, skipped(results))
+}
+
+func TestCGO(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires the go command")
+	}
+	ctx := context.Background()
+	dir := testModule(t)
+	builder := &Builder{}
+	cross := Platform{OS: "linux", Arch: "arm64"}
+	if Host() == cross {
+		cross.Arch = "amd64"
+	}
+
+	for _, tc := range []struct {
+		build    Build
+		expected bool
+		env      string
+	}{
+		{build: Build{Source: filepath.Join(dir, "cmd", "a"), Platform: cross}, expected: false, env: "0"},
+		{build: Build{Source: filepath.Join(dir, "cmd", "c"), Platform: cross}, expected: true, env: "1"},
+		{build: Build{Source: filepath.Join(dir, "cmd", "c"), Platform: cross, CGO: CGOOff}, expected: false, env: "0"},
+		{build: Build{Source: filepath.Join(dir, "cmd", "a"), Platform: cross, CGO: CGOOn}, expected: true, env: "1"},
+		{build: Build{Source: filepath.Join(dir, "cmd", "c"), Platform: Host()}, expected: true, env: ""},
+	} {
+		result := Result{Build: tc.build}
+		_, err := builder.inspect(ctx, tc.build, &result)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, result.CGO, tc.build)
+		require.Equal(t, tc.env, cgoEnv(tc.build, result.CGO), tc.build)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package ide generates remote debug configurations for VS Code and GoLand.
+package ide
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common/atomicfile"
+)
+
+// NamePrefix is the prefix of the names of the generated configurations. Configurations with other names are not touched.
+const NamePrefix = "storj-up: "
+
+// goLandPrefix is the file name prefix of the generated GoLand run configurations.
+const goLandPrefix = "storj-up-debug-"
+
+// Target is one service instance, running with a headless Delve server.
+type Target struct {
+	Name string
+	Host string
+	Port int
+	// PathMappings are required if the binary is built from a different directory (eg. inside a container). They are
+	// written only to the VS Code configurations: the Go Remote configuration of GoLand has no option for them.
+	PathMappings []PathMapping
+}
+
+// PathMapping maps the local source directory to the directory used by the build.
+type PathMapping struct {
+	Local  string
+	Remote string
+}
+
+// ContainerSourceDirs are the locations of the checked out projects in the storj-up docker images (see storj.Dockerfile and edge.Dockerfile).
+var ContainerSourceDirs = map[string]string{
+	"storj":      "/var/lib/storj/storj",
+	"gateway-mt": "/var/lib/storj/gateway-mt",
+}
+
+// Project returns with the project (storj or gateway-mt) of a service. Returns with empty string for unknown services.
+func Project(service string) string {
+	switch {
+	case strings.HasPrefix(service, "satellite"), service == "storagenode", service == "uplink",
+		service == "versioncontrol", service == "multinode":
+		return "storj"
+	case service == "gateway-mt", service == "authservice", service == "linksharing":
+		return "gateway-mt"
+	}
+	return ""
+}
+
+// Write updates the generated configurations in .vscode/launch.json and .run/ of the directory.
+// Nothing is created if there are no targets, and nothing has been generated earlier.
+func Write(dir string, targets []Target) error {
+	slices.SortFunc(targets, func(a, b Target) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return errs.Combine(writeVSCode(dir, targets), writeGoLand(dir, targets))
+}
+
+func writeVSCode(dir string, targets []Target) error {
+	launchFile := filepath.Join(dir, ".vscode", "launch.json")
+	launch := map[string]any{}
+	raw, err := os.ReadFile(launchFile)
+	switch {
+	case os.IsNotExist(err):
+		if len(targets) == 0 {
+			return nil
+		}
+		launch["version"] = "0.2.0"
+	case err != nil:
+		return errs.Wrap(err)
+	default:
+		if err := json.Unmarshal(raw, &launch); err != nil {
+			return errs.Errorf("couldn't parse %s (comments are not supported): %v", launchFile, err)
+		}
+	}
+
+	var configurations []any
+	if existing, ok := launch["configurations"].([]any); ok {
+		for _, c := range existing {
+			if cfg, ok := c.(map[string]any); ok {
+				if name, ok := cfg["name"].(string); ok && strings.HasPrefix(name, NamePrefix) {
+					continue
+				}
+			}
+			configurations = append(configurations, c)
+		}
+	}
+	for _, t := range targets {
+		cfg := map[string]any{
+			"name":    NamePrefix + t.Name,
+			"type":    "go",
+			"request": "attach",
+			"mode":    "remote",
+			"host":    t.Host,
+			"port":    t.Port,
+		}
+		if len(t.PathMappings) > 0 {
+			var substitutePath []map[string]string
+			for _, m := range t.PathMappings {
+				substitutePath = append(substitutePath, map[string]string{"from": m.Local, "to": m.Remote})
+			}
+			cfg["substitutePath"] = substitutePath
+		}
+		configurations = append(configurations, cfg)
+	}
+	if configurations == nil {
+		configurations = []any{}
+	}
+	launch["configurations"] = configurations
+
+	out, err := json.MarshalIndent(launch, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(launchFile), 0755); err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(launchFile, append(out, '\n'), 0644)
+}
+
+// writeGoLand writes one Go Remote run configuration for each target. Path mappings are not supported by GoLand run
+// configurations, the sources of the binaries built inside the containers are not found automatically.
+func writeGoLand(dir string, targets []Target) error {
+	runDir := filepath.Join(dir, ".run")
+	previous, err := filepath.Glob(filepath.Join(runDir, goLandPrefix+"*.run.xml"))
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	if len(targets) > 0 {
+		if err := os.MkdirAll(runDir, 0755); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+
+	var generated []string
+	for _, t := range targets {
+		fileName := filepath.Join(runDir, goLandPrefix+t.Name+".run.xml")
+		var out bytes.Buffer
+		out.WriteString(`<component name="ProjectRunConfigurationManager">` + "\n")
+		out.WriteString(`    <configuration default="false" name="` + escape(NamePrefix+t.Name) + `" type="GoRemoteDebugConfigurationType" factoryName="Go Remote">` + "\n")
+		out.WriteString(`        <option name="disconnectOption" value="LEAVE"/>` + "\n")
+		out.WriteString(`        <option name="host" value="` + escape(t.Host) + `"/>` + "\n")
+		out.WriteString(`        <option name="port" value="` + strconv.Itoa(t.Port) + `"/>` + "\n")
+		out.WriteString(`        <method v="2"/>` + "\n")
+		out.WriteString(`    </configuration>` + "\n")
+		out.WriteString(`</component>` + "\n")
+		if err := atomicfile.WriteFile(fileName, out.Bytes(), 0644); err != nil {
+			return err
+		}
+		generated = append(generated, fileName)
+	}
+
+	for _, p := range previous {
+		if !slices.Contains(generated, p) {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return errs.Wrap(err)
+			}
+		}
+	}
+	return nil
+}
+
+func escape(s string) string {
+	var out strings.Builder
+	_ = xml.EscapeText(&out, []byte(s))
+	return out.String()
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package ide
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+
+	// nothing is generated without debugged services
+	require.NoError(t, Write(dir, nil))
+	require.NoDirExists(t, filepath.Join(dir, ".vscode"))
+	require.NoDirExists(t, filepath.Join(dir, ".run"))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".vscode"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".vscode", "launch.json"), []byte(`{"version":"0.2.0","configurations":[{"name":"mine"}]}`), 0644))
+
+	require.NoError(t, Write(dir, []Target{
+		{
+			Name:         "storagenode2",
+			Host:         "localhost",
+			Port:         30018,
+			PathMappings: []PathMapping{{Local: "/home/dev/storj", Remote: "/var/lib/storj/storj"}},
+		},
+		{Name: "satellite-api1", Host: "localhost", Port: 10008},
+	}))
+
+	launch := readLaunch(t, dir)
+	require.Len(t, launch.Configurations, 3)
+	require.Equal(t, "mine", launch.Configurations[0].Name)
+	require.Equal(t, NamePrefix+"satellite-api1", launch.Configurations[1].Name)
+	require.Equal(t, 10008, launch.Configurations[1].Port)
+	require.Equal(t, []map[string]string{{"from": "/home/dev/storj", "to": "/var/lib/storj/storj"}}, launch.Configurations[2].SubstitutePath)
+	require.FileExists(t, filepath.Join(dir, ".run", "storj-up-debug-storagenode2.run.xml"))
+
+	// generated configurations are replaced, others are kept
+	require.NoError(t, Write(dir, []Target{{Name: "satellite-api1", Host: "localhost", Port: 10008}}))
+	launch = readLaunch(t, dir)
+	require.Len(t, launch.Configurations, 2)
+	require.NoFileExists(t, filepath.Join(dir, ".run", "storj-up-debug-storagenode2.run.xml"))
+	require.FileExists(t, filepath.Join(dir, ".run", "storj-up-debug-satellite-api1.run.xml"))
+}
+
+type launchFile struct {
+	Configurations []struct {
+		Name           string              `json:"name"`
+		Port           int                 `json:"port"`
+		SubstitutePath []map[string]string `json:"substitutePath"`
+	} `json:"configurations"`
+}
+
+func readLaunch(t *testing.T, dir string) launchFile {
+	raw, err := os.ReadFile(filepath.Join(dir, ".vscode", "launch.json"))
+	require.NoError(t, err)
+	var launch launchFile
+	require.NoError(t, json.Unmarshal(raw, &launch))
+	return launch
+}
@@ -0,0 +1,237 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package matrix runs scenarios against the combinations of satellite, storagenode and uplink versions, to check the
+// compatibility of the versions before releases.
+//
+// The clusters use fixed ports on the host (see package up), so the combinations are executed sequentially (there
+// are no port offsets). Each cluster is destroyed before the next combination is started.
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/release"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/up"
+)
+
+// Recipes are the recipes of the generated clusters.
+var Recipes = []string{"db", "minimal", "uplink"}
+
+// storjRepository is the image repository of the satellite, storagenode and uplink services.
+const storjRepository = "img.dev.storj.io/storjup/storj"
+
+// Combination is the versions of one cluster. A version is an image tag (like 1.125.2), or a release of the release
+// manifest with v prefix (like v1.125). Empty version means the version of the recipes.
+type Combination struct {
+	Satellite   string
+	Storagenode string
+	Uplink      string
+}
+
+// String returns with the name of the combination, used as the name of the test case.
+func (c Combination) String() string {
+	return fmt.Sprintf("satellite=%s,storagenode=%s,uplink=%s", orDefault(c.Satellite), orDefault(c.Storagenode), orDefault(c.Uplink))
+}
+
+// dirName returns with a directory name for the cluster of the combination.
+func (c Combination) dirName() string {
+	return strings.NewReplacer("=", "-", ",", "_").Replace(c.String())
+}
+
+// version returns with the version of a service. Services of the storj image, which are not storagenodes or uplinks,
+// are satellite services (like satellite-api, satellite-core or versioncontrol).
+func (c Combination) version(service string) string {
+	switch service {
+	case "storagenode":
+		return c.Storagenode
+	case "uplink":
+		return c.Uplink
+	default:
+		return c.Satellite
+	}
+}
+
+// Combinations returns with all the combinations of the versions. Empty lists are replaced by the version of the
+// recipes.
+func Combinations(satellites, storagenodes, uplinks []string) []Combination {
+	var res []Combination
+	for _, satellite := range orDefaultVersion(satellites) {
+		for _, storagenode := range orDefaultVersion(storagenodes) {
+			for _, uplink := range orDefaultVersion(uplinks) {
+				res = append(res, Combination{Satellite: satellite, Storagenode: storagenode, Uplink: uplink})
+			}
+		}
+	}
+	return res
+}
+
+// Apply changes the images of the services to the versions of the combination.
+func (c Combination) Apply(rt runtime.Runtime, manifest release.Manifest) error {
+	for _, s := range rt.GetServices() {
+		version := c.version(s.ID().Name)
+		if version == "" {
+			continue
+		}
+		var resolveErr error
+		err := s.ChangeImage(func(image string) string {
+			repository, _ := release.Split(image)
+			if repository != storjRepository {
+				return image
+			}
+			resolved, err := resolve(manifest, image, version)
+			if err != nil {
+				resolveErr = err
+				return image
+			}
+			return resolved
+		})
+		if err != nil {
+			return err
+		}
+		if resolveErr != nil {
+			return resolveErr
+		}
+	}
+	return nil
+}
+
+// resolve returns with the image of a version, which is a release of the manifest, or an image tag.
+func resolve(manifest release.Manifest, image string, version string) (string, error) {
+	repository, _ := release.Split(image)
+	if strings.HasPrefix(version, "v") {
+		r, err := manifest.Get(version)
+		if err != nil {
+			return "", err
+		}
+		pinned, found := r.Image(image)
+		if !found {
+			return "", errs.Errorf("release %s doesn't pin %s", r.Name, repository)
+		}
+		return pinned, nil
+	}
+	return repository + ":" + version, nil
+}
+
+// Options are the parameters of a matrix run.
+type Options struct {
+	// Dir is the parent directory of the clusters. If empty, temporary directories are used, which are removed at
+	// the end. Otherwise, the directories are kept (to check the logs and configuration of the failed clusters).
+	Dir string
+	// Scenario is the name of the scenario to execute (see Scenarios).
+	Scenario string
+	// Manifest is the release manifest file, which extends the embedded one.
+	Manifest string
+	// Timeout is the time limit of a combination, including the start of the cluster.
+	Timeout time.Duration
+}
+
+// Result is the outcome of a combination.
+type Result struct {
+	Combination Combination
+	Scenario    string
+	Duration    time.Duration
+	// Err is nil, if the scenario is passed.
+	Err error
+}
+
+// Passed returns true if the scenario is passed.
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Run executes the scenario with each combination: it creates and starts a cluster, waits until it's healthy, runs
+// the scenario, and destroys the cluster. progress is called before (with nil result) and after each combination.
+func Run(ctx context.Context, options Options, combinations []Combination, progress func(Combination, *Result)) ([]Result, error) {
+	scenario, found := Scenarios[options.Scenario]
+	if !found {
+		return nil, errs.Errorf("unknown scenario %s (known scenarios: %s)", options.Scenario, strings.Join(ScenarioNames(), ", "))
+	}
+	manifest, err := release.Load(options.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, combination := range combinations {
+		if progress != nil {
+			progress(combination, nil)
+		}
+		start := time.Now()
+		err := runCombination(ctx, options, manifest, combination, scenario)
+		result := Result{
+			Combination: combination,
+			Scenario:    options.Scenario,
+			Duration:    time.Since(start),
+			Err:         err,
+		}
+		results = append(results, result)
+		if progress != nil {
+			progress(combination, &result)
+		}
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+func runCombination(ctx context.Context, options Options, manifest release.Manifest, combination Combination, scenario Scenario) (err error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	dir := ""
+	if options.Dir != "" {
+		dir = filepath.Join(options.Dir, combination.dirName())
+		if err := os.RemoveAll(dir); err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	cluster, err := up.New(ctx, up.Options{
+		Dir:     dir,
+		Recipes: Recipes,
+		Customize: func(rt runtime.Runtime) error {
+			return combination.Apply(rt, manifest)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// the context can be already canceled by the timeout
+		destroyCtx, cancel := context.WithTimeout(context.Background(), up.DestroyTimeout)
+		defer cancel()
+		err = errs.Combine(err, cluster.Destroy(destroyCtx))
+	}()
+
+	if err := cluster.Start(ctx); err != nil {
+		return err
+	}
+	if err := cluster.WaitHealthy(ctx); err != nil {
+		return err
+	}
+	return scenario(ctx, cluster)
+}
+
+func orDefault(version string) string {
+	if version == "" {
+		return "default"
+	}
+	return version
+}
+
+func orDefaultVersion(versions []string) []string {
+	if len(versions) == 0 {
+		return []string{""}
+	}
+	return versions
+}
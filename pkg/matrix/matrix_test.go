@@ -0,0 +1,113 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/docker"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/release"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/up"
+)
+
+func TestCombinations(t *testing.T) {
+	combinations := Combinations([]string{"1.131.1", "v1.125"}, []string{"1.120.0", "1.125.2"}, nil)
+	require.Len(t, combinations, 4)
+	require.Equal(t, Combination{Satellite: "1.131.1", Storagenode: "1.120.0"}, combinations[0])
+	require.Equal(t, "satellite=v1.125,storagenode=1.125.2,uplink=default", combinations[3].String())
+	require.Equal(t, "satellite-v1.125_storagenode-1.125.2_uplink-default", combinations[3].dirName())
+}
+
+func TestApply(t *testing.T) {
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	rt, err := compose.NewCompose(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, runtime.ApplyRecipes(st, rt, Recipes, 0))
+	manifest, err := release.Load("")
+	require.NoError(t, err)
+
+	require.NoError(t, Combination{Satellite: "1.131.1", Uplink: "v1.125"}.Apply(rt, manifest))
+	images := map[string]string{}
+	for _, s := range rt.GetServices() {
+		_ = s.ChangeImage(func(image string) string {
+			images[s.ID().Name] = image
+			return image
+		})
+	}
+	require.Equal(t, storjRepository+":1.131.1", images["satellite-api"])
+	require.Equal(t, storjRepository+":1.125.2", images["storagenode"])
+	require.Equal(t, storjRepository+":1.125.2", images["uplink"])
+	require.False(t, strings.HasPrefix(images["redis"], storjRepository))
+
+	require.Error(t, Combination{Storagenode: "v0.1"}.Apply(rt, manifest))
+}
+
+func TestRunDestroys(t *testing.T) {
+	t.Setenv("COMPOSE_PROJECT_NAME", "")
+	t.Setenv(docker.CLIEnv, "")
+	// the containers can't be created with the Engine API
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/containers/json":
+			_, _ = w.Write([]byte("[]"))
+		case "/containers/create":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"message":"port is already allocated"}`))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(server.URL, "http://"))
+	bin := t.TempDir()
+	log := filepath.Join(bin, "docker.log")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte("#!/bin/sh\necho \"$@\" >> "+log+"\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	Scenarios["noop"] = func(ctx context.Context, cluster *up.Cluster) error { return nil }
+	defer delete(Scenarios, "noop")
+	results, err := Run(t.Context(), Options{Dir: t.TempDir(), Scenario: "noop"}, Combinations([]string{"1.125.2", "v1.125"}, nil, nil), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		require.ErrorContains(t, r.Err, "port is already allocated")
+	}
+	// each cluster is destroyed, even if it's started partially
+	commands, err := os.ReadFile(log)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("compose -p storj-up down --volumes --remove-orphans\n", 2), string(commands))
+}
+
+func TestReports(t *testing.T) {
+	results := []Result{
+		{Combination: Combination{Satellite: "1.131.1"}, Scenario: "upload-download", Duration: 90 * time.Second},
+		{Combination: Combination{Satellite: "1.125.2"}, Scenario: "upload-download", Duration: time.Minute, Err: errs.Errorf("upload failed\ndetails")},
+	}
+
+	table := &bytes.Buffer{}
+	require.NoError(t, WriteTable(table, results))
+	require.Contains(t, table.String(), "PASS")
+	require.Contains(t, table.String(), "FAIL")
+
+	junit := &bytes.Buffer{}
+	require.NoError(t, WriteJUnit(junit, results))
+	require.Contains(t, junit.String(), `<testsuites tests="2" failures="1" time="150.000">`)
+	require.Contains(t, junit.String(), `<testcase name="satellite=1.131.1,storagenode=default,uplink=default" classname="upload-download" time="90.000"></testcase>`)
+	require.Contains(t, junit.String(), `<failure message="upload failed">upload failed&#xA;details</failure>`)
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package matrix
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// WriteTable prints the results as a pass/fail table.
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "SATELLITE\tSTORAGENODE\tUPLINK\tSCENARIO\tRESULT\tDURATION")
+	for _, r := range results {
+		result := "PASS"
+		if !r.Passed() {
+			result = "FAIL"
+		}
+		c := r.Combination
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", orDefault(c.Satellite), orDefault(c.Storagenode), orDefault(c.Uplink),
+			r.Scenario, result, r.Duration.Round(time.Second))
+	}
+	return errs.Wrap(tw.Flush())
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as a JUnit XML report. Each scenario is a test suite, and each combination is a test
+// case.
+func WriteJUnit(w io.Writer, results []Result) error {
+	report := junitSuites{}
+	var total time.Duration
+	suites := map[string]int{}
+	durations := map[string]time.Duration{}
+	for _, r := range results {
+		ix, found := suites[r.Scenario]
+		if !found {
+			ix = len(report.Suites)
+			suites[r.Scenario] = ix
+			report.Suites = append(report.Suites, junitSuite{Name: "storj-up matrix " + r.Scenario})
+		}
+		suite := &report.Suites[ix]
+		testCase := junitCase{
+			Name:      r.Combination.String(),
+			ClassName: r.Scenario,
+			Time:      seconds(r.Duration),
+		}
+		if !r.Passed() {
+			message, _, _ := strings.Cut(r.Err.Error(), "\n")
+			testCase.Failure = &junitFailure{Message: message, Text: r.Err.Error()}
+			suite.Failures++
+			report.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		report.Tests++
+		durations[r.Scenario] += r.Duration
+		total += r.Duration
+	}
+	for scenario, ix := range suites {
+		report.Suites[ix].Time = seconds(durations[scenario])
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errs.Wrap(err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return errs.Wrap(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return errs.Wrap(err)
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
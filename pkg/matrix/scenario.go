@@ -0,0 +1,85 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package matrix
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/up"
+)
+
+// Scenario is executed with a started and healthy cluster. The error is the failure of the scenario.
+type Scenario func(ctx context.Context, cluster *up.Cluster) error
+
+// Scenarios are the built-in scenarios, by name.
+var Scenarios = map[string]Scenario{
+	"upload-download": uploadDownload,
+}
+
+// ScenarioNames returns with the names of the built-in scenarios.
+func ScenarioNames() []string {
+	var names []string
+	for name := range Scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uploadDownload creates a bucket with the uplink of the uplink service, uploads a random file (big enough to be stored
+// on the storagenodes, not inline), downloads it, and compares the checksums.
+func uploadDownload(ctx context.Context, cluster *up.Cluster) error {
+	access, err := containerAccess(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	const (
+		bucket   = "sj://matrix"
+		object   = bucket + "/data"
+		upload   = "/tmp/matrix-upload"
+		download = "/tmp/matrix-download"
+	)
+	steps := [][]string{
+		{"sh", "-c", "head -c 1048576 /dev/urandom > " + upload},
+		{"uplink", "mb", "--access", access, bucket},
+		{"uplink", "cp", "--access", access, upload, object},
+		{"uplink", "cp", "--access", access, object, download},
+	}
+	for _, step := range steps {
+		if _, err := cluster.Exec(ctx, "uplink", step...); err != nil {
+			return err
+		}
+	}
+	out, err := cluster.Exec(ctx, "uplink", "sha256sum", upload, download)
+	if err != nil {
+		return err
+	}
+	var sums []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		sum, _, _ := strings.Cut(line, " ")
+		sums = append(sums, sum)
+	}
+	if len(sums) != 2 || sums[0] != sums[1] {
+		return errs.Errorf("downloaded data is different from the uploaded data:\n%s", out)
+	}
+	return nil
+}
+
+// containerAccess creates the test user, and returns with an access grant which can be used inside the containers.
+func containerAccess(ctx context.Context, cluster *up.Cluster) (string, error) {
+	creds, err := cluster.Credentials(ctx)
+	if err != nil {
+		return "", err
+	}
+	sat := runtime.NewServiceInstance("satellite-api", 0)
+	rt := cluster.Runtime()
+	address := fmt.Sprintf("%s:%d", rt.GetHost(sat, "internal"), rt.GetPort(sat, "public").Internal)
+	return up.RelocateGrant(creds.Grant, address)
+}
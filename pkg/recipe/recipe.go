@@ -31,15 +31,37 @@ type Service struct {
 	Environment   map[string]string
 	Config        map[string]string
 	Persistence   []string
-	Port          []PortDefinition
-	File          []File
-	Folder        []Folder
+	// PersistenceType is the default type of the persistence (bind, volume or tmpfs). Empty means bind.
+	PersistenceType string
+	Port            []PortDefinition
+	File            []File
+	Folder          []Folder
 
 	// port forward outside->inside
 	PortForwards map[int]int
 
 	// mount rules outside --> inside
 	Mounts map[string]string
+
+	// Build describes how the image of the service is built from source (used by `storj-up build`).
+	Build *Build
+}
+
+// Build is the definition of an image, built by a dedicated builder service of the compose file. Services with the same
+// image share the builder.
+type Build struct {
+	// Image is the name of the built image (like storj).
+	Image string
+	// Name is the name of the builder service. Default is app-<image>.
+	Name string
+	// Dockerfile is the name of an embedded Dockerfile (like storj.Dockerfile), or the path of a custom one.
+	Dockerfile string
+	// Context is the directory of the build context. Default is the directory of the compose file.
+	Context string
+	// Target is the build stage to build. Default is the last one.
+	Target string
+	// Args are the additional build arguments.
+	Args map[string]string
 }
 
 // Modification represents a transformation applied to one or more services.
@@ -0,0 +1,184 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package release pins the images of the services to known-compatible sets of the Storj releases.
+package release
+
+import (
+	_ "embed"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+//go:embed releases.yaml
+var embedded []byte
+
+// Release is a known-compatible set of images.
+type Release struct {
+	// Name is the version of the release, like v1.130.
+	Name string
+	// Images are the tags of the image repositories (like img.dev.storj.io/storjup/storj: 1.130.2).
+	Images map[string]string
+	// UpgradeFrom is the oldest release whose databases can be migrated by this release. Empty means any.
+	UpgradeFrom string
+}
+
+// Manifest is the list of the known releases, ordered by version.
+type Manifest struct {
+	Releases []Release
+}
+
+// Change is a changed image of a service.
+type Change struct {
+	Service string
+	From    string
+	To      string
+}
+
+// Load returns with the embedded manifest, extended (or overridden) by the releases of the file, if it's not empty.
+func Load(file string) (Manifest, error) {
+	var m Manifest
+	if err := yaml.Unmarshal(embedded, &m); err != nil {
+		return m, errs.Errorf("invalid embedded release manifest: %v", err)
+	}
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return m, errs.Wrap(err)
+		}
+		var custom Manifest
+		if err := yaml.Unmarshal(raw, &custom); err != nil {
+			return m, errs.Errorf("invalid release manifest %s: %v", file, err)
+		}
+		for _, r := range custom.Releases {
+			m.add(r)
+		}
+	}
+	for i, r := range m.Releases {
+		m.Releases[i].Name = Normalize(r.Name)
+		if !semver.IsValid(m.Releases[i].Name) {
+			return m, errs.Errorf("invalid release name: %s", r.Name)
+		}
+	}
+	sort.SliceStable(m.Releases, func(i, j int) bool {
+		return semver.Compare(m.Releases[i].Name, m.Releases[j].Name) < 0
+	})
+	return m, nil
+}
+
+// add adds the release, or replaces the release with the same name.
+func (m *Manifest) add(r Release) {
+	for i, existing := range m.Releases {
+		if Normalize(existing.Name) == Normalize(r.Name) {
+			m.Releases[i] = r
+			return
+		}
+	}
+	m.Releases = append(m.Releases, r)
+}
+
+// Normalize returns with the release name with the v prefix (1.130 -> v1.130).
+func Normalize(name string) string {
+	if !strings.HasPrefix(name, "v") {
+		return "v" + name
+	}
+	return name
+}
+
+// Get returns with the release by name.
+func (m Manifest) Get(name string) (Release, error) {
+	var names []string
+	for _, r := range m.Releases {
+		if r.Name == Normalize(name) {
+			return r, nil
+		}
+		names = append(names, r.Name)
+	}
+	return Release{}, errs.Errorf("unknown release %s (known releases: %s)", name, strings.Join(names, ", "))
+}
+
+// Detect returns with the newest release which pins the same tags for the given images. Images of unknown
+// repositories are ignored.
+func (m Manifest) Detect(images []string) (Release, bool) {
+	for i := len(m.Releases) - 1; i >= 0; i-- {
+		matched, conflict := false, false
+		for _, image := range images {
+			repository, tag := Split(image)
+			if pinned, found := m.Releases[i].Images[repository]; found {
+				if pinned == tag {
+					matched = true
+				} else {
+					conflict = true
+				}
+			}
+		}
+		if matched && !conflict {
+			return m.Releases[i], true
+		}
+	}
+	return Release{}, false
+}
+
+// Image returns with the pinned image for the repository of the image.
+func (r Release) Image(image string) (string, bool) {
+	repository, _ := Split(image)
+	tag, found := r.Images[repository]
+	if !found {
+		return "", false
+	}
+	return repository + ":" + tag, true
+}
+
+// Apply changes the images of the services to the images of the release, and returns with the changes. Services of
+// other images (like the images built from source) are not changed.
+func (r Release) Apply(rt runtime.Runtime) ([]Change, error) {
+	var changes []Change
+	for _, s := range rt.GetServices() {
+		err := s.ChangeImage(func(image string) string {
+			pinned, found := r.Image(image)
+			if !found || pinned == image {
+				return image
+			}
+			changes = append(changes, Change{Service: s.ID().String(), From: image, To: pinned})
+			return pinned
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Service < changes[j].Service
+	})
+	return changes, nil
+}
+
+// Split returns with the repository and the tag of the image. The digest is dropped.
+func Split(image string) (repository string, tag string) {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// MigrationWarning returns with a warning if the databases of the from release can't be migrated to the to release
+// directly (the required migrations are skipped), or if it's a downgrade. It's empty, if the upgrade is safe.
+func MigrationWarning(from Release, to Release) string {
+	switch {
+	case semver.Compare(to.Name, from.Name) < 0:
+		return "downgrade from " + from.Name + " to " + to.Name + ": the database migrations of " + from.Name +
+			" can't be reverted, recreate the databases with `storj-up clean`"
+	case to.UpgradeFrom != "" && semver.Compare(from.Name, Normalize(to.UpgradeFrom)) < 0:
+		return "the database migrations between " + from.Name + " and " + Normalize(to.UpgradeFrom) + " are skipped: " +
+			to.Name + " can migrate the databases of " + Normalize(to.UpgradeFrom) + " or newer. Upgrade to " +
+			Normalize(to.UpgradeFrom) + " first, or recreate the databases with `storj-up clean`"
+	}
+	return ""
+}
@@ -0,0 +1,157 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// testManifest contains hypothetical releases, the tags are not verified.
+const testManifest = `releases:
+  - name: "1.131"
+    upgradefrom: v1.130
+    images:
+      img.dev.storj.io/storjup/storj: 1.131.1
+      img.dev.storj.io/storjup/edge: 1.99.0
+  - name: v1.130
+    images:
+      img.dev.storj.io/storjup/storj: 1.130.3
+      img.dev.storj.io/storjup/edge: 1.99.0
+`
+
+func TestEmbeddedMatchesRecipes(t *testing.T) {
+	m, err := Load("")
+	require.NoError(t, err)
+	require.NotEmpty(t, m.Releases)
+
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	var images []string
+	for _, r := range st {
+		for _, s := range r.Add {
+			images = append(images, s.Image)
+		}
+	}
+	// the recipes use the images of one release, all the pinned images of the release
+	current, found := m.Detect(images)
+	require.True(t, found, "recipes should use the images of a release")
+	for _, r := range st {
+		for _, s := range r.Add {
+			if pinned, found := current.Image(s.Image); found {
+				require.Equal(t, pinned, s.Image, "%s should use the images of %s", s.Name, current.Name)
+			}
+		}
+	}
+	for repository := range current.Images {
+		require.True(t, slices.ContainsFunc(images, func(image string) bool {
+			r, _ := Split(image)
+			return r == repository
+		}), "%s of %s is not used by the recipes", repository, current.Name)
+	}
+
+	// the releases can be upgraded step by step
+	for i, r := range m.Releases {
+		if r.UpgradeFrom == "" {
+			continue
+		}
+		from, err := m.Get(r.UpgradeFrom)
+		require.NoError(t, err)
+		require.Empty(t, MigrationWarning(from, r))
+		require.Empty(t, MigrationWarning(m.Releases[i-1], r))
+	}
+}
+
+func TestEmbeddedUpgrade(t *testing.T) {
+	m, err := Load("")
+	require.NoError(t, err)
+	current, found := m.Detect([]string{"img.dev.storj.io/storjup/storj:1.125.2", "img.dev.storj.io/storjup/edge:1.97.0"})
+	require.True(t, found)
+	require.Equal(t, "v1.125", current.Name)
+
+	// newer releases are available only from manifest files
+	_, err = m.Get("v1.131")
+	require.Error(t, err)
+	file := filepath.Join(t.TempDir(), "releases.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testManifest), 0o644))
+	m, err = Load(file)
+	require.NoError(t, err)
+	target, err := m.Get("v1.130")
+	require.NoError(t, err)
+	require.Empty(t, MigrationWarning(current, target))
+	target, err = m.Get("v1.131")
+	require.NoError(t, err)
+	require.Contains(t, MigrationWarning(current, target), "skipped")
+}
+
+func TestUpgrade(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "releases.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(testManifest), 0o644))
+	m, err := Load(file)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1.125", "v1.130", "v1.131"}, []string{m.Releases[0].Name, m.Releases[1].Name, m.Releases[2].Name})
+
+	_, err = m.Get("v1.129")
+	require.Error(t, err)
+	target, err := m.Get("1.131")
+	require.NoError(t, err)
+
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	rt, err := compose.NewCompose(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, runtime.ApplyRecipes(st, rt, []string{"minimal", "edge", "db"}, 0))
+
+	current, found := m.Detect([]string{"img.dev.storj.io/storjup/storj:1.125.2", "redis:6.0.9"})
+	require.True(t, found)
+	require.Equal(t, "v1.125", current.Name)
+
+	changes, err := target.Apply(rt)
+	require.NoError(t, err)
+	require.Contains(t, changes, Change{
+		Service: "satellite-api/0",
+		From:    "img.dev.storj.io/storjup/storj:1.125.2",
+		To:      "img.dev.storj.io/storjup/storj:1.131.1",
+	})
+	require.Contains(t, changes, Change{
+		Service: "gateway-mt/0",
+		From:    "img.dev.storj.io/storjup/edge:1.97.0",
+		To:      "img.dev.storj.io/storjup/edge:1.99.0",
+	})
+	for _, c := range changes {
+		require.NotEqual(t, "redis/0", c.Service)
+	}
+	require.Contains(t, MigrationWarning(current, target), "skipped")
+
+	// nothing is changed again
+	changes, err = target.Apply(rt)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	previous, err := m.Get("v1.130")
+	require.NoError(t, err)
+	require.Empty(t, MigrationWarning(previous, target))
+	require.Contains(t, MigrationWarning(target, previous), "downgrade")
+}
+
+func TestSplit(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"redis:6.0.9":                           {"redis", "6.0.9"},
+		"redis":                                 {"redis", ""},
+		"localhost:5000/storj:1.2@sha256:abcd":  {"localhost:5000/storj", "1.2"},
+		"localhost:5000/storj":                  {"localhost:5000/storj", ""},
+		"img.dev.storj.io/storjup/storj:1.13.1": {"img.dev.storj.io/storjup/storj", "1.13.1"},
+	} {
+		repository, tag := Split(image)
+		require.Equal(t, expected, [2]string{repository, tag}, image)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package compose
+
+import (
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// RecipeLabel is the compose label which assigns a service to a recipe service explicitly (like satellite-api or
+// storagenode/2).
+const RecipeLabel = "io.storj.up.recipe"
+
+// Adoption is the mapping of one compose service to a recipe service.
+type Adoption struct {
+	Service  string
+	Metadata Metadata
+	// Reason is how the recipe service is found: metadata (already adopted), explicit, label, command or image. Empty,
+	// if the service is not matched.
+	Reason string
+	// Candidates are the equally matching recipe services, if the match is ambiguous.
+	Candidates []string
+}
+
+// target is the requested recipe service of a compose service. Instance is -1 if it's not specified.
+type target struct {
+	service  string
+	recipe   string
+	instance int
+	reason   string
+}
+
+// Adopt maps the services of an existing (hand-written) compose file to the recipe services, and saves the mapping
+// as metadata. Services are matched by the explicit mapping (compose service name -> recipe service with optional
+// instance), the RecipeLabel of the service, or by the command and the image. Services which already have metadata
+// are kept, unless force is set.
+func (c *Compose) Adopt(stack recipe.Stack, explicit map[string]string, force bool) ([]Adoption, error) {
+	candidates := map[string]*recipe.Service{}
+	for _, r := range stack {
+		for _, s := range r.Add {
+			if _, found := candidates[s.Name]; !found {
+				candidates[s.Name] = s
+			}
+		}
+	}
+	for name := range explicit {
+		if _, found := c.project.Services[name]; !found {
+			return nil, errs.Errorf("no such service in %s: %s", common.ComposeFileName, name)
+		}
+	}
+
+	var names []string
+	for name := range c.project.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var adoptions []Adoption
+	var targets []target
+	used := map[string][]int{}
+	for _, name := range names {
+		ds := c.project.Services[name]
+		if m, found := GetMetadata(ds); found && !force {
+			used[m.Recipe] = append(used[m.Recipe], m.Instance)
+			adoptions = append(adoptions, Adoption{Service: name, Metadata: m, Reason: "metadata"})
+			continue
+		}
+
+		var t target
+		var err error
+		switch {
+		case explicit[name] != "":
+			t, err = parseTarget(explicit[name], "explicit")
+		case ds.Labels[RecipeLabel] != "":
+			t, err = parseTarget(ds.Labels[RecipeLabel], "label")
+		default:
+			var ambiguous []string
+			t, ambiguous = match(ds, candidates)
+			if t.recipe == "" {
+				adoptions = append(adoptions, Adoption{Service: name, Candidates: ambiguous})
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, found := candidates[t.recipe]; !found {
+			return nil, errs.Errorf("%s is mapped to %s, but there is no such recipe service", name, t.recipe)
+		}
+		t.service = name
+		targets = append(targets, t)
+	}
+
+	// explicit instances are reserved first
+	for _, t := range targets {
+		if t.instance < 0 {
+			continue
+		}
+		if slices.Contains(used[t.recipe], t.instance) {
+			return nil, errs.Errorf("%s/%d is assigned to more than one service", t.recipe, t.instance)
+		}
+		used[t.recipe] = append(used[t.recipe], t.instance)
+	}
+	// services named after the recipe service (like storagenode3) get their own index, if it's possible
+	sort.SliceStable(targets, func(i, j int) bool {
+		return named(targets[i]) && !named(targets[j])
+	})
+	for _, t := range targets {
+		if t.instance < 0 {
+			t.instance = freeInstance(used[t.recipe], t.service, t.recipe)
+			used[t.recipe] = append(used[t.recipe], t.instance)
+		}
+		ds := c.project.Services[t.service]
+		m := Metadata{Recipe: t.recipe, Instance: t.instance, Labels: candidates[t.recipe].Label}
+		SetMetadata(&ds, m)
+		delete(ds.Extensions, "labels")
+		c.project.Services[t.service] = ds
+		adoptions = append(adoptions, Adoption{Service: t.service, Metadata: m, Reason: t.reason})
+	}
+
+	sort.Slice(adoptions, func(i, j int) bool {
+		return adoptions[i].Service < adoptions[j].Service
+	})
+	return adoptions, nil
+}
+
+// parseTarget parses a recipe service with optional instance (like storagenode/2).
+func parseTarget(value string, reason string) (target, error) {
+	name, instance, found := strings.Cut(strings.TrimSpace(value), "/")
+	t := target{recipe: name, instance: -1, reason: reason}
+	if found {
+		ix, err := strconv.Atoi(instance)
+		if err != nil || ix < 0 {
+			return t, errs.Errorf("invalid instance in %s (expected <recipe service>/<instance>)", value)
+		}
+		t.instance = ix
+	}
+	return t, nil
+}
+
+// match finds the recipe service of a compose service based on the command and the image. A longer matching command
+// is a better match, the image is used as a tie-breaker, or alone if the recipe service has no command. If more
+// recipe services match equally, the name of the compose service decides, or the match is ambiguous.
+func match(ds types.ServiceConfig, candidates map[string]*recipe.Service) (target, []string) {
+	command := commandWords(ds.Command)
+	if len(command) == 0 {
+		command = commandWords(ds.Entrypoint)
+	}
+	best := 0
+	var matching []string
+	for name, rs := range candidates {
+		words := commandMatch(command, commandWords(rs.Command))
+		score := words * 2
+		if rs.Image != "" && imageName(rs.Image) == imageName(ds.Image) {
+			score++
+		}
+		switch {
+		case score == 0 || score < best:
+		case score > best:
+			best = score
+			matching = []string{name}
+		default:
+			matching = append(matching, name)
+		}
+	}
+	reason := "image"
+	if best > 1 {
+		reason = "command"
+	}
+	if len(matching) == 1 {
+		return target{recipe: matching[0], instance: -1, reason: reason}, nil
+	}
+	sort.Strings(matching)
+	if named := runtime.ServiceInstanceFromIndexedName(ds.Name).Name; slices.Contains(matching, named) {
+		return target{recipe: named, instance: -1, reason: reason}, nil
+	}
+	return target{}, matching
+}
+
+// commandWords returns with the leading words of a command (binary and subcommands), until the first flag or
+// template.
+func commandWords(command []string) []string {
+	var words []string
+	for _, word := range command {
+		if word == "" || strings.HasPrefix(word, "-") || strings.Contains(word, "{{") {
+			break
+		}
+		words = append(words, path.Base(word))
+	}
+	return words
+}
+
+// commandMatch returns with the number of matching words, if the recipe command is a prefix of the service command.
+// The binary of the recipe command is optional, as it can be the entrypoint of the image.
+func commandMatch(service []string, recipe []string) int {
+	for _, expected := range [][]string{recipe, recipe[min(1, len(recipe)):]} {
+		if len(expected) > 0 && len(service) >= len(expected) && slices.Equal(service[:len(expected)], expected) {
+			return len(expected)
+		}
+	}
+	return 0
+}
+
+// imageName returns with the name of the image without the registry, the repository, the tag and the digest.
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, ":")
+	return name
+}
+
+// named checks if the compose service is named after the recipe service.
+func named(t target) bool {
+	return runtime.ServiceInstanceFromIndexedName(t.service).Name == t.recipe
+}
+
+// freeInstance selects the instance of a newly adopted service: the index of the name (like storagenode3) if it's
+// available, or the first free instance.
+func freeInstance(used []int, service string, recipeName string) int {
+	if id := runtime.ServiceInstanceFromIndexedName(service); id.Name == recipeName && !slices.Contains(used, id.Instance) {
+		return id.Instance
+	}
+	for i := 0; ; i++ {
+		if !slices.Contains(used, i) {
+			return i
+		}
+	}
+}
@@ -8,7 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -39,15 +39,21 @@ func (c *Compose) Reload(stack recipe.Stack) error {
 		return err
 	}
 	c.project = composeProject
+	// services without metadata (files generated by older versions) get the labels of the recipe service with the
+	// same name
 	for serviceName, service := range c.project.Services {
-		for _, recipe := range stack {
-			for _, baseService := range recipe.Add {
-				if strings.Contains(service.Name, baseService.Name) && len(baseService.Label) > 0 {
-					service.Extensions = map[string]any{"labels": baseService.Label}
-					c.project.Services[serviceName] = service
-				}
-			}
+		if _, found := GetMetadata(service); found {
+			continue
+		}
+		rService, err := stack.FindRecipeByName(runtime.ServiceInstanceFromIndexedName(service.Name).Name)
+		if err != nil || len(rService.Label) == 0 {
+			continue
 		}
+		if service.Extensions == nil {
+			service.Extensions = map[string]any{}
+		}
+		service.Extensions["labels"] = rService.Label
+		c.project.Services[serviceName] = service
 	}
 	return nil
 }
@@ -56,11 +62,7 @@ func (c *Compose) Reload(stack recipe.Stack) error {
 func (c *Compose) GetServices() []runtime.Service {
 	k := make([]runtime.Service, 0, len(c.project.Services))
 	for _, s := range c.project.Services {
-		id := runtime.ServiceInstanceFromIndexedName(s.Name)
-		var labels []string
-		if s.Extensions["labels"] != nil {
-			labels = s.Extensions["labels"].([]string)
-		}
+		id := serviceID(s)
 		svc := &Service{
 			id:         id,
 			project:    c.project,
@@ -68,7 +70,7 @@ func (c *Compose) GetServices() []runtime.Service {
 			render: func(s string) (string, error) {
 				return runtime.Render(c, id, s)
 			},
-			labels: labels,
+			labels: serviceLabels(s),
 		}
 		k = append(k, svc)
 	}
@@ -88,12 +90,18 @@ func (c *Compose) Get(service runtime.ServiceInstance, name string) string {
 	return c.variables[service.Name][name]
 }
 
-// GetHost implements runtime.Runtime.
+// GetHost implements runtime.Runtime. Internal host is the name of the compose service (which can be different from
+// the recipe name for adopted services), or the conventional name, if the service is not added yet.
 func (c *Compose) GetHost(service runtime.ServiceInstance, hostType string) string {
 	switch hostType {
 	case "listen":
 		return "0.0.0.0"
 	case "internal":
+		for _, ds := range c.project.Services {
+			if serviceID(ds) == service {
+				return ds.Name
+			}
+		}
 		if service.Name == "storagenode" {
 			return service.Name + strconv.Itoa(service.Instance+1)
 		}
@@ -153,6 +161,8 @@ func (c *Compose) GetPort(service runtime.ServiceInstance, portType string) runt
 }
 
 var _ runtime.Runtime = &Compose{}
+var _ runtime.Specifier = &Compose{}
+var _ runtime.Cleaner = &Compose{}
 
 // NewCompose creates a new compose runtime.
 func NewCompose(dir string) (*Compose, error) {
@@ -165,6 +175,10 @@ func NewCompose(dir string) (*Compose, error) {
 				"metainfo": "cockroach://root@cockroach:26257/metainfo?sslmode=disable",
 				"dir":      "/tmp/cockroach",
 			},
+			"postgres": {
+				"main":     "postgres://postgres@postgres:5432/master?sslmode=disable",
+				"metainfo": "postgres://postgres@postgres:5432/master?sslmode=disable",
+			},
 			"spanner": {
 				"main":         "spanner://projects/test-project/instances/test-instance/databases/master",
 				"metainfo":     "spanner://projects/test-project/instances/test-instance/databases/metainfo",
@@ -254,10 +268,10 @@ func (c *Compose) AddService(recipe recipe.Service) (runtime.Service, error) {
 		Deploy: &types.DeployConfig{
 			Replicas: &one,
 		},
-		Ports:      []types.ServicePortConfig{},
-		Image:      recipe.Image,
-		Extensions: map[string]any{"labels": recipe.Label},
+		Ports: []types.ServicePortConfig{},
+		Image: recipe.Image,
 	}
+	SetMetadata(&s, Metadata{Recipe: recipe.Name, Instance: index, Labels: recipe.Label})
 
 	if recipe.Name == "storagenode" || recipe.Name == "satellite-core" || recipe.Name == "satellite-admin" {
 		s.Environment["STORJUP_ROLE"] = ptrStr(recipe.Name)
@@ -285,6 +299,7 @@ func (c *Compose) AddService(recipe recipe.Service) (runtime.Service, error) {
 		render: func(s string) (string, error) {
 			return runtime.Render(c, id, s)
 		},
+		labels: recipe.Label,
 	}
 
 	err := runtime.InitFromRecipe(r, recipe)
@@ -329,11 +344,28 @@ func (c *Compose) AddService(recipe recipe.Service) (runtime.Service, error) {
 	return r, nil
 }
 
+// RemoveService implements runtime.Runtime. Remaining instances keep their names (like storagenode1): the rendered
+// values of the services refer to the instances by host.
+func (c *Compose) RemoveService(instance runtime.ServiceInstance) error {
+	removed := &Service{id: instance}
+	found := false
+	for serviceName, ds := range c.project.Services {
+		if filtered(removed, ds) {
+			delete(c.project.Services, serviceName)
+			found = true
+		}
+	}
+	if !found {
+		return errs.Errorf("no such service: %s", instance)
+	}
+	return nil
+}
+
 func (c *Compose) serviceCount(name string) int {
 	i := 0
 
 	for _, ds := range c.project.Services {
-		if m, err := regexp.MatchString(name+"\\d*", ds.Name); m && err == nil {
+		if serviceID(ds).Name == name {
 			i++
 		}
 	}
@@ -349,3 +381,81 @@ func (c *Compose) Write() error {
 	return common.WriteComposeFile(c.dir, c.project)
 
 }
+
+// Specs implements runtime.Specifier.
+func (c *Compose) Specs() []runtime.ServiceSpec {
+	return ProjectSpecs(c.project)
+}
+
+// State implements runtime.Cleaner. Only the mounts of the persisted directories are returned, and only the host
+// directories inside the project directory. Persisted directories without mounts are returned as Internal.
+func (c *Compose) State(s runtime.Service, persisted []string) runtime.ServiceState {
+	state := runtime.ServiceState{
+		Container: true,
+	}
+	cs, ok := s.(*Service)
+	if !ok {
+		return state
+	}
+	dir, err := filepath.Abs(c.dir)
+	if err != nil {
+		return state
+	}
+	for _, ds := range c.project.Services {
+		if !filtered(cs, ds) {
+			continue
+		}
+		state.Name = ds.Name
+		for _, dir := range persisted {
+			if !slices.ContainsFunc(ds.Volumes, func(v types.ServiceVolumeConfig) bool { return v.Target == dir }) {
+				state.Internal = append(state.Internal, dir)
+			}
+		}
+		for _, v := range ds.Volumes {
+			if !slices.Contains(persisted, v.Target) {
+				continue
+			}
+			switch v.Type {
+			case types.VolumeTypeBind:
+				if rel, err := filepath.Rel(dir, v.Source); err == nil && !strings.HasPrefix(rel, "..") {
+					state.Paths = append(state.Paths, v.Source)
+				}
+			case types.VolumeTypeVolume:
+				name := c.project.Name + "_" + v.Source
+				if declared, found := c.project.Volumes[v.Source]; found && declared.Name != "" {
+					name = declared.Name
+				}
+				state.Volumes = append(state.Volumes, name)
+			}
+		}
+	}
+	return state
+}
+
+// ProjectSpecs returns with the comparable definitions of the services of a compose project.
+func ProjectSpecs(project *types.Project) []runtime.ServiceSpec {
+	var specs []runtime.ServiceSpec
+	for _, ds := range project.Services {
+		spec := runtime.ServiceSpec{
+			Name:        ds.Name,
+			Image:       ds.Image,
+			Environment: map[string]string{},
+			Flags:       append([]string{}, ds.Command...),
+		}
+		for k, v := range ds.Environment {
+			if v != nil {
+				spec.Environment[k] = *v
+			} else {
+				spec.Environment[k] = ""
+			}
+		}
+		for _, p := range ds.Ports {
+			spec.Ports = append(spec.Ports, formatPort(p))
+		}
+		for _, v := range ds.Volumes {
+			spec.Volumes = append(spec.Volumes, formatVolume(v))
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
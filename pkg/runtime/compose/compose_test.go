@@ -4,10 +4,13 @@
 package compose
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/compose-spec/compose-go/v2/types"
 	"github.com/stretchr/testify/require"
 
 	"storj.io/storj-up/pkg/recipe"
@@ -28,7 +31,7 @@ func TestPersist(t *testing.T) {
 	s, err := c.AddService(r)
 	require.NoError(t, err)
 
-	err = s.Persist("/some/dir")
+	err = s.Persist("/some/dir", "")
 	require.NoError(t, err)
 
 	err = c.Write()
@@ -42,6 +45,72 @@ func TestPersist(t *testing.T) {
 
 }
 
+func TestPersistTypes(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCompose(dir)
+	require.NoError(t, err)
+
+	s, err := c.AddService(recipe.Service{
+		Name:  "storagenode",
+		Image: "img.dev.storj.io/storjup/storj",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Persist("/var/lib/storj/.local", runtime.PersistVolume))
+	require.NoError(t, s.Persist("/tmp/cache", runtime.PersistTmpfs))
+	require.Error(t, s.Persist("/some/dir", "nfs"))
+
+	// persisting the same directory again replaces the previous mount
+	require.NoError(t, s.Persist("/tmp/cache", runtime.PersistTmpfs))
+
+	require.NoError(t, c.Write())
+
+	reloaded, err := NewCompose(dir)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+
+	require.Contains(t, reloaded.project.Volumes, "storagenode-.local")
+	volumes := reloaded.project.Services["storagenode"].Volumes
+	require.Len(t, volumes, 2)
+	require.Equal(t, types.VolumeTypeVolume, volumes[0].Type)
+	require.Equal(t, "storagenode-.local", volumes[0].Source)
+	require.Equal(t, "/var/lib/storj/.local", volumes[0].Target)
+	require.Equal(t, types.VolumeTypeTmpfs, volumes[1].Type)
+	require.Equal(t, "/tmp/cache", volumes[1].Target)
+}
+
+func TestState(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCompose(dir)
+	require.NoError(t, err)
+
+	db, err := c.AddService(recipe.Service{Name: "cockroach", Image: "cockroachdb/cockroach", Command: []string{"cockroach", "start-single-node"}})
+	require.NoError(t, err)
+	require.NoError(t, db.Persist("/cockroach/cockroach-data", runtime.PersistBind))
+	require.NoError(t, db.UseFolder("/tmp/init", "init"))
+
+	node, err := c.AddService(recipe.Service{Name: "storagenode", Image: "img.dev.storj.io/storjup/storj"})
+	require.NoError(t, err)
+	require.NoError(t, node.Persist("/var/lib/storj/.local", runtime.PersistVolume))
+
+	state := c.State(db, []string{"/cockroach/cockroach-data"})
+	require.Equal(t, "cockroach", state.Name)
+	require.True(t, state.Container)
+	require.Equal(t, []string{filepath.Join(dir, "cockroach", "cockroach-data")}, state.Paths)
+	require.Empty(t, state.Volumes)
+
+	// only the persisted directories of the recipe are part of the state
+	require.Empty(t, c.State(db, nil).Paths)
+
+	// not mounted directories are stored in the container
+	require.Equal(t, []string{"/var/lib/storj/.local/share/storj"}, c.State(node, []string{"/var/lib/storj/.local/share/storj"}).Internal)
+
+	state = c.State(node, []string{"/var/lib/storj/.local"})
+	require.Equal(t, "storagenode", state.Name)
+	require.Empty(t, state.Paths)
+	require.Equal(t, []string{c.project.Name + "_storagenode-.local"}, state.Volumes)
+}
+
 func TestAddFile(t *testing.T) {
 
 	dir := t.TempDir()
@@ -84,3 +153,317 @@ func TestCompose(t *testing.T) {
 	require.NoError(t, err)
 
 }
+
+func TestDebug(t *testing.T) {
+	c, err := NewCompose(t.TempDir())
+	require.NoError(t, err)
+
+	var nodes []runtime.Debuggable
+	for i := 0; i < 2; i++ {
+		s, err := c.AddService(recipe.Service{
+			Name:  "storagenode",
+			Image: "img.dev.storj.io/storjup/storj",
+		})
+		require.NoError(t, err)
+		nodes = append(nodes, s.(runtime.Debuggable))
+	}
+
+	require.Equal(t, 0, nodes[0].DebugPort())
+	for _, n := range nodes {
+		require.NoError(t, n.SetDebug(true))
+	}
+	require.Equal(t, 30008, nodes[0].DebugPort())
+	require.Equal(t, 30018, nodes[1].DebugPort())
+
+	// enabling twice shouldn't publish the port twice
+	require.NoError(t, nodes[1].SetDebug(true))
+	require.Len(t, c.project.Services["storagenode2"].Ports, 2)
+
+	require.NoError(t, nodes[1].SetDebug(false))
+	require.Equal(t, 0, nodes[1].DebugPort())
+	require.NotContains(t, c.project.Services["storagenode2"].Environment, "GO_DLV")
+
+	// the conventional port of the 11th redis instance is used by the first one
+	var redis []runtime.Debuggable
+	for i := 0; i < 11; i++ {
+		s, err := c.AddService(recipe.Service{Name: "redis", Image: "redis", Command: []string{"redis-server"}})
+		require.NoError(t, err)
+		redis = append(redis, s.(runtime.Debuggable))
+	}
+	require.NoError(t, redis[0].SetDebug(true))
+	require.NoError(t, redis[10].SetDebug(true))
+	require.NotEqual(t, redis[0].DebugPort(), redis[10].DebugPort())
+	port := redis[10].DebugPort()
+	require.NoError(t, redis[0].SetDebug(false))
+	require.NoError(t, redis[10].SetDebug(true))
+	require.Equal(t, port, redis[10].DebugPort())
+}
+
+func TestRemoveService(t *testing.T) {
+	c, err := NewCompose(t.TempDir())
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := c.AddService(recipe.Service{Name: "storagenode", Image: "img.dev.storj.io/storjup/storj"})
+		require.NoError(t, err)
+	}
+	require.Contains(t, c.project.Services, "storagenode3")
+
+	require.NoError(t, c.RemoveService(runtime.NewServiceInstance("storagenode", 2)))
+	require.NotContains(t, c.project.Services, "storagenode3")
+	require.Contains(t, c.project.Services, "storagenode2")
+
+	// storagenodes are addressed by the indexed name, the last instance keeps it
+	require.NoError(t, c.RemoveService(runtime.NewServiceInstance("storagenode", 1)))
+	require.Len(t, c.project.Services, 1)
+	require.Contains(t, c.project.Services, "storagenode1")
+	require.Equal(t, "storagenode1", c.GetHost(runtime.NewServiceInstance("storagenode", 0), "internal"))
+
+	require.Error(t, c.RemoveService(runtime.NewServiceInstance("storagenode", 5)))
+
+}
+
+func TestDescribe(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewCompose(dir)
+	require.NoError(t, err)
+
+	s, err := c.AddService(recipe.Service{
+		Name:  "satellite-api",
+		Image: "img.dev.storj.io/storjup/storj:1.2.3",
+		Label: []string{"storj"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Persist("/var/lib/storj/.local", runtime.PersistBind))
+	require.NoError(t, s.(runtime.Debuggable).SetDebug(true))
+
+	info := s.(runtime.Describer).Describe()
+	require.Equal(t, "satellite-api/0", info.Instance)
+	require.Equal(t, "img.dev.storj.io/storjup/storj:1.2.3", info.Source)
+	require.Equal(t, []string{"7777:7777/tcp", "10000:10000/tcp", "10008:2345/tcp"}, info.Ports)
+	require.Equal(t, []string{filepath.Join(dir, "satellite-api", ".local") + ":/var/lib/storj/.local"}, info.Persisted)
+	require.Equal(t, 10008, info.Debug)
+	require.Equal(t, []string{"storj"}, info.Labels)
+
+	// image built by a builder service
+	branch, remote := "main", "github"
+	c.project.Services["app-storj"] = types.ServiceConfig{
+		Name:  "app-storj",
+		Image: "storj",
+		Build: &types.BuildConfig{Args: types.MappingWithEquals{"TYPE": &remote, "BRANCH": &branch}},
+	}
+	require.NoError(t, s.ChangeImage(func(string) string { return "storj" }))
+	require.Equal(t, "built from github branch main", s.(runtime.Describer).Describe().Source)
+
+	// mounted local binary
+	require.NoError(t, s.(*Service).TransformRaw(func(ds *types.ServiceConfig) error {
+		ds.Volumes = append(ds.Volumes, types.ServiceVolumeConfig{Type: "bind", Source: "/home/dev/go/bin/satellite", Target: "/var/lib/storj/go/bin/satellite"})
+		return nil
+	}))
+	info = s.(runtime.Describer).Describe()
+	require.Equal(t, "local bin /home/dev/go/bin/satellite", info.Source)
+	require.Len(t, info.Persisted, 1)
+}
+
+func TestBuiltFrom(t *testing.T) {
+	args := func(kv ...string) *types.BuildConfig {
+		m := types.MappingWithEquals{}
+		for i := 0; i < len(kv); i += 2 {
+			m[kv[i]] = &kv[i+1]
+		}
+		return &types.BuildConfig{Args: m}
+	}
+	for _, tc := range []struct {
+		build    *types.BuildConfig
+		expected string
+	}{
+		{args("TYPE", "github", "BRANCH", "main"), "built from github branch main"},
+		{args("TYPE", "github", "BRANCH", "main", "COMMIT", "abc123"), "built from github commit abc123"},
+		{args("TYPE", "github", "PR", "1234"), "built from github PR #1234"},
+		{args("TYPE", "gerrit", "REF", "refs/changes/65/6365/1"), "built from gerrit refs/changes/65/6365/1"},
+		{args("TYPE", "git", "URL", "https://example.com/storj.git", "REF", "fix"), "built from git https://example.com/storj.git fix"},
+		{args("TYPE", "local", "PATH", ".", "PATCH", "0123456789ab-fix.diff"), "built from local . with patch 0123456789ab-fix.diff"},
+		{args(), "built from source"},
+		{&types.BuildConfig{Dockerfile: "/home/dev/multinode.Dockerfile", Target: "final"}, "built from /home/dev/multinode.Dockerfile (target final)"},
+	} {
+		require.Equal(t, tc.expected, builtFrom(tc.build))
+	}
+}
+
+func TestReloadKeepsExtensions(t *testing.T) {
+	t.Setenv("STORJUP_NO_HISTORY", "true")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yaml"), []byte(`name: storj-up
+services:
+  satellite-api:
+    image: img.dev.storj.io/storjup/storj
+    x-owner: me
+x-common: true
+`), 0644))
+
+	c, err := NewCompose(dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Reload(recipe.Stack{{Add: []*recipe.Service{{Name: "satellite-api", Label: []string{"storj"}}}}}))
+	require.NoError(t, c.GetServices()[0].AddConfig("STORJ_LOG_LEVEL", "debug"))
+	require.NoError(t, c.Write())
+
+	raw, err := os.ReadFile(filepath.Join(dir, "docker-compose.yaml"))
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "STORJ_LOG_LEVEL: debug")
+	require.Contains(t, string(raw), "x-owner: me")
+	require.Contains(t, string(raw), "x-common: true")
+	require.NotContains(t, string(raw), "labels")
+}
+
+func TestAdopt(t *testing.T) {
+	t.Setenv("STORJUP_NO_HISTORY", "true")
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docker-compose.yaml"), []byte(`name: storj-up
+services:
+  sat:
+    image: img.dev.storj.io/storjup/storj:1.100.0
+    command: ["satellite", "run", "api", "--defaults=dev"]
+  satellite-api-proxy:
+    image: nginx
+  node-a:
+    image: storjlabs/storj
+    command: ["storagenode", "run", "api"]
+  storagenode1:
+    image: storjlabs/storj
+    command: ["storagenode", "run", "api"]
+  db:
+    image: redis:6.0.9
+  worker:
+    image: img.dev.storj.io/storjup/storj:1.100.0
+    labels:
+      io.storj.up.recipe: satellite-core/0
+  unknown:
+    image: img.dev.storj.io/storjup/storj:1.100.0
+`), 0644))
+
+	stack := recipe.Stack{{Add: []*recipe.Service{
+		{Name: "satellite-api", Label: []string{"storj", "core"}, Image: "img.dev.storj.io/storjup/storj:1.125.2", Command: []string{"satellite", "run", "api", "--defaults=dev"}},
+		{Name: "satellite-core", Label: []string{"storj", "core"}, Image: "img.dev.storj.io/storjup/storj:1.125.2", Command: []string{"satellite", "run", "--defaults=dev"}},
+		{Name: "storagenode", Label: []string{"storj"}, Image: "img.dev.storj.io/storjup/storj:1.125.2", Command: []string{"storagenode", "run", "api"}},
+		{Name: "redis", Label: []string{"infra"}, Image: "redis:6.0.9", Command: []string{"redis-server"}},
+	}}}
+
+	c, err := NewCompose(dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Reload(stack))
+	// without metadata, only the exact names are used
+	for _, s := range c.GetServices() {
+		if s.ID().Name == "satellite-api-proxy" {
+			require.Empty(t, s.Labels())
+		}
+	}
+
+	_, err = c.Adopt(stack, map[string]string{"db": "redis/x"}, false)
+	require.Error(t, err)
+
+	adoptions, err := c.Adopt(stack, map[string]string{"db": "redis"}, false)
+	require.NoError(t, err)
+	result := map[string]string{}
+	for _, a := range adoptions {
+		if a.Reason == "" {
+			result[a.Service] = strings.Join(a.Candidates, ",")
+			continue
+		}
+		result[a.Service] = fmt.Sprintf("%s/%d %s", a.Metadata.Recipe, a.Metadata.Instance, a.Reason)
+	}
+	require.Equal(t, map[string]string{
+		"sat":                 "satellite-api/0 command",
+		"satellite-api-proxy": "",
+		"node-a":              "storagenode/1 command",
+		"storagenode1":        "storagenode/0 command",
+		"db":                  "redis/0 explicit",
+		"worker":              "satellite-core/0 label",
+		"unknown":             "satellite-api,satellite-core,storagenode",
+	}, result)
+
+	// the metadata is saved, and used instead of the names
+	require.NoError(t, c.Write())
+	c, err = NewCompose(dir)
+	require.NoError(t, err)
+	require.NoError(t, c.Reload(stack))
+	ids := map[string][]string{}
+	for _, s := range c.GetServices() {
+		ids[s.ID().String()] = s.Labels()
+	}
+	require.Equal(t, []string{"storj", "core"}, ids["satellite-api/0"])
+	require.Equal(t, []string{"storj"}, ids["storagenode/1"])
+	// hosts are the names of the adopted compose services
+	require.Equal(t, "sat", c.GetHost(runtime.NewServiceInstance("satellite-api", 0), "internal"))
+	require.Equal(t, "node-a", c.GetHost(runtime.NewServiceInstance("storagenode", 1), "internal"))
+	require.Equal(t, "storagenode1", c.GetHost(runtime.NewServiceInstance("storagenode", 0), "internal"))
+	require.Equal(t, "db", c.GetHost(runtime.NewServiceInstance("redis", 0), "internal"))
+	require.Contains(t, ids, "satellite-api-proxy/0")
+	require.NoError(t, runtime.ModifyService(stack, c, []string{"storagenode2"}, func(s runtime.Service) error {
+		return s.AddEnvironment("STORJ_LOG_LEVEL", "debug")
+	}))
+	require.Equal(t, "debug", *c.project.Services["node-a"].Environment["STORJ_LOG_LEVEL"])
+
+	adoptions, err = c.Adopt(stack, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, "metadata", adoptions[0].Reason)
+}
+
+func TestUseDatabase(t *testing.T) {
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	c, err := NewCompose(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, runtime.ApplyRecipes(st, c, []string{"minimal", "db", "core", "gc"}, 0))
+
+	_, err = runtime.UseDatabase(st, c, "mysql")
+	require.Error(t, err)
+
+	changed, err := runtime.UseDatabase(st, c, "postgres")
+	require.NoError(t, err)
+	require.Contains(t, changed, "satellite-api/0")
+	require.Contains(t, changed, "satellite-core/0")
+	require.NotContains(t, changed, "storagenode/0")
+	require.NotContains(t, c.project.Services, "spanner")
+	require.Contains(t, c.project.Services, "postgres")
+	require.Contains(t, c.project.Services, "redis")
+	for _, s := range c.project.Services {
+		if _, found := s.Environment["STORJ_DATABASE"]; !found {
+			continue
+		}
+		require.Equal(t, "postgres://postgres@postgres:5432/master?sslmode=disable", *s.Environment["STORJ_DATABASE"], s.Name)
+		require.Equal(t, "postgres://postgres@postgres:5432/master?sslmode=disable", *s.Environment["STORJ_METAINFO_DATABASE_URL"], s.Name)
+		require.NotContains(t, s.Environment, "SPANNER_EMULATOR_HOST", s.Name)
+	}
+
+	_, err = runtime.UseDatabase(st, c, "spanner")
+	require.NoError(t, err)
+	require.NotContains(t, c.project.Services, "postgres")
+	require.Contains(t, c.project.Services, "spanner")
+	sat := c.project.Services["satellite-api"]
+	require.Equal(t, "spanner://projects/test-project/instances/test-instance/databases/master", *sat.Environment["STORJ_DATABASE"])
+	require.Equal(t, "spanner:9010", *sat.Environment["SPANNER_EMULATOR_HOST"])
+}
+
+func TestUseDatabaseShared(t *testing.T) {
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	c, err := NewCompose(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, runtime.ApplyRecipes(st, c, []string{"minimal", "db", "billing"}, 0))
+	require.Contains(t, c.project.Services, "postgres")
+
+	changed, err := runtime.UseDatabase(st, c, "cockroach")
+	require.NoError(t, err)
+	require.Contains(t, changed, "satellite-api/0")
+	require.NotContains(t, changed, "storjscan/0")
+	require.NotContains(t, c.project.Services, "spanner")
+	require.Contains(t, c.project.Services, "cockroach")
+	// storjscan uses postgres
+	require.Contains(t, c.project.Services, "postgres")
+	require.Equal(t, "cockroach://root@cockroach:26257/master?sslmode=disable", *c.project.Services["satellite-api"].Environment["STORJ_DATABASE"])
+
+	require.NoError(t, c.RemoveService(runtime.NewServiceInstance("storjscan", 0)))
+	_, err = runtime.UseDatabase(st, c, "spanner")
+	require.NoError(t, err)
+	require.NotContains(t, c.project.Services, "postgres")
+	require.NotContains(t, c.project.Services, "cockroach")
+}
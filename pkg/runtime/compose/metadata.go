@@ -0,0 +1,64 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package compose
+
+import (
+	"github.com/compose-spec/compose-go/v2/types"
+
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// MetadataExtension is the compose extension of the services which connects them to the recipes.
+const MetadataExtension = "x-storj-up"
+
+// Metadata is the storj-up specific information of a compose service.
+type Metadata struct {
+	// Recipe is the name of the recipe service (like satellite-api).
+	Recipe string `yaml:"recipe" mapstructure:"recipe"`
+	// Instance is the index of the instance, starting from 0.
+	Instance int      `yaml:"instance" mapstructure:"instance"`
+	Labels   []string `yaml:"labels,omitempty" mapstructure:"labels"`
+}
+
+// GetMetadata returns with the storj-up metadata of a compose service, if it has any.
+func GetMetadata(ds types.ServiceConfig) (Metadata, bool) {
+	switch m := ds.Extensions[MetadataExtension].(type) {
+	case nil:
+		return Metadata{}, false
+	case Metadata:
+		return m, true
+	}
+	var m Metadata
+	found, err := ds.Extensions.Get(MetadataExtension, &m)
+	if !found || err != nil || m.Recipe == "" {
+		return Metadata{}, false
+	}
+	return m, true
+}
+
+// SetMetadata saves the storj-up metadata to the extensions of the compose service.
+func SetMetadata(ds *types.ServiceConfig, m Metadata) {
+	if ds.Extensions == nil {
+		ds.Extensions = map[string]any{}
+	}
+	ds.Extensions[MetadataExtension] = m
+}
+
+// serviceID returns with the recipe service and instance of a compose service. Without metadata, it's guessed from
+// the name (like storagenode3).
+func serviceID(ds types.ServiceConfig) runtime.ServiceInstance {
+	if m, found := GetMetadata(ds); found {
+		return runtime.NewServiceInstance(m.Recipe, m.Instance)
+	}
+	return runtime.ServiceInstanceFromIndexedName(ds.Name)
+}
+
+// serviceLabels returns with the labels of the recipe service, from the metadata, or from Reload.
+func serviceLabels(ds types.ServiceConfig) []string {
+	if m, found := GetMetadata(ds); found {
+		return m.Labels
+	}
+	labels, _ := ds.Extensions["labels"].([]string)
+	return labels
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/zeebo/errs/v2"
 	"golang.org/x/exp/slices"
 
 	"storj.io/storj-up/pkg/runtime/runtime"
@@ -26,6 +27,12 @@ type Service struct {
 
 var _ runtime.Service = (*Service)(nil)
 var _ runtime.ManageableNetwork = (*Service)(nil)
+var _ runtime.Debuggable = (*Service)(nil)
+var _ runtime.EnvironmentRemover = (*Service)(nil)
+var _ runtime.Describer = (*Service)(nil)
+
+// delvePort is the port of the Delve server inside the containers (see entrypoint.sh).
+const delvePort = 2345
 
 // GetENV implements runtime.Service.
 func (s *Service) GetENV() map[string]*string {
@@ -112,18 +119,41 @@ func (s *Service) RemoveFlag(flag string) error {
 	return nil
 }
 
-// Persist implements runtime.Service.
-func (s *Service) Persist(dir string) error {
+// Persist implements runtime.Service. Bind mounts use the <composeDir>/<service>/<dir name> directory, named volumes
+// are called <service>-<dir name>.
+func (s *Service) Persist(dir string, persistType string) error {
 	for serviceName, ds := range s.project.Services {
 		if filtered(s, ds) {
-			ds.Volumes = append(ds.Volumes, types.ServiceVolumeConfig{
-				Type:   "bind",
-				Source: filepath.Join(s.composeDir, ds.Name, filepath.Base(dir)),
-				Target: dir,
-				Bind: &types.ServiceVolumeBind{
-					CreateHostPath: true,
-				},
+			var volume types.ServiceVolumeConfig
+			switch persistType {
+			case runtime.PersistBind, "":
+				volume = types.ServiceVolumeConfig{
+					Type:   types.VolumeTypeBind,
+					Source: filepath.Join(s.composeDir, ds.Name, filepath.Base(dir)),
+					Target: dir,
+					Bind: &types.ServiceVolumeBind{
+						CreateHostPath: true,
+					},
+				}
+			case runtime.PersistVolume:
+				volume = types.ServiceVolumeConfig{
+					Type:   types.VolumeTypeVolume,
+					Source: ds.Name + "-" + filepath.Base(dir),
+					Target: dir,
+				}
+			case runtime.PersistTmpfs:
+				volume = types.ServiceVolumeConfig{
+					Type:   types.VolumeTypeTmpfs,
+					Target: dir,
+				}
+			default:
+				return errs.Errorf("unsupported persistence type %q (supported: %s)", persistType, strings.Join(runtime.PersistTypes, ", "))
+			}
+			// persisting again (eg. with a different type) replaces the previous mount
+			ds.Volumes = slices.DeleteFunc(ds.Volumes, func(v types.ServiceVolumeConfig) bool {
+				return v.Target == dir
 			})
+			ds.Volumes = append(ds.Volumes, volume)
 			s.project.Services[serviceName] = ds
 		}
 	}
@@ -154,6 +184,9 @@ func (s *Service) AddConfig(key string, value string) error {
 			if err != nil {
 				return err
 			}
+			if ds.Environment == nil {
+				ds.Environment = map[string]*string{}
+			}
 			ds.Environment[key] = &rendered
 			s.project.Services[serviceName] = ds
 		}
@@ -162,6 +195,9 @@ func (s *Service) AddConfig(key string, value string) error {
 }
 
 func filtered(s *Service, ds types.ServiceConfig) bool {
+	if m, found := GetMetadata(ds); found {
+		return m.Recipe == s.id.Name && m.Instance == s.id.Instance
+	}
 	return (s.id.Name == ds.Name && s.id.Instance == 0) || ds.Name == s.id.Name+strconv.Itoa(s.id.Instance+1)
 }
 
@@ -266,6 +302,9 @@ func (s *Service) AddEnvironment(key string, value string) error {
 			if err != nil {
 				return err
 			}
+			if ds.Environment == nil {
+				ds.Environment = map[string]*string{}
+			}
 			ds.Environment[key] = ptrStr(rendered)
 			s.project.Services[serviceName] = ds
 		}
@@ -273,6 +312,17 @@ func (s *Service) AddEnvironment(key string, value string) error {
 	return nil
 }
 
+// RemoveEnvironment implements runtime.EnvironmentRemover.
+func (s *Service) RemoveEnvironment(key string) error {
+	for serviceName, ds := range s.project.Services {
+		if filtered(s, ds) {
+			delete(ds.Environment, key)
+			s.project.Services[serviceName] = ds
+		}
+	}
+	return nil
+}
+
 // TransformRaw enables to apply transformations on original raw docker service.
 func (s *Service) TransformRaw(apply func(config *types.ServiceConfig) error) error {
 	for serviceName, ds := range s.project.Services {
@@ -286,3 +336,183 @@ func (s *Service) TransformRaw(apply func(config *types.ServiceConfig) error) er
 	}
 	return nil
 }
+
+// SetDebug implements runtime.Debuggable. Delve port of the container is published on a unique host port (which is
+// kept, if the debugger is enabled already).
+func (s *Service) SetDebug(enabled bool) error {
+	var used []int
+	current := 0
+	for _, ds := range s.project.Services {
+		for _, port := range ds.Ports {
+			published, err := strconv.Atoi(port.Published)
+			switch {
+			case err != nil:
+			case filtered(s, ds) && port.Target == delvePort:
+				current = published
+			default:
+				used = append(used, published)
+			}
+		}
+	}
+	return s.TransformRaw(func(ds *types.ServiceConfig) error {
+		ds.Ports = slices.DeleteFunc(ds.Ports, func(port types.ServicePortConfig) bool {
+			return port.Target == delvePort
+		})
+		if !enabled {
+			delete(ds.Environment, "GO_DLV")
+			return nil
+		}
+		published := current
+		if published == 0 || slices.Contains(used, published) {
+			var err error
+			published, err = runtime.DelvePort(s.id, used)
+			if err != nil {
+				return err
+			}
+		}
+		if ds.Environment == nil {
+			ds.Environment = map[string]*string{}
+		}
+		ds.Environment["GO_DLV"] = ptrStr("true")
+		ds.Ports = append(ds.Ports, types.ServicePortConfig{
+			Mode:      "ingress",
+			Target:    delvePort,
+			Published: strconv.Itoa(published),
+			Protocol:  "tcp",
+		})
+		return nil
+	})
+}
+
+// DebugPort implements runtime.Debuggable.
+func (s *Service) DebugPort() int {
+	for _, ds := range s.project.Services {
+		if !filtered(s, ds) {
+			continue
+		}
+		if _, found := ds.Environment["GO_DLV"]; !found {
+			return 0
+		}
+		for _, port := range ds.Ports {
+			if port.Target == delvePort {
+				published, err := strconv.Atoi(port.Published)
+				if err == nil {
+					return published
+				}
+			}
+		}
+		return delvePort
+	}
+	return 0
+}
+
+// localBinDir is the directory of the binaries inside the containers, where `storj-up local-bin` mounts the local binaries.
+const localBinDir = "/var/lib/storj/go/bin/"
+
+// Describe implements runtime.Describer.
+func (s *Service) Describe() runtime.ServiceInfo {
+	info := runtime.ServiceInfo{
+		Instance: s.id.String(),
+		Debug:    s.DebugPort(),
+		Labels:   s.labels,
+	}
+	for _, ds := range s.project.Services {
+		if !filtered(s, ds) {
+			continue
+		}
+		var binaries []string
+		for _, v := range ds.Volumes {
+			if v.Type == types.VolumeTypeBind && strings.HasPrefix(v.Target, localBinDir) {
+				binaries = append(binaries, v.Source)
+				continue
+			}
+			info.Persisted = append(info.Persisted, formatVolume(v))
+		}
+		for _, p := range ds.Ports {
+			info.Ports = append(info.Ports, formatPort(p))
+		}
+		info.Source = s.source(ds, binaries)
+	}
+	return info
+}
+
+// formatPort returns with the port definition in published:target/protocol format.
+func formatPort(p types.ServicePortConfig) string {
+	port := strconv.Itoa(int(p.Target))
+	if p.Published != "" {
+		port = p.Published + ":" + port
+	}
+	if p.Protocol != "" {
+		port += "/" + p.Protocol
+	}
+	return port
+}
+
+// formatVolume returns with the volume definition in source:target format.
+func formatVolume(v types.ServiceVolumeConfig) string {
+	if v.Type == types.VolumeTypeTmpfs {
+		return "tmpfs:" + v.Target
+	}
+	if v.Source == "" {
+		return v.Target
+	}
+	return v.Source + ":" + v.Target
+}
+
+// source returns with the origin of the binaries of the container: the image, the build or the mounted local binaries.
+func (s *Service) source(ds types.ServiceConfig, binaries []string) string {
+	if len(binaries) > 0 {
+		return "local bin " + strings.Join(binaries, ", ")
+	}
+	if ds.Build != nil {
+		return builtFrom(ds.Build)
+	}
+	// images of `storj-up build` are built by dedicated builder services
+	for _, builder := range s.project.Services {
+		if builder.Build != nil && builder.Image != "" && builder.Image == ds.Image {
+			return builtFrom(builder.Build)
+		}
+	}
+	return ds.Image
+}
+
+// builtFrom describes the source of a build, based on the build arguments used by `storj-up build`. Builds of custom
+// Dockerfiles are described by the Dockerfile.
+func builtFrom(build *types.BuildConfig) string {
+	arg := func(name string) string {
+		if value := build.Args[name]; value != nil {
+			return *value
+		}
+		return ""
+	}
+	var from string
+	switch arg("TYPE") {
+	case "github":
+		switch {
+		case arg("PR") != "":
+			from = "built from github PR #" + arg("PR")
+		case arg("COMMIT") != "":
+			from = "built from github commit " + arg("COMMIT")
+		default:
+			from = "built from github branch " + arg("BRANCH")
+		}
+	case "gerrit":
+		from = "built from gerrit " + arg("REF")
+	case "git":
+		from = "built from git " + arg("URL") + " " + arg("REF")
+	case "local":
+		from = "built from local " + arg("PATH")
+	default:
+		from = "built from source"
+		if build.Dockerfile != "" {
+			from = "built from " + build.Dockerfile
+		}
+		if build.Target != "" {
+			from += " (target " + build.Target + ")"
+		}
+	}
+	if patch := arg("PATCH"); patch != "" {
+		from += " with patch " + patch
+	}
+	return from
+}
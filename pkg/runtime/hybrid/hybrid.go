@@ -0,0 +1,194 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package hybrid contains a runtime which runs the infrastructure services (databases, redis, ...) in containers,
+// and the Storj services as native processes.
+package hybrid
+
+import (
+	"os"
+	"slices"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/runtime/standalone"
+)
+
+// InfraLabel is the recipe label of the services which are running in containers.
+const InfraLabel = "infra"
+
+// publishedPorts are the ports which are not declared by the recipes (not required in a pure compose environment),
+// but required to access the containers from the host. The declared ports of the recipes are always published.
+var publishedPorts = map[string][]int{
+	"redis": {6379},
+}
+
+// Hybrid is a runtime.Runtime implementation which writes the infrastructure services to docker-compose.yaml
+// and generates scripts for all the other services.
+type Hybrid struct {
+	containers  *compose.Compose
+	native      *standalone.Standalone
+	containered map[string]bool
+}
+
+var _ runtime.Runtime = &Hybrid{}
+var _ runtime.Specifier = &Hybrid{}
+var _ runtime.Cleaner = &Hybrid{}
+
+// NewHybrid creates a new hybrid runtime. Both docker-compose.yaml and the scripts are generated to the ScriptDir.
+func NewHybrid(paths standalone.Paths) (*Hybrid, error) {
+	containers, err := compose.NewCompose(paths.ScriptDir)
+	if err != nil {
+		return nil, err
+	}
+	native, err := standalone.NewStandalone(paths)
+	if err != nil {
+		return nil, err
+	}
+	h := &Hybrid{
+		containers:  containers,
+		native:      native,
+		containered: map[string]bool{},
+	}
+	native.WithResolver(h)
+	return h, nil
+}
+
+// Native returns with the runtime of the natively executed services.
+func (h *Hybrid) Native() *standalone.Standalone {
+	return h.native
+}
+
+// Containers returns with the runtime of the containerized services.
+func (h *Hybrid) Containers() *compose.Compose {
+	return h.containers
+}
+
+// AddService implements runtime.Runtime.
+func (h *Hybrid) AddService(recipe recipe.Service) (runtime.Service, error) {
+	if !recipe.HasLabel(InfraLabel) {
+		return h.native.AddService(recipe)
+	}
+	h.containered[recipe.Name] = true
+	s, err := h.containers.AddService(recipe)
+	if err != nil {
+		return s, err
+	}
+	for _, port := range hostPorts(recipe) {
+		err = s.AddPortForward(runtime.PortMap{Internal: port, External: port, Protocol: "tcp"})
+		if err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// hostPorts returns with the ports of an infra service which are used by the native processes, but not published by
+// the recipe itself (compose publishes all the declared ports of the recipes).
+func hostPorts(service recipe.Service) []int {
+	var ports []int
+	for _, port := range publishedPorts[service.Name] {
+		if !slices.ContainsFunc(service.Port, func(p recipe.PortDefinition) bool { return p.Target == port }) {
+			ports = append(ports, port)
+		}
+	}
+	return ports
+}
+
+// RemoveService implements runtime.Runtime.
+func (h *Hybrid) RemoveService(instance runtime.ServiceInstance) error {
+	if h.containered[instance.Name] {
+		return h.containers.RemoveService(instance)
+	}
+	return h.native.RemoveService(instance)
+}
+
+// Write implements runtime.Runtime.
+func (h *Hybrid) Write() error {
+	return errs.Combine(h.containers.Write(), h.native.Write())
+}
+
+// GetServices implements runtime.Runtime.
+func (h *Hybrid) GetServices() []runtime.Service {
+	return append(h.containers.GetServices(), h.native.GetServices()...)
+}
+
+// Specs implements runtime.Specifier.
+func (h *Hybrid) Specs() []runtime.ServiceSpec {
+	return append(h.containers.Specs(), h.native.Specs()...)
+}
+
+// State implements runtime.Cleaner.
+func (h *Hybrid) State(s runtime.Service, persisted []string) runtime.ServiceState {
+	if h.containered[s.ID().Name] {
+		return h.containers.State(s, persisted)
+	}
+	return h.native.State(s, persisted)
+}
+
+// Reload implements runtime.Runtime.
+func (h *Hybrid) Reload(stack recipe.Stack) error {
+	err := h.containers.Reload(stack)
+	if err != nil {
+		return err
+	}
+	for _, s := range h.containers.GetServices() {
+		h.containered[s.ID().Name] = true
+	}
+	return h.native.Reload(stack)
+}
+
+// Get implements runtime.Runtime.
+func (h *Hybrid) Get(service runtime.ServiceInstance, name string) string {
+	host := dockerHost()
+	switch service.Name {
+	case "cockroach":
+		switch name {
+		case "main":
+			return "cockroach://root@" + host + ":26257/master?sslmode=disable"
+		case "metainfo":
+			return "cockroach://root@" + host + ":26257/metainfo?sslmode=disable"
+		}
+	case "postgres":
+		switch name {
+		case "main", "metainfo":
+			return "postgres://postgres@" + host + ":5432/master?sslmode=disable"
+		}
+	case "spanner":
+		if name == "emulatorHost" {
+			return host + ":9010"
+		}
+	case "redis":
+		if name == "url" {
+			return "redis://" + host + ":6379"
+		}
+	}
+	return h.native.Get(service, name)
+}
+
+// GetHost implements runtime.Runtime. Containers are accessed from the native processes via the published ports.
+func (h *Hybrid) GetHost(service runtime.ServiceInstance, hostType string) string {
+	if h.containered[service.Name] {
+		return h.containers.GetHost(service, "external")
+	}
+	return h.native.GetHost(service, hostType)
+}
+
+// GetPort implements runtime.Runtime.
+func (h *Hybrid) GetPort(service runtime.ServiceInstance, portType string) runtime.PortMap {
+	if h.containered[service.Name] {
+		return h.containers.GetPort(service, portType)
+	}
+	return h.native.GetPort(service, portType)
+}
+
+// dockerHost returns with the address where the published container ports are available.
+func dockerHost() string {
+	if host := os.Getenv("STORJ_DOCKER_HOST"); host != "" {
+		return host
+	}
+	return "localhost"
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package hybrid
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/runtime"
+	"storj.io/storj-up/pkg/runtime/standalone"
+)
+
+func TestHybrid(t *testing.T) {
+	t.Setenv("STORJ_DOCKER_HOST", "")
+	tempDir := t.TempDir()
+	paths := standalone.Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	}
+	rt, err := NewHybrid(paths)
+	require.NoError(t, err)
+
+	_, err = rt.AddService(recipe.Service{
+		Name:    "redis",
+		Label:   []string{InfraLabel},
+		Image:   "redis:6.0.9",
+		Command: []string{"redis-server"},
+	})
+	require.NoError(t, err)
+
+	_, err = rt.AddService(recipe.Service{
+		Name:    "uplink",
+		Command: []string{"uplink"},
+		Environment: map[string]string{
+			"REDIS": `{{ Environment "redis" "url" }}`,
+			"HOST":  `{{ Host "redis" "internal" }}`,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, rt.Write())
+
+	compose, err := os.ReadFile(filepath.Join(tempDir, common.ComposeFileName))
+	require.NoError(t, err)
+	require.Contains(t, string(compose), "redis:6.0.9")
+	require.Contains(t, string(compose), "6379")
+	require.NotContains(t, string(compose), "uplink")
+
+	script, err := os.ReadFile(filepath.Join(tempDir, "uplink.sh"))
+	require.NoError(t, err)
+	require.Contains(t, string(script), "redis://localhost:6379")
+	require.FileExists(t, filepath.Join(tempDir, standalone.StateFileName))
+	require.NoFileExists(t, filepath.Join(tempDir, "redis.sh"))
+
+	reloaded, err := NewHybrid(paths)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+
+	var names []string
+	for _, s := range reloaded.GetServices() {
+		names = append(names, s.ID().Name)
+	}
+	require.ElementsMatch(t, []string{"redis", "uplink"}, names)
+	require.Len(t, reloaded.Native().GetServices(), 1)
+	require.Equal(t, "localhost", reloaded.GetHost(runtime.NewServiceInstance("redis", 0), "internal"))
+
+	t.Setenv("STORJ_DOCKER_HOST", "10.0.0.1")
+	require.Equal(t, "redis://10.0.0.1:6379", reloaded.Get(runtime.NewServiceInstance("redis", 0), "url"))
+	require.Equal(t, "postgres://postgres@10.0.0.1:5432/master?sslmode=disable", reloaded.Get(runtime.NewServiceInstance("postgres", 0), "main"))
+	require.Equal(t, "postgres://postgres@10.0.0.1:5432/master?sslmode=disable", reloaded.Get(runtime.NewServiceInstance("postgres", 0), "metainfo"))
+}
+
+func TestHybridInfraPorts(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewHybrid(standalone.Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	})
+	require.NoError(t, err)
+
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+	for _, name := range []string{"db", "billing", "mailserver"} {
+		r, err := st.Get(name)
+		require.NoError(t, err)
+		for _, s := range r.Add {
+			if s.HasLabel(InfraLabel) {
+				_, err = rt.AddService(*s)
+				require.NoError(t, err)
+			}
+		}
+	}
+
+	published := map[string][]string{}
+	for _, spec := range rt.Specs() {
+		published[spec.Name] = spec.Ports
+	}
+	require.Contains(t, published["redis"], "6379:6379/tcp")
+	require.Contains(t, published["postgres"], "5432:5432")
+	require.Contains(t, published["geth"], "8545:8545")
+	require.Contains(t, published["mailserver"], "1025:1025")
+	for name, ports := range published {
+		require.Len(t, ports, len(slices.Compact(slices.Sorted(slices.Values(ports)))), name)
+	}
+
+	require.Equal(t, []int{6379}, hostPorts(recipe.Service{Name: "redis"}))
+	require.Empty(t, hostPorts(recipe.Service{Name: "redis", Port: []recipe.PortDefinition{{Name: "redis", Target: 6379}}}))
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+)
+
+// Databases are the recipes of the satellite database backends. The configuration of the satellite services is
+// defined by the modify rules of the recipes.
+var Databases = []string{"cockroach", "postgres", "spanner"}
+
+// UseDatabase switches the satellite services to a database backend: the services of the other backends are removed
+// (unless other services, like storjscan, use them), the services of the backend are added (if missing), and the
+// database configuration of the satellite services is replaced by the configuration of the backend. Satellite services
+// are the services which are configured by the modify rules of the database recipes. Configuration keys of the other
+// backends (like SPANNER_EMULATOR_HOST) are removed. It returns with the names of the reconfigured services.
+func UseDatabase(st recipe.Stack, rt Runtime, backend string) ([]string, error) {
+	if !slices.Contains(Databases, backend) {
+		return nil, errs.Errorf("unknown database %s (supported databases: %s)", backend, strings.Join(Databases, ", "))
+	}
+	target, err := st.Get(backend)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := map[string]bool{}
+	for _, s := range target.Add {
+		keep[s.Name] = true
+	}
+	config := map[string]string{}
+	for _, mod := range target.Modify {
+		for k, v := range mod.Config {
+			config[k] = v
+		}
+	}
+	var matchers []recipe.Matcher
+	databaseServices := map[string]bool{}
+	stale := map[string]bool{}
+	for _, name := range Databases {
+		r, err := st.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range r.Add {
+			databaseServices[s.Name] = true
+		}
+		for _, mod := range r.Modify {
+			matchers = append(matchers, mod.Match)
+			if name == backend {
+				continue
+			}
+			for k := range mod.Config {
+				if _, found := config[k]; !found {
+					stale[k] = true
+				}
+			}
+		}
+	}
+	satellite := func(s Service) bool {
+		if slices.ContainsFunc(matchers, func(m recipe.Matcher) bool { return Match(s, m) }) {
+			return true
+		}
+		r, err := st.FindRecipeByName(s.ID().Name)
+		if err != nil {
+			return false
+		}
+		_, inConfig := r.Config["STORJ_DATABASE"]
+		_, inEnvironment := r.Environment["STORJ_DATABASE"]
+		return inConfig || inEnvironment
+	}
+
+	for name := range databaseServices {
+		if keep[name] || usedByOthers(st, rt, name, func(s Service) bool { return satellite(s) || databaseServices[s.ID().Name] }) {
+			continue
+		}
+		for _, existing := range rt.GetServices() {
+			if existing.ID().Name == name {
+				if err := rt.RemoveService(existing.ID()); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	for _, s := range target.Add {
+		if !slices.ContainsFunc(rt.GetServices(), func(existing Service) bool { return existing.ID().Name == s.Name }) {
+			if err := AddServiceToRuntime(rt, *s); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var changed []string
+	for _, s := range rt.GetServices() {
+		if !satellite(s) {
+			continue
+		}
+		for k, v := range config {
+			if err := s.AddConfig(k, v); err != nil {
+				return nil, err
+			}
+		}
+		for k := range stale {
+			if remover, ok := s.(EnvironmentRemover); ok {
+				err = remover.RemoveEnvironment(k)
+			} else if _, found := s.GetENV()[k]; found {
+				err = s.AddEnvironment(k, "")
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		changed = append(changed, s.ID().String())
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// usedByOthers returns true if a service (except the skipped ones) refers to the database service in the templates of
+// its recipe (like storjscan to postgres). Rendered values can't be used, the native services are all on localhost.
+func usedByOthers(st recipe.Stack, rt Runtime, name string, skip func(Service) bool) bool {
+	reference := regexp.MustCompile(`\b(Host|Port|Environment)\s+"` + regexp.QuoteMeta(name) + `"`)
+	for _, s := range rt.GetServices() {
+		if skip(s) {
+			continue
+		}
+		r, err := st.FindRecipeByName(s.ID().Name)
+		if err != nil {
+			continue
+		}
+		templates := slices.Clone(r.Command)
+		for _, v := range r.Environment {
+			templates = append(templates, v)
+		}
+		for _, v := range r.Config {
+			templates = append(templates, v)
+		}
+		if slices.ContainsFunc(templates, reference.MatchString) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/recipe"
+)
+
+func TestUseDatabaseConfig(t *testing.T) {
+	st, err := recipe.GetEmbeddedStack()
+	require.NoError(t, err)
+
+	// database configuration is stored as config (like in standalone), not in the environment
+	rt := NewMockRuntime()
+	for _, name := range []string{"satellite-api", "satellite-gc", "storagenode", "spanner"} {
+		r, err := st.FindRecipeByName(name)
+		require.NoError(t, err)
+		s := NewMockService(name)
+		s.Label = r.Label
+		s.Config["STORJ_DATABASE"] = "spanner://projects/test-project/instances/test-instance/databases/master"
+		s.Config["SPANNER_EMULATOR_HOST"] = "localhost:9010"
+		rt.Services = append(rt.Services, s)
+	}
+	rt.Services[2].(*MockService).Config = map[string]string{}
+
+	changed, err := UseDatabase(st, rt, "postgres")
+	require.NoError(t, err)
+	require.Equal(t, []string{"satellite-api/0", "satellite-gc/0"}, changed)
+	for _, s := range rt.Services {
+		require.NotEqual(t, "spanner", s.ID().Name)
+	}
+	require.Contains(t, rt.Services[0].(*MockService).Config["STORJ_DATABASE"], "postgres")
+	require.Contains(t, rt.Services[1].(*MockService).Config["STORJ_DATABASE"], "postgres")
+	require.Empty(t, rt.Services[2].(*MockService).Config)
+}
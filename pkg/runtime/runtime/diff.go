@@ -0,0 +1,166 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ServiceSpec is the comparable definition of one service instance.
+type ServiceSpec struct {
+	Name string
+	// Image is the container image, or the executed binary of native services.
+	Image       string
+	Environment map[string]string
+	Flags       []string
+	Ports       []string
+	Volumes     []string
+}
+
+// Specifier is implemented by runtimes which can export the definition of all the services.
+type Specifier interface {
+	Specs() []ServiceSpec
+}
+
+// WriteDiff prints the service level differences between two sets of services, in unified diff like format.
+// Returns with false if there are no differences.
+func WriteDiff(w io.Writer, fromName string, from []ServiceSpec, toName string, to []ServiceSpec) (bool, error) {
+	before := specsByName(from)
+	after := specsByName(to)
+
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, found := before[name]; !found {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := &strings.Builder{}
+	for _, name := range names {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		var lines []string
+		header := "@@ " + name + " @@"
+		switch {
+		case !inBefore:
+			header = "@@ " + name + " (added) @@"
+			lines = specLines("+", a)
+		case !inAfter:
+			header = "@@ " + name + " (removed) @@"
+			lines = specLines("-", b)
+		default:
+			lines = changedLines(b, a)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		out.WriteString(header + "\n")
+		for _, line := range lines {
+			out.WriteString(line + "\n")
+		}
+	}
+	if out.Len() == 0 {
+		return false, nil
+	}
+	_, err := fmt.Fprintf(w, "--- %s\n+++ %s\n%s", fromName, toName, out.String())
+	return true, err
+}
+
+func specsByName(specs []ServiceSpec) map[string]ServiceSpec {
+	res := map[string]ServiceSpec{}
+	for _, s := range specs {
+		res[s.Name] = s
+	}
+	return res
+}
+
+// specLines returns with all the properties of a service, prefixed with the marker.
+func specLines(marker string, s ServiceSpec) (lines []string) {
+	if s.Image != "" {
+		lines = append(lines, marker+"image "+s.Image)
+	}
+	for _, k := range sortedKeys(s.Environment) {
+		lines = append(lines, marker+"env "+k+"="+s.Environment[k])
+	}
+	for _, f := range s.Flags {
+		lines = append(lines, marker+"flag "+f)
+	}
+	for _, p := range s.Ports {
+		lines = append(lines, marker+"port "+p)
+	}
+	for _, v := range s.Volumes {
+		lines = append(lines, marker+"volume "+v)
+	}
+	return lines
+}
+
+// changedLines returns with the removed (-) and added (+) properties of a service.
+func changedLines(before, after ServiceSpec) (lines []string) {
+	if before.Image != after.Image {
+		if before.Image != "" {
+			lines = append(lines, "-image "+before.Image)
+		}
+		if after.Image != "" {
+			lines = append(lines, "+image "+after.Image)
+		}
+	}
+
+	keys := sortedKeys(before.Environment)
+	for _, k := range sortedKeys(after.Environment) {
+		if _, found := before.Environment[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b, inBefore := before.Environment[k]
+		a, inAfter := after.Environment[k]
+		if inBefore && inAfter && a == b {
+			continue
+		}
+		if inBefore {
+			lines = append(lines, "-env "+k+"="+b)
+		}
+		if inAfter {
+			lines = append(lines, "+env "+k+"="+a)
+		}
+	}
+
+	lines = append(lines, listChanges("flag", before.Flags, after.Flags)...)
+	lines = append(lines, listChanges("port", before.Ports, after.Ports)...)
+	lines = append(lines, listChanges("volume", before.Volumes, after.Volumes)...)
+	return lines
+}
+
+// listChanges compares two lists, ignoring the order of the elements.
+func listChanges(kind string, before, after []string) (lines []string) {
+	for _, b := range before {
+		if !slices.Contains(after, b) {
+			lines = append(lines, "-"+kind+" "+b)
+		}
+	}
+	for _, a := range after {
+		if !slices.Contains(before, a) {
+			lines = append(lines, "+"+kind+" "+a)
+		}
+	}
+	return lines
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
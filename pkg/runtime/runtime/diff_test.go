@@ -0,0 +1,61 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDiff(t *testing.T) {
+	before := []ServiceSpec{
+		{
+			Name:        "satellite-api",
+			Image:       "storj:1",
+			Environment: map[string]string{"A": "1", "B": "2"},
+			Flags:       []string{"--x=1"},
+		},
+		{Name: "storagenode2", Image: "storj:1"},
+	}
+	after := []ServiceSpec{
+		{
+			Name:        "satellite-api",
+			Image:       "storj:2",
+			Environment: map[string]string{"B": "3", "C": "4"},
+			Flags:       []string{"--x=1", "--y=2"},
+			Ports:       []string{"10008:2345/tcp"},
+		},
+		{Name: "storagenode3", Image: "storj:1", Volumes: []string{"/data:/var/lib/storj"}},
+	}
+
+	out := &bytes.Buffer{}
+	changed, err := WriteDiff(out, "before", before, "after", after)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, `--- before
++++ after
+@@ satellite-api @@
+-image storj:1
++image storj:2
+-env A=1
+-env B=2
++env B=3
++env C=4
++flag --y=2
++port 10008:2345/tcp
+@@ storagenode2 (removed) @@
+-image storj:1
+@@ storagenode3 (added) @@
++image storj:1
++volume /data:/var/lib/storj
+`, out.String())
+
+	out.Reset()
+	changed, err = WriteDiff(out, "before", before, "after", before)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, out.String())
+}
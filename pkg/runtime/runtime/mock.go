@@ -3,7 +3,11 @@
 
 package runtime
 
-import "storj.io/storj-up/pkg/recipe"
+import (
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/recipe"
+)
 
 // MockService is a service implementation for MockRuntime.
 type MockService struct {
@@ -21,8 +25,11 @@ var _ Service = (*MockService)(nil)
 
 // GetENV implements runtime.Service.
 func (m *MockService) GetENV() map[string]*string {
-	// TODO implement me
-	panic("implement me")
+	env := map[string]*string{}
+	for k, v := range m.Environment {
+		env[k] = &v
+	}
+	return env
 }
 
 // GetVolumes implements runtime.Service.
@@ -51,6 +58,7 @@ func NewMockService(name string) *MockService {
 		Environment: map[string]string{},
 		Flag:        []string{},
 		Persisted:   []string{},
+		Ports:       map[int]int{},
 	}
 }
 
@@ -106,7 +114,7 @@ func (m *MockService) RemovePortForward(portMap PortMap) error {
 }
 
 // Persist  implements runtime.Service.
-func (m *MockService) Persist(dir string) error {
+func (m *MockService) Persist(dir string, persistType string) error {
 	m.Persisted = append(m.Persisted, dir)
 	return nil
 }
@@ -145,6 +153,12 @@ func (m *MockRuntime) Get(serviceInstance ServiceInstance, name string) string {
 // AddService implements runtime.Runtime.
 func (m *MockRuntime) AddService(service recipe.Service) (Service, error) {
 	s := NewMockService(service.Name)
+	for _, o := range m.Services {
+		if o.ID().Name == service.Name {
+			s.Identifier.Instance++
+		}
+	}
+	s.Label = service.Label
 	err := InitFromRecipe(s, service)
 	if err != nil {
 		return s, err
@@ -153,6 +167,17 @@ func (m *MockRuntime) AddService(service recipe.Service) (Service, error) {
 	return s, nil
 }
 
+// RemoveService implements runtime.Runtime.
+func (m *MockRuntime) RemoveService(instance ServiceInstance) error {
+	for ix, s := range m.Services {
+		if s.ID() == instance {
+			m.Services = append(m.Services[:ix], m.Services[ix+1:]...)
+			return nil
+		}
+	}
+	return errs.Errorf("no such service: %s", instance)
+}
+
 // Write implements runtime.Runtime.
 func (m *MockRuntime) Write() error {
 	return nil
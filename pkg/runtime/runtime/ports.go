@@ -4,6 +4,8 @@
 package runtime
 
 import (
+	"slices"
+
 	"github.com/zeebo/errs"
 )
 
@@ -21,6 +23,9 @@ var basePorts = map[string]int{
 	"authservice":          21000,
 	"linksharing":          22200,
 	"versioncontrol":       23000,
+	"storjscan":            24000,
+	"multinode":            24100,
+	"uplink":               24200,
 }
 
 // PortConvention defines port numbers for any services.
@@ -37,8 +42,55 @@ func PortConvention(instance ServiceInstance, portType string) (int, error) {
 		port++
 	case "private":
 		port += 2
+	case "delve":
+		port += 8
 	case "debug":
 		port += 9
 	}
 	return port, nil
 }
+
+// delveServices are the services of the recipes without base port, in a stable order. Their conventional Delve ports
+// are allocated from the reserved range (starting from delvePortRange), 10 ports per service (one for each of the
+// first 10 instances). The remaining ports of the range are allocated on demand.
+var delveServices = []string{
+	"cockroach",
+	"geth",
+	"jaeger",
+	"mailserver",
+	"postgres",
+	"redis",
+	"spanner",
+}
+
+const (
+	delvePortRange = 25000
+	delvePortSlots = 500
+)
+
+// DelvePort returns with the port of the Delve debugger of the service instance. The conventional port is used (base
+// port + 8, or the slot of the service in the reserved range), unless it's already used. Otherwise (and for the
+// services which are unknown to the recipes) the first free port of the reserved range is allocated after the slots
+// of the known services. The allocated port is stored by the runtimes, so it doesn't change later.
+func DelvePort(instance ServiceInstance, used []int) (int, error) {
+	if port, found := conventionalDelvePort(instance); found && !slices.Contains(used, port) {
+		return port, nil
+	}
+	for port := delvePortRange + len(delveServices)*10; port < delvePortRange+delvePortSlots*10; port++ {
+		if !slices.Contains(used, port) {
+			return port, nil
+		}
+	}
+	return 0, errs.New("no free Delve port for %s", instance.String())
+}
+
+// conventionalDelvePort returns with the preferred Delve port of the service instance, if there is any.
+func conventionalDelvePort(instance ServiceInstance) (int, bool) {
+	if port, err := PortConvention(instance, "delve"); err == nil {
+		return port, true
+	}
+	if i := slices.Index(delveServices, instance.Name); i >= 0 && instance.Instance < 10 {
+		return delvePortRange + i*10 + instance.Instance, true
+	}
+	return 0, false
+}
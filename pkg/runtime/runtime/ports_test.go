@@ -0,0 +1,46 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDelvePort(t *testing.T) {
+	delvePort := func(name string, instance int, used ...int) int {
+		port, err := DelvePort(NewServiceInstance(name, instance), used)
+		require.NoError(t, err)
+		return port
+	}
+	require.Equal(t, 10008, delvePort("satellite-api", 0))
+	require.Equal(t, 30018, delvePort("storagenode", 1))
+
+	// services without base port
+	redis := delvePort("redis", 0)
+	postgres := delvePort("postgres", 0)
+	require.NotEqual(t, redis, postgres)
+	require.NotEqual(t, redis, delvePort("redis", 1))
+	require.Equal(t, redis, delvePort("redis", 0))
+
+	// used ports (like the conventional port of the 11th instance) are not allocated again
+	first := delvePortRange + len(delveServices)*10
+	require.Equal(t, first, delvePort("redis", 10, redis))
+	require.Equal(t, first+1, delvePort("redis", 11, redis, first))
+	require.Equal(t, first, delvePort("satellite-api", 0, 10008))
+	require.Equal(t, first, delvePort("foo", 0))
+	require.Equal(t, first+1, delvePort("bar", 0, first))
+
+	var all []int
+	for port := delvePortRange; port < delvePortRange+delvePortSlots*10; port++ {
+		all = append(all, port)
+	}
+	_, err := DelvePort(NewServiceInstance("foo", 0), all)
+	require.Error(t, err)
+
+	for _, base := range basePorts {
+		require.False(t, base >= delvePortRange && base < delvePortRange+delvePortSlots*10)
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package runtime
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// instanceSelector is a selector of the instances of a service: 1-based indexes and index ranges (like
+// storagenode[1-5] or storagenode[1,3,7-9]), or a percentage of the instances (like storagenode[50%]).
+var instanceSelector = regexp.MustCompile(`^([^\[\]]+)\[([^\[\]]+)\]$`)
+
+// SplitSelectors splits comma separated selectors. Commas of the instance-level selectors (like storagenode[1,3]) are
+// not separators.
+func SplitSelectors(selectors string) []string {
+	var res []string
+	depth, start := 0, 0
+	for i, c := range selectors {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				res = append(res, selectors[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(res, selectors[start:])
+}
+
+// SelectInstances returns with the instances of the service matched by an instance-level selector, ordered by the
+// instance index. found is false, if the selector is not an instance-level selector.
+func SelectInstances(rt Runtime, selector string) (selected []Service, found bool, err error) {
+	match := instanceSelector.FindStringSubmatch(selector)
+	if match == nil {
+		return nil, false, nil
+	}
+	name, spec := match[1], strings.TrimSpace(match[2])
+	var instances []Service
+	for _, s := range rt.GetServices() {
+		if s.ID().Name == name {
+			instances = append(instances, s)
+		}
+	}
+	if len(instances) == 0 {
+		return nil, true, errs.Errorf("no instance of %s is found", name)
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ID().Instance < instances[j].ID().Instance
+	})
+
+	if percent, isPercent := strings.CutSuffix(spec, "%"); isPercent {
+		p, err := strconv.Atoi(strings.TrimSpace(percent))
+		if err != nil || p < 0 || p > 100 {
+			return nil, true, errs.Errorf("invalid percentage in selector %s", selector)
+		}
+		// rounded up, so any positive percentage selects at least one instance
+		return instances[:(len(instances)*p+99)/100], true, nil
+	}
+
+	selectedIndex := map[int]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		first, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, true, errs.Errorf("invalid index %q in selector %s", part, selector)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil || last < first {
+				return nil, true, errs.Errorf("invalid range %q in selector %s", part, selector)
+			}
+		}
+		if first < 1 || last > len(instances) {
+			return nil, true, errs.Errorf("%s is out of range: %s has %d instances", part, name, len(instances))
+		}
+		for i := first; i <= last; i++ {
+			selectedIndex[i-1] = true
+		}
+	}
+	for i, s := range instances {
+		if selectedIndex[i] {
+			selected = append(selected, s)
+		}
+	}
+	return selected, true, nil
+}
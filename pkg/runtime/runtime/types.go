@@ -27,14 +27,21 @@ type VariableGetter interface {
 	Get(serviceInstance ServiceInstance, name string) string
 }
 
-// Runtime provides methods to read/write/modify any existing runtime definition (like compose/...)
-type Runtime interface {
+// Resolver can resolve all the template functions (hosts, ports and variables) of the recipes.
+type Resolver interface {
 	HostResolver
 	PortResolver
 	VariableGetter
+}
+
+// Runtime provides methods to read/write/modify any existing runtime definition (like compose/...)
+type Runtime interface {
+	Resolver
 
 	// AddService creates and adds new service instance based on the recipe.
 	AddService(recipe.Service) (Service, error)
+	// RemoveService removes one service instance.
+	RemoveService(ServiceInstance) error
 	Write() error
 	GetServices() []Service
 	Reload(stack recipe.Stack) error
@@ -54,6 +61,19 @@ type VolumeMount struct {
 	Target    string
 }
 
+// Types of persistence (see Service.Persist).
+const (
+	// PersistBind mounts a host directory.
+	PersistBind = "bind"
+	// PersistVolume uses a named volume.
+	PersistVolume = "volume"
+	// PersistTmpfs uses an in-memory file system, the content is lost at restart (useful for fast, throwaway runs).
+	PersistTmpfs = "tmpfs"
+)
+
+// PersistTypes are the supported types of persistence.
+var PersistTypes = []string{PersistBind, PersistVolume, PersistTmpfs}
+
 // ManageableNetwork is the interface to configure docker networks.
 type ManageableNetwork interface {
 	AddNetwork(string) error
@@ -82,13 +102,68 @@ type Service interface {
 	AddPortForward(PortMap) error
 	RemovePortForward(PortMap) error
 
-	Persist(dir string) error
+	// Persist keeps the content of the directory between restarts, using one of the PersistTypes.
+	Persist(dir string, persistType string) error
 	Labels() []string
 
 	UseFile(path string, name string, data string) error
 	UseFolder(path string, name string) error
 }
 
+// Debuggable is implemented by services which can be started with a headless Delve debugger.
+type Debuggable interface {
+	// SetDebug turns on/off the debugger.
+	SetDebug(enabled bool) error
+	// DebugPort returns with the port where Delve is available from the host, or 0 if debug is not enabled.
+	DebugPort() int
+}
+
+// EnvironmentRemover is implemented by services which can remove environment variables (instead of setting them to
+// empty).
+type EnvironmentRemover interface {
+	RemoveEnvironment(key string) error
+}
+
+// Describer is implemented by services which can summarize their configuration (used by `storj-up list`).
+type Describer interface {
+	Describe() ServiceInfo
+}
+
+// ServiceInfo is the summary of a configured service instance.
+type ServiceInfo struct {
+	Instance string `json:"instance" yaml:"instance"`
+	// Source is the image, or the origin of the binary if it's built or mounted from a local directory.
+	Source    string   `json:"source" yaml:"source"`
+	Ports     []string `json:"ports,omitempty" yaml:"ports,omitempty"`
+	Persisted []string `json:"persisted,omitempty" yaml:"persisted,omitempty"`
+	// Debug is the port of the Delve server, or 0 if debug is not enabled.
+	Debug  int      `json:"debug,omitempty" yaml:"debug,omitempty"`
+	Labels []string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Cleaner is implemented by runtimes which know where the services store their data (used by `storj-up clean`).
+type Cleaner interface {
+	// State returns with the data of a service instance. persisted is the list of the persisted directories, defined
+	// by the recipe.
+	State(s Service, persisted []string) ServiceState
+}
+
+// ServiceState is the data of a service instance, which is created at runtime (databases, pieces, migration markers).
+type ServiceState struct {
+	// Name is the name of the compose service, or the name of the generated script.
+	Name string
+	// Container is true if the service is running in a container.
+	Container bool
+	// Paths are the files and directories on the host.
+	Paths []string
+	// Volumes are the named docker volumes.
+	Volumes []string
+	// Internal are the persisted directories which are not mounted, therefore stored only in the container.
+	Internal []string
+	// Config are the generated configuration files (configuration, identity) on the host, which are not part of the data.
+	Config []string
+}
+
 // ServiceInstance is a unique identifier of a service instance.
 type ServiceInstance struct {
 	Name     string
@@ -140,8 +215,16 @@ func NewServiceInstance(name string, i int) ServiceInstance {
 func ModifyService(stack recipe.Stack, rt Runtime, selectors []string, f func(service Service) error) error {
 	for _, oneOrMoreSelector := range selectors {
 
-		for selector := range strings.SplitSeq(oneOrMoreSelector, ",") {
-			found := false
+		for _, selector := range SplitSelectors(oneOrMoreSelector) {
+			instances, found, err := SelectInstances(rt, selector)
+			if err != nil {
+				return err
+			}
+			for _, s := range instances {
+				if err := f(s); err != nil {
+					return err
+				}
+			}
 
 			for _, s := range rt.GetServices() {
 				if s.ID().Name == selector { // selector can be the generic name of service (eg. storagenode without index)
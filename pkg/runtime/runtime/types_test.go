@@ -8,6 +8,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"storj.io/storj-up/pkg/recipe"
 )
 
 func TestServiceInstanceFromIndexedName(t *testing.T) {
@@ -93,3 +95,44 @@ func Test_setFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestModifyServiceInstances(t *testing.T) {
+	rt := NewMockRuntime()
+	for i := 0; i < 10; i++ {
+		_, err := rt.AddService(recipe.Service{Name: "storagenode"})
+		require.NoError(t, err)
+	}
+	_, err := rt.AddService(recipe.Service{Name: "satellite-api"})
+	require.NoError(t, err)
+
+	selected := func(selectors ...string) (res []int, err error) {
+		err = ModifyService(recipe.Stack{}, rt, selectors, func(s Service) error {
+			res = append(res, s.ID().Instance+1)
+			return nil
+		})
+		return res, err
+	}
+
+	res, err := selected("storagenode[1-3]")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3}, res)
+
+	res, err = selected("storagenode[1,3,7-9],satellite-api")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 3, 7, 8, 9, 1}, res)
+
+	res, err = selected("storagenode[25%]", "storagenode10")
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2, 3, 10}, res)
+
+	res, err = selected("storagenode[0%]")
+	require.NoError(t, err)
+	require.Empty(t, res)
+
+	for _, invalid := range []string{"storagenode[0]", "storagenode[5-11]", "storagenode[3-1]", "storagenode[x]", "storagenode[120%]", "gateway-mt[1]"} {
+		_, err = selected(invalid)
+		require.Error(t, err, invalid)
+	}
+
+	require.Equal(t, []string{"a[1,2]", "b", "c[10%]"}, SplitSelectors("a[1,2],b,c[10%]"))
+}
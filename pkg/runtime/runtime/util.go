@@ -229,3 +229,100 @@ func AddServiceToRuntime(c Runtime, r recipe.Service) error {
 
 	return nil
 }
+
+// Scale adds or removes instances of the selected services (service or recipe names), to have exactly the requested number of instances.
+// New instances are created from the recipe, and modified by the recipes which are already part of the environment.
+// Scaling down removes the highest numbered instances.
+func Scale(st recipe.Stack, rt Runtime, selectors []string, instances int) error {
+	if instances < 1 {
+		return errs.Errorf("number of instances should be at least 1, not %d", instances)
+	}
+	var names []string
+	for _, oneOrMoreSelector := range selectors {
+		for selector := range strings.SplitSeq(oneOrMoreSelector, ",") {
+			if _, err := st.FindRecipeByName(selector); err == nil {
+				names = append(names, selector)
+				continue
+			}
+			r, err := st.Get(selector)
+			if err != nil {
+				return errs.Errorf("couldn't find service or recipe with the name %s", selector)
+			}
+			for _, s := range r.Add {
+				names = append(names, s.Name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		var existing []Service
+		for _, s := range rt.GetServices() {
+			if s.ID().Name == name {
+				existing = append(existing, s)
+			}
+		}
+		slices.SortFunc(existing, func(a, b Service) int {
+			return a.ID().Instance - b.ID().Instance
+		})
+
+		for i := len(existing) - 1; i >= instances; i-- {
+			err := rt.RemoveService(existing[i].ID())
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(existing) >= instances {
+			continue
+		}
+		service, err := st.FindRecipeByName(name)
+		if err != nil {
+			return err
+		}
+		applied := appliedRecipes(st, rt)
+		for i := len(existing); i < instances; i++ {
+			s, err := rt.AddService(*service)
+			if err != nil {
+				return err
+			}
+			for _, r := range applied {
+				for _, mod := range r.Modify {
+					if Match(s, mod.Match) {
+						err := ModifyFromRecipe(s, *mod)
+						if err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// appliedRecipes returns the recipes where all the services are part of the runtime, in the order of priority (same as ApplyRecipes).
+func appliedRecipes(st recipe.Stack, rt Runtime) []recipe.Recipe {
+	existing := map[string]bool{}
+	for _, s := range rt.GetServices() {
+		existing[s.ID().Name] = true
+	}
+	var applied []recipe.Recipe
+	for _, r := range st {
+		if len(r.Add) == 0 {
+			continue
+		}
+		all := true
+		for _, s := range r.Add {
+			if !existing[s.Name] {
+				all = false
+			}
+		}
+		if all {
+			applied = append(applied, r)
+		}
+	}
+	slices.SortStableFunc(applied, func(a, b recipe.Recipe) int {
+		return b.Priority - a.Priority
+	})
+	return applied
+}
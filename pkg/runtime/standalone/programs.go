@@ -0,0 +1,66 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package standalone
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"storj.io/storj-up/pkg/supervisor"
+)
+
+// dependencies defines the services which should be running before a service is started.
+// Dependencies which are not part of the environment are ignored.
+var dependencies = map[string][]string{
+	"satellite-api": {"cockroach", "postgres", "spanner", "redis"},
+	"storagenode":   {"satellite-api"},
+	"authservice":   {"satellite-api"},
+	"gateway-mt":    {"authservice"},
+	"linksharing":   {"authservice"},
+	"storjscan":     {"geth", "postgres"},
+}
+
+func dependsOn(name string) []string {
+	if deps, found := dependencies[name]; found {
+		return deps
+	}
+	if strings.HasPrefix(name, "satellite-") {
+		return []string{"satellite-api"}
+	}
+	return nil
+}
+
+// Programs returns with the supervised form of the generated scripts (same as the programs of supervisord.conf).
+func (c *Standalone) Programs() []supervisor.Program {
+	var programs []supervisor.Program
+	for _, s := range c.services {
+		var deps []string
+		for _, dep := range dependsOn(s.id.Name) {
+			for _, o := range c.services {
+				if o.id.Name == dep {
+					deps = append(deps, c.uniqueName(o))
+				}
+			}
+		}
+		programs = append(programs, supervisor.Program{
+			Name:      c.uniqueName(s),
+			Command:   []string{filepath.Join(c.dir, c.uniqueName(s)+".sh")},
+			Dir:       c.dir,
+			LogDir:    filepath.Join(c.dir, s.id.Name, strconv.Itoa(s.id.Instance)),
+			DependsOn: deps,
+		})
+	}
+	return programs
+}
+
+// Dir returns with the directory of the environment.
+func (c *Standalone) Dir() string {
+	return c.dir
+}
+
+// Generated returns with the files (relative to the directory of the environment) which are generated from the state.
+func (c *Standalone) Generated() []string {
+	return c.generated
+}
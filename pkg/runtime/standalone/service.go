@@ -5,9 +5,12 @@ package standalone
 
 import (
 	"fmt"
+	"os/exec"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/zeebo/errs/v2"
 	"golang.org/x/exp/slices"
 
 	"storj.io/storj-up/pkg/runtime/runtime"
@@ -21,9 +24,82 @@ type service struct {
 	config      []string
 	Environment map[string]string
 	labels      []string
+	debug       bool
+	// delvePort is the allocated port of the debugger, and usedPorts returns with the ports of the other debuggers.
+	delvePort int
+	usedPorts func() []int
+	ports     []runtime.PortMap
+	// dir is the working directory of the service instance.
+	dir string
 }
 
 var _ runtime.Service = (*service)(nil)
+var _ runtime.Debuggable = (*service)(nil)
+var _ runtime.Describer = (*service)(nil)
+
+// SetDebug implements runtime.Debuggable. The generated script starts the process with a headless Delve server.
+func (s *service) SetDebug(enabled bool) error {
+	if enabled && len(s.Command) == 0 {
+		return errs.Errorf("%s has no command to debug", s.id)
+	}
+	if !enabled {
+		s.debug, s.delvePort = false, 0
+		return nil
+	}
+	var used []int
+	if s.usedPorts != nil {
+		used = s.usedPorts()
+	}
+	if s.delvePort == 0 || slices.Contains(used, s.delvePort) {
+		port, err := runtime.DelvePort(s.id, used)
+		if err != nil {
+			return err
+		}
+		s.delvePort = port
+	}
+	s.debug = true
+	return nil
+}
+
+// DebugPort implements runtime.Debuggable.
+func (s *service) DebugPort() int {
+	if !s.debug {
+		return 0
+	}
+	if s.delvePort == 0 {
+		// the port is not allocated by earlier versions
+		port, _ := runtime.DelvePort(s.id, nil)
+		return port
+	}
+	return s.delvePort
+}
+
+// Describe implements runtime.Describer.
+func (s *service) Describe() runtime.ServiceInfo {
+	info := runtime.ServiceInfo{
+		Instance: s.id.String(),
+		Debug:    s.DebugPort(),
+		Labels:   s.labels,
+	}
+	if len(s.Command) > 0 {
+		binary := s.Command[0]
+		if path, err := exec.LookPath(binary); err == nil {
+			binary = path
+		}
+		info.Source = "local bin " + binary
+	}
+	for _, p := range s.ports {
+		port := strconv.Itoa(p.Internal)
+		if p.Protocol != "" {
+			port += "/" + p.Protocol
+		}
+		info.Ports = append(info.Ports, port)
+	}
+	if s.dir != "" {
+		info.Persisted = append(info.Persisted, s.dir)
+	}
+	return info
+}
 
 func (s *service) GetVolumes() []runtime.VolumeMount {
 	// TODO implement me
@@ -31,8 +107,11 @@ func (s *service) GetVolumes() []runtime.VolumeMount {
 }
 
 func (s *service) GetENV() map[string]*string {
-	// TODO implement me
-	return nil
+	env := map[string]*string{}
+	for k, v := range s.Environment {
+		env[k] = &v
+	}
+	return env
 }
 
 func (s *service) UseFolder(path string, name string) error {
@@ -54,7 +133,7 @@ func (s *service) RemoveFlag(flag string) error {
 	return nil
 }
 
-func (s *service) Persist(dir string) error {
+func (s *service) Persist(dir string, persistType string) error {
 	// NOOP: Standalone runner doesn't use containers. No need to persist directories
 	return nil
 }
@@ -64,13 +143,16 @@ func (s *service) ChangeImage(func(string) string) error {
 	return nil
 }
 
-func (s *service) AddPortForward(runtime.PortMap) error {
-	// all ports are available, by default...
+func (s *service) AddPortForward(port runtime.PortMap) error {
+	// all ports are available, by default, only recorded to show them in the service summary.
+	s.ports = append(s.ports, port)
 	return nil
 }
 
-func (s *service) RemovePortForward(runtime.PortMap) error {
-	// all ports are available, by default...
+func (s *service) RemovePortForward(port runtime.PortMap) error {
+	s.ports = slices.DeleteFunc(s.ports, func(p runtime.PortMap) bool {
+		return p.Internal == port.Internal
+	})
 	return nil
 }
 
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -41,8 +42,18 @@ type Standalone struct {
 	services   []*service
 	variables  map[string]map[string]string
 	clean      bool
+	generated  []string
+	removed    []runtime.ServiceInstance
+	resolver   runtime.Resolver
 	Intellij   bool
 	ProjectDir string
+
+	// ProcessManager selects the generated process manager configuration (one of ProcessManagers).
+	ProcessManager string
+
+	// DryRun turns off the file system changes of AddService (identities and configs of the new services), so the
+	// changes can be printed without changing the environment. Write shouldn't be called.
+	DryRun bool
 }
 
 // Paths contains directories required for storj-up standalone instances.
@@ -55,6 +66,20 @@ type Paths struct {
 
 // Reload implements runtime.Runtime.
 func (c *Standalone) Reload(stack recipe.Stack) error {
+	st, err := loadState(c.dir)
+	if err != nil {
+		return err
+	}
+	if st != nil {
+		c.restoreState(st)
+		return nil
+	}
+	return c.reloadLegacy(stack)
+}
+
+// reloadLegacy rediscovers the services from the generated scripts of environments created before the state file existed.
+// Discovered scripts are treated as generated outputs, and replaced by the next Write.
+func (c *Standalone) reloadLegacy(stack recipe.Stack) error {
 	scripts, err := find(c.dir, ".sh")
 	if err != nil {
 		return err
@@ -84,11 +109,8 @@ func (c *Standalone) Reload(stack recipe.Stack) error {
 				if err != nil {
 					return err
 				}
-				err = os.Remove(script)
-				if err != nil {
-					return err
-				}
-				_ = os.Remove(scriptPath + ".run.xml")
+				base := filepath.Base(scriptPath)
+				c.generated = append(c.generated, base+".sh", base+".run.xml")
 			}
 		}
 	}
@@ -177,7 +199,9 @@ func (c *Standalone) GetPort(service runtime.ServiceInstance, portType string) r
 }
 
 var (
-	_ runtime.Runtime = &Standalone{}
+	_ runtime.Runtime   = &Standalone{}
+	_ runtime.Specifier = &Standalone{}
+	_ runtime.Cleaner   = &Standalone{}
 )
 
 // AddService implements runtime.Runtime.
@@ -188,27 +212,31 @@ func (c *Standalone) AddService(recipe recipe.Service) (runtime.Service, error)
 	s := &service{
 		id: id,
 		render: func(s string) (string, error) {
-			return runtime.Render(c, id, s)
+			return runtime.Render(c.resolve(), id, s)
 		},
 		config:      []string{},
 		Command:     []string{},
 		labels:      recipe.Label,
 		Environment: map[string]string{},
 	}
+	s.usedPorts = func() []int { return c.debugPorts(s) }
 	if s.labels == nil {
 		s.labels = []string{}
 	}
 
 	serviceDir := filepath.Join(c.dir, s.id.Name, strconv.Itoa(s.id.Instance))
-	if c.clean {
+	s.dir = serviceDir
+	if c.clean && !c.DryRun {
 		_ = os.RemoveAll(serviceDir)
 	}
-	_ = os.MkdirAll(serviceDir, 0755)
+	if !c.DryRun {
+		_ = os.MkdirAll(serviceDir, 0755)
+	}
 
 	var configFile string
 	if recipe.HasLabel("storj") {
 
-		if _, err := os.Stat(filepath.Join(serviceDir, "identity.cert")); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(serviceDir, "identity.cert")); os.IsNotExist(err) && !c.DryRun {
 			err := c.generateIdentity(s.id.Name, s.id.Instance)
 			if err != nil {
 				return nil, err
@@ -216,7 +244,8 @@ func (c *Standalone) AddService(recipe recipe.Service) (runtime.Service, error)
 		}
 
 		configFile = filepath.Join(serviceDir, "config.yaml")
-		if _, err := os.Stat(configFile); os.IsNotExist(err) && len(recipe.Command) > 0 {
+		// generated defaults are commented out, so they are not part of the specs of dry-runs
+		if _, err := os.Stat(configFile); os.IsNotExist(err) && len(recipe.Command) > 0 && !c.DryRun {
 			args := []string{"setup", "--config-dir=" + filepath.Dir(configFile)}
 			if id.Name == "storagenode" {
 				args = append(args, "--identity-dir", filepath.Dir(configFile))
@@ -277,6 +306,43 @@ func (c *Standalone) AddService(recipe recipe.Service) (runtime.Service, error)
 	return s, nil
 }
 
+// RemoveService implements runtime.Runtime. Generated files and the directory of the instance (identity, config and
+// data) are removed by the next Write.
+func (c *Standalone) RemoveService(instance runtime.ServiceInstance) error {
+	for ix, s := range c.services {
+		if s.id == instance {
+			c.services = append(c.services[:ix], c.services[ix+1:]...)
+			c.removed = append(c.removed, instance)
+			return nil
+		}
+	}
+	return errs.Errorf("no such service: %s", instance)
+}
+
+// WithResolver sets a different resolver for the templates of the recipes (eg. when some services are running in containers).
+func (c *Standalone) WithResolver(r runtime.Resolver) *Standalone {
+	c.resolver = r
+	return c
+}
+
+func (c *Standalone) resolve() runtime.Resolver {
+	if c.resolver != nil {
+		return c.resolver
+	}
+	return c
+}
+
+// debugPorts returns with the Delve ports of the debugged services (except one service).
+func (c *Standalone) debugPorts(except *service) []int {
+	var ports []int
+	for _, s := range c.services {
+		if s != except && s.debug {
+			ports = append(ports, s.DebugPort())
+		}
+	}
+	return ports
+}
+
 func (c *Standalone) serviceCount(name string) int {
 	i := 0
 	for _, o := range c.services {
@@ -299,6 +365,10 @@ func NewStandalone(paths Paths) (*Standalone, error) {
 				"metainfo": "cockroach://root@localhost:26257/metainfo?sslmode=disable",
 				"dir":      filepath.Join(paths.ScriptDir, "cockroach", "0", "data"),
 			},
+			"postgres": {
+				"main":     "postgres://postgres@localhost:5432/master?sslmode=disable",
+				"metainfo": "postgres://postgres@localhost:5432/master?sslmode=disable",
+			},
 			"spanner": {
 				"main":         "spanner://projects/test-project/instances/test-instance/databases/master",
 				"metainfo":     "spanner://projects/test-project/instances/test-instance/databases/metainfo",
@@ -395,6 +465,71 @@ func (c *Standalone) generateIdentity(name string, index int) error {
 	return nil
 }
 
+// Specs implements runtime.Specifier. Configuration file entries are included as environment variables.
+func (c *Standalone) Specs() []runtime.ServiceSpec {
+	var specs []runtime.ServiceSpec
+	for _, s := range c.services {
+		spec := runtime.ServiceSpec{
+			Name:        c.uniqueName(s),
+			Environment: map[string]string{},
+		}
+		if len(s.Command) > 0 {
+			spec.Image = s.Command[0]
+			spec.Flags = append(spec.Flags, s.Command[1:]...)
+		}
+		for k, v := range s.Environment {
+			spec.Environment[k] = v
+		}
+		for _, line := range s.config {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			if k, v, ok := strings.Cut(line, ":"); ok {
+				// keys which are not in the generated config (eg. in dry-run mode) are added with the environment name
+				key := strings.TrimSpace(k)
+				if !strings.HasPrefix(key, "STORJ_") {
+					key = camelToUpperCase(key)
+				}
+				spec.Environment[key] = strings.TrimSpace(v)
+			}
+		}
+		for _, p := range s.ports {
+			spec.Ports = append(spec.Ports, strconv.Itoa(p.Internal))
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// configurationFiles are the generated files of the service directories which are kept by `storj-up clean`.
+var configurationFiles = []string{"config.yaml", "ca.cert", "ca.key", "identity.cert", "identity.key"}
+
+// State implements runtime.Cleaner. Native services store all their data in the service directory, next to the
+// configuration and identity, therefore all the other files of the directory are returned as data.
+func (c *Standalone) State(s runtime.Service, persisted []string) runtime.ServiceState {
+	ss, ok := s.(*service)
+	if !ok {
+		return runtime.ServiceState{}
+	}
+	state := runtime.ServiceState{
+		Name: c.uniqueName(ss),
+	}
+	serviceDir := filepath.Join(c.dir, ss.id.Name, strconv.Itoa(ss.id.Instance))
+	entries, err := os.ReadDir(serviceDir)
+	if err != nil {
+		return state
+	}
+	for _, entry := range entries {
+		if slices.Contains(configurationFiles, entry.Name()) {
+			state.Config = append(state.Config, filepath.Join(serviceDir, entry.Name()))
+			continue
+		}
+		state.Paths = append(state.Paths, filepath.Join(serviceDir, entry.Name()))
+	}
+	return state
+}
+
 func (c *Standalone) uniqueName(s *service) string {
 	u := ""
 	if c.serviceCount(s.id.Name) > 1 {
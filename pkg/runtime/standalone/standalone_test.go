@@ -5,6 +5,7 @@ package standalone
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -38,3 +39,256 @@ func TestStandalone(t *testing.T) {
 	err = runtime.ApplyRecipes(st, rt, st.AllRecipeNames(), 0)
 	require.NoError(t, err)
 }
+
+func TestReloadFromState(t *testing.T) {
+	tempDir := t.TempDir()
+	paths := Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	}
+	rt, err := NewStandalone(paths)
+	require.NoError(t, err)
+
+	redis := recipe.Service{
+		Name:    "redis",
+		Command: []string{"redis-server"},
+		Environment: map[string]string{
+			"FOO": "bar",
+		},
+	}
+	_, err = rt.AddService(redis)
+	require.NoError(t, err)
+	require.NoError(t, rt.Write())
+
+	// unrelated scripts shouldn't be touched
+	stray := filepath.Join(tempDir, "redis-backup.sh")
+	require.NoError(t, os.WriteFile(stray, []byte("#!/bin/bash"), 0755))
+
+	reloaded, err := NewStandalone(paths)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+	require.Len(t, reloaded.GetServices(), 1)
+	require.Equal(t, "redis/0", reloaded.GetServices()[0].ID().String())
+	require.Equal(t, "bar", reloaded.services[0].Environment["FOO"])
+
+	// second instance renames the generated script
+	_, err = reloaded.AddService(redis)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Write())
+
+	require.NoFileExists(t, filepath.Join(tempDir, "redis.sh"))
+	require.FileExists(t, filepath.Join(tempDir, "redis1.sh"))
+	require.FileExists(t, filepath.Join(tempDir, "redis2.sh"))
+	require.FileExists(t, stray)
+}
+
+func TestProcessManagers(t *testing.T) {
+	tempDir := t.TempDir()
+	paths := Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	}
+	rt, err := NewStandalone(paths)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = rt.AddService(recipe.Service{
+			Name:    "redis",
+			Command: []string{"redis-server"},
+		})
+		require.NoError(t, err)
+	}
+
+	rt.ProcessManager = "procfile"
+	require.NoError(t, rt.Write())
+	procfile, err := os.ReadFile(filepath.Join(tempDir, "Procfile"))
+	require.NoError(t, err)
+	require.Equal(t, "redis1: ./redis1.sh\nredis2: ./redis2.sh\n", string(procfile))
+	require.NoFileExists(t, filepath.Join(tempDir, "supervisord.conf"))
+
+	// selected process manager is persisted, previous output is removed
+	reloaded, err := NewStandalone(paths)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+	require.Equal(t, "procfile", reloaded.ProcessManager)
+
+	reloaded.ProcessManager = "tmux"
+	require.NoError(t, reloaded.Write())
+	require.NoFileExists(t, filepath.Join(tempDir, "Procfile"))
+	tmux, err := os.ReadFile(filepath.Join(tempDir, "tmux.sh"))
+	require.NoError(t, err)
+	require.Contains(t, string(tmux), "-T redis2")
+
+	reloaded.ProcessManager = "zellij"
+	require.NoError(t, reloaded.Write())
+	zellij, err := os.ReadFile(filepath.Join(tempDir, "zellij.kdl"))
+	require.NoError(t, err)
+	require.Contains(t, string(zellij), `pane name="redis1"`)
+
+	reloaded.ProcessManager = "unknown"
+	require.Error(t, reloaded.Write())
+}
+
+func TestDebug(t *testing.T) {
+	tempDir := t.TempDir()
+	paths := Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	}
+	rt, err := NewStandalone(paths)
+	require.NoError(t, err)
+	s, err := rt.AddService(recipe.Service{
+		Name:    "versioncontrol",
+		Command: []string{"versioncontrol", "run"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.(runtime.Debuggable).SetDebug(true))
+	require.NoError(t, rt.Write())
+
+	script, err := os.ReadFile(filepath.Join(tempDir, "versioncontrol.sh"))
+	require.NoError(t, err)
+	require.Contains(t, string(script), "dlv --listen=localhost:23008 --headless=true --api-version=2 --accept-multiclient exec --check-go-version=false $(command -v versioncontrol) -- run")
+
+	reloaded, err := NewStandalone(paths)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+	require.Equal(t, 23008, reloaded.GetServices()[0].(runtime.Debuggable).DebugPort())
+
+	// unknown services get different ports, which are kept after reload
+	var ports []int
+	for _, name := range []string{"foo", "bar"} {
+		s, err := reloaded.AddService(recipe.Service{Name: name, Command: []string{name}})
+		require.NoError(t, err)
+		require.NoError(t, s.(runtime.Debuggable).SetDebug(true))
+		ports = append(ports, s.(runtime.Debuggable).DebugPort())
+	}
+	require.NotEqual(t, ports[0], ports[1])
+	require.NoError(t, reloaded.Write())
+	reloaded, err = NewStandalone(paths)
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(recipe.Stack{}))
+	require.Equal(t, ports, []int{
+		reloaded.GetServices()[1].(runtime.Debuggable).DebugPort(),
+		reloaded.GetServices()[2].(runtime.Debuggable).DebugPort(),
+	})
+}
+
+func TestScale(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewStandalone(Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	})
+	require.NoError(t, err)
+	st := recipe.Stack{{
+		Name: "db",
+		Add:  []*recipe.Service{{Name: "redis", Command: []string{"redis-server"}}},
+	}}
+	require.NoError(t, runtime.ApplyRecipes(st, rt, []string{"db"}, 0))
+
+	require.NoError(t, runtime.Scale(st, rt, []string{"redis"}, 3))
+	require.NoError(t, rt.Write())
+	require.FileExists(t, filepath.Join(tempDir, "redis3.sh"))
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "redis", "2", "data"), 0o755))
+
+	require.NoError(t, runtime.Scale(st, rt, []string{"db"}, 1))
+	// the data of the removed instances is deleted only by the Write
+	require.DirExists(t, filepath.Join(tempDir, "redis", "2", "data"))
+	require.NoError(t, rt.Write())
+	require.Len(t, rt.GetServices(), 1)
+	require.FileExists(t, filepath.Join(tempDir, "redis.sh"))
+	require.NoFileExists(t, filepath.Join(tempDir, "redis3.sh"))
+	require.NoDirExists(t, filepath.Join(tempDir, "redis", "2"))
+	require.NoDirExists(t, filepath.Join(tempDir, "redis", "1"))
+	require.DirExists(t, filepath.Join(tempDir, "redis", "0"))
+}
+
+func TestDescribe(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewStandalone(Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	})
+	require.NoError(t, err)
+	_, err = rt.AddService(recipe.Service{
+		Name:    "redis",
+		Command: []string{"redis-server"},
+		Label:   []string{"infra"},
+		Port:    []recipe.PortDefinition{{Name: "redis", Target: 6379, Protocol: "tcp"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, rt.Write())
+
+	// everything is restored from the state file
+	reloaded, err := NewStandalone(Paths{ScriptDir: tempDir})
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Reload(nil))
+	require.Len(t, reloaded.GetServices(), 1)
+
+	info := reloaded.GetServices()[0].(runtime.Describer).Describe()
+	require.Equal(t, "redis/0", info.Instance)
+	require.Contains(t, info.Source, "redis-server")
+	require.Equal(t, []string{"6379/tcp"}, info.Ports)
+	require.Equal(t, []string{filepath.Join(tempDir, "redis", "0")}, info.Persisted)
+	require.Equal(t, []string{"infra"}, info.Labels)
+}
+
+func TestState(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewStandalone(Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+	})
+	require.NoError(t, err)
+	s, err := rt.AddService(recipe.Service{
+		Name:    "redis",
+		Command: []string{"redis-server"},
+	})
+	require.NoError(t, err)
+
+	serviceDir := filepath.Join(tempDir, "redis", "0")
+	require.NoError(t, os.WriteFile(filepath.Join(serviceDir, "config.yaml"), []byte{}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceDir, "identity.cert"), []byte{}, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(serviceDir, "dump.rdb"), []byte{}, 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(serviceDir, "storage"), 0755))
+
+	state := rt.State(s, nil)
+	require.Equal(t, "redis", state.Name)
+	require.False(t, state.Container)
+	require.ElementsMatch(t, []string{filepath.Join(serviceDir, "dump.rdb"), filepath.Join(serviceDir, "storage")}, state.Paths)
+	require.ElementsMatch(t, []string{filepath.Join(serviceDir, "config.yaml"), filepath.Join(serviceDir, "identity.cert")}, state.Config)
+	require.Empty(t, state.Volumes)
+}
+
+func TestDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	rt, err := NewStandalone(Paths{
+		ScriptDir:  tempDir,
+		StorjDir:   tempDir,
+		GatewayDir: tempDir,
+		CleanDir:   true,
+	})
+	require.NoError(t, err)
+	rt.DryRun = true
+	// the setup of the binary is not executed, and the identity is not generated
+	_, err = rt.AddService(recipe.Service{
+		Name:    "storagenode",
+		Label:   []string{"storj"},
+		Command: []string{"storagenode", "run"},
+		Config:  map[string]string{"STORJ_LOG_LEVEL": "debug"},
+	})
+	require.NoError(t, err)
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	specs := rt.Specs()
+	require.Len(t, specs, 1)
+	require.Equal(t, "storagenode", specs[0].Image)
+	require.Equal(t, "debug", specs[0].Environment["STORJ_LOG_LEVEL"])
+}
@@ -14,5 +14,6 @@ export {{$k}}="{{Safe $v}}"{{end}}
 mkdir -p ./{{.Service.ID.Name}}/{{.Service.ID.Instance}}
 
 #RUN
-{{range .Service.Command}}{{.}} {{end}}
+{{ if .DebugPort }}dlv --listen=localhost:{{ .DebugPort }} --headless=true --api-version=2 --accept-multiclient exec --check-go-version=false $(command -v {{ index .Service.Command 0 }}) -- {{range Tail .Service.Command}}{{.}} {{end}}
+{{- else }}{{range .Service.Command}}{{.}} {{end}}{{ end }}
 
@@ -0,0 +1,133 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package standalone
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common/atomicfile"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// StateFileName is the name of the file which stores the authoritative state of a standalone environment.
+const StateFileName = "storj-up.state.json"
+
+// stateVersion is the current version of the state file format.
+const stateVersion = 1
+
+// state is the persisted form of a standalone environment. Scripts, supervisord.conf and IDE runners are generated from it.
+type state struct {
+	Version  int            `json:"version"`
+	Services []serviceState `json:"services"`
+
+	// ProcessManager is the selected process manager output (empty means the default).
+	ProcessManager string `json:"processManager,omitempty"`
+
+	// Generated lists the files (relative to the environment directory) written from the state by the last Write.
+	Generated []string `json:"generated,omitempty"`
+}
+
+// serviceState is the persisted form of one service instance.
+type serviceState struct {
+	Name        string            `json:"name"`
+	Instance    int               `json:"instance"`
+	Command     []string          `json:"command"`
+	Environment map[string]string `json:"environment,omitempty"`
+	Config      []string          `json:"config,omitempty"`
+	Labels      []string          `json:"labels,omitempty"`
+	Debug       bool              `json:"debug,omitempty"`
+	DelvePort   int               `json:"delvePort,omitempty"`
+	Ports       []runtime.PortMap `json:"ports,omitempty"`
+}
+
+// loadState reads the state file from the directory. Returns with nil if the file doesn't exist.
+func loadState(dir string) (*state, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, StateFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	st := &state{}
+	if err := json.Unmarshal(raw, st); err != nil {
+		return nil, errs.Errorf("couldn't parse %s: %v", StateFileName, err)
+	}
+	if st.Version > stateVersion {
+		return nil, errs.Errorf("%s has version %d, but only version %d is supported. Please upgrade storj-up", StateFileName, st.Version, stateVersion)
+	}
+	return st, nil
+}
+
+// writeState persists the current services to the state file.
+func (c *Standalone) writeState(generated []string) error {
+	st := state{
+		Version:   stateVersion,
+		Services:  make([]serviceState, 0, len(c.services)),
+		Generated: generated,
+
+		ProcessManager: c.ProcessManager,
+	}
+	for _, s := range c.services {
+		st.Services = append(st.Services, serviceState{
+			Name:        s.id.Name,
+			Instance:    s.id.Instance,
+			Command:     s.Command,
+			Environment: s.Environment,
+			Config:      s.config,
+			Labels:      s.labels,
+			Debug:       s.debug,
+			DelvePort:   s.delvePort,
+			Ports:       s.ports,
+		})
+	}
+	raw, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(filepath.Join(c.dir, StateFileName), raw, 0644)
+}
+
+// restoreState recreates the services from the state, without any side effect on the file system.
+func (c *Standalone) restoreState(st *state) {
+	c.services = make([]*service, 0, len(st.Services))
+	for _, ss := range st.Services {
+		id := runtime.NewServiceInstance(ss.Name, ss.Instance)
+		s := &service{
+			id: id,
+			render: func(s string) (string, error) {
+				return runtime.Render(c.resolve(), id, s)
+			},
+			Command:     ss.Command,
+			Environment: ss.Environment,
+			config:      ss.Config,
+			labels:      ss.Labels,
+			debug:       ss.Debug,
+			delvePort:   ss.DelvePort,
+			ports:       ss.Ports,
+			dir:         filepath.Join(c.dir, ss.Name, strconv.Itoa(ss.Instance)),
+		}
+		s.usedPorts = func() []int { return c.debugPorts(s) }
+		if s.Command == nil {
+			s.Command = []string{}
+		}
+		if s.Environment == nil {
+			s.Environment = map[string]string{}
+		}
+		if s.config == nil {
+			s.config = []string{}
+		}
+		if s.labels == nil {
+			s.labels = []string{}
+		}
+		c.services = append(c.services, s)
+	}
+	c.generated = st.Generated
+	c.ProcessManager = st.ProcessManager
+}
@@ -4,14 +4,18 @@
 package standalone
 
 import (
+	"bytes"
 	_ "embed"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"text/template"
 
 	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common/atomicfile"
 )
 
 //go:embed intellij.xml
@@ -23,11 +27,56 @@ var startTemplate []byte
 //go:embed supervisord.template
 var supervisorTemplate []byte
 
+//go:embed procfile.template
+var procfileTemplate []byte
+
+//go:embed tmux.template
+var tmuxTemplate []byte
+
+//go:embed zellij.template
+var zellijTemplate []byte
+
 //go:embed .env
 var dotEnvrc []byte
 
+// ProcessManagers lists the supported process manager outputs with the name of the generated file.
+var ProcessManagers = map[string]string{
+	"supervisord": "supervisord.conf",
+	"procfile":    "Procfile",
+	"tmux":        "tmux.sh",
+	"zellij":      "zellij.kdl",
+}
+
+// DefaultProcessManager is used when ProcessManager is not set.
+const DefaultProcessManager = "supervisord"
+
+// Write implements runtime.Runtime. The state file is written first, all the other files are generated from the same services.
 func (c *Standalone) Write() error {
+	processManager := c.ProcessManager
+	if processManager == "" {
+		processManager = DefaultProcessManager
+	}
+	processManagerFile, found := ProcessManagers[processManager]
+	if !found {
+		return errs.Errorf("unsupported process manager: %s", processManager)
+	}
+
 	_ = os.MkdirAll(c.dir, 0755)
+
+	var generated []string
+	for _, service := range c.services {
+		generated = append(generated, c.uniqueName(service)+".sh")
+		if _, found := runnerSupported[service.ID().Name]; found {
+			generated = append(generated, c.uniqueName(service)+".run.xml")
+		}
+	}
+	generated = append(generated, processManagerFile, ".envrc")
+
+	err := c.writeState(generated)
+	if err != nil {
+		return err
+	}
+
 	for _, service := range c.services {
 		err := c.writeService(service)
 		if err != nil {
@@ -38,63 +87,117 @@ func (c *Standalone) Write() error {
 			return err
 		}
 		if len(service.config) > 0 {
-			err := os.WriteFile(filepath.Join(c.dir, service.id.Name, strconv.Itoa(service.id.Instance), "config.yaml"), []byte(strings.Join(service.config, "\n")), 0644)
+			err := atomicfile.WriteFile(filepath.Join(c.dir, service.id.Name, strconv.Itoa(service.id.Instance), "config.yaml"), []byte(strings.Join(service.config, "\n")), 0644)
 			if err != nil {
 				return errs.Wrap(err)
 			}
 		}
 	}
-	err := c.writeSupervisor()
+	switch processManager {
+	case "procfile":
+		err = c.writeProcfile()
+	case "tmux":
+		err = c.writeTmux()
+	case "zellij":
+		err = c.writeZellij()
+	default:
+		err = c.writeSupervisor()
+	}
 	if err != nil {
 		return err
 	}
 
-	err = os.WriteFile(filepath.Join(c.dir, ".envrc"), dotEnvrc, 0644)
+	err = atomicfile.WriteFile(filepath.Join(c.dir, ".envrc"), dotEnvrc, 0644)
 	if err != nil {
 		return err
 	}
+
+	// outputs of the previous Write which are not generated any more (eg. renamed because of a new instance)
+	for _, previous := range c.generated {
+		if filepath.IsLocal(previous) && !slices.Contains(generated, previous) {
+			err = os.Remove(filepath.Join(c.dir, previous))
+			if err != nil && !os.IsNotExist(err) {
+				return errs.Wrap(err)
+			}
+		}
+	}
+	c.generated = generated
+
+	// directories of the removed instances (unless the instance is added again)
+	for _, instance := range c.removed {
+		if slices.ContainsFunc(c.services, func(s *service) bool { return s.id == instance }) {
+			continue
+		}
+		err = os.RemoveAll(filepath.Join(c.dir, instance.Name, strconv.Itoa(instance.Instance)))
+		if err != nil {
+			return errs.Wrap(err)
+		}
+	}
+	c.removed = nil
 	return nil
 }
 
 func (c *Standalone) writeSupervisor() error {
-	f, err := os.OpenFile(filepath.Join(c.dir, "supervisord.conf"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = f.Close()
-	}()
-	t, err := template.New("supervisord.template").
+	return c.writeProcessManager("supervisord.conf", supervisorTemplate, 0755)
+}
+
+// writeProcfile generates Procfile for foreman/overmind/honcho.
+func (c *Standalone) writeProcfile() error {
+	return c.writeProcessManager("Procfile", procfileTemplate, 0644)
+}
+
+// writeTmux generates a script which starts a tmux session with one pane per service.
+func (c *Standalone) writeTmux() error {
+	return c.writeProcessManager("tmux.sh", tmuxTemplate, 0755)
+}
+
+// writeZellij generates a zellij layout with one pane per service (use it with `zellij --layout zellij.kdl`).
+func (c *Standalone) writeZellij() error {
+	return c.writeProcessManager("zellij.kdl", zellijTemplate, 0644)
+}
+
+// writeProcessManager renders the configuration of a process manager for all the services.
+func (c *Standalone) writeProcessManager(fileName string, tmpl []byte, perm os.FileMode) error {
+	t, err := template.New(fileName).
 		Funcs(map[string]any{
 			"UniqueName": c.uniqueName,
 			"Add": func(a int, b int) int {
 				return a + b
 			},
+			"Script": func(s *service) string {
+				return filepath.Join(c.dir, c.uniqueName(s)+".sh")
+			},
+			"Quote": strconv.Quote,
 		}).
-		Parse(string(supervisorTemplate))
+		Parse(string(tmpl))
 	if err != nil {
 		return errs.Wrap(err)
 	}
 
-	err = t.Execute(f, struct {
+	var out bytes.Buffer
+	err = t.Execute(&out, struct {
+		Dir      string
 		Services []*service
 	}{
+		Dir:      c.dir,
 		Services: c.services,
 	})
-	return err
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(filepath.Join(c.dir, fileName), out.Bytes(), perm)
 }
 
 func (c *Standalone) writeService(s *service) error {
-	f, err := os.OpenFile(filepath.Join(c.dir, c.uniqueName(s)+".sh"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = f.Close()
-	}()
 	t, err := template.New("start.sh").
 		Funcs(map[string]any{
 			"HasPrefix": strings.HasPrefix,
+			"Tail": func(a []string) []string {
+				if len(a) > 0 {
+					return a[1:]
+				}
+				return a
+			},
 			"Safe": func(p string) string {
 				var out strings.Builder
 				for i := 0; i < len(p); i++ {
@@ -111,12 +214,18 @@ func (c *Standalone) writeService(s *service) error {
 		return errs.Wrap(err)
 	}
 
-	err = t.Execute(f, struct {
-		Service *service
+	var out bytes.Buffer
+	err = t.Execute(&out, struct {
+		Service   *service
+		DebugPort int
 	}{
-		Service: s,
+		Service:   s,
+		DebugPort: s.DebugPort(),
 	})
-	return err
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(filepath.Join(c.dir, c.uniqueName(s)+".sh"), out.Bytes(), 0755)
 }
 
 var runnerSupported = map[string]string{
@@ -137,13 +246,6 @@ func (c *Standalone) writeIntelliJRunner(s *service) error {
 	if !found {
 		return nil
 	}
-	f, err := os.OpenFile(filepath.Join(c.dir, c.uniqueName(s)+".run.xml"), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = f.Close()
-	}()
 	t, err := template.New("intellij.xml").
 		Funcs(map[string]any{
 			"HasPrefix":  strings.HasPrefix,
@@ -168,7 +270,8 @@ func (c *Standalone) writeIntelliJRunner(s *service) error {
 	if strings.HasPrefix(executable, "satellite") {
 		executable = "satellite"
 	}
-	err = t.Execute(f, struct {
+	var out bytes.Buffer
+	err = t.Execute(&out, struct {
 		Service    *service
 		Package    string
 		Executable string
@@ -177,5 +280,8 @@ func (c *Standalone) writeIntelliJRunner(s *service) error {
 		Package:    pkg,
 		Executable: executable,
 	})
-	return err
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	return atomicfile.WriteFile(filepath.Join(c.dir, c.uniqueName(s)+".run.xml"), out.Bytes(), 0755)
 }
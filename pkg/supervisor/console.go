@@ -0,0 +1,75 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package supervisor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+var colors = []int{32, 33, 34, 35, 36, 91, 92, 93, 94, 95, 96}
+
+// console multiplexes the output of all the processes to one writer, prefixing each line with the name of the program.
+type console struct {
+	mu    sync.Mutex
+	out   io.Writer
+	width int
+	color bool
+}
+
+func newConsole(out io.Writer, programs []Program) *console {
+	c := &console{
+		out:   out,
+		color: os.Getenv("NO_COLOR") == "",
+	}
+	for _, p := range programs {
+		if len(p.Name) > c.width {
+			c.width = len(p.Name)
+		}
+	}
+	return c
+}
+
+// writer returns a line buffered writer for the output of one process.
+func (c *console) writer(p *process) io.Writer {
+	return &lineWriter{console: c, process: p}
+}
+
+// system prints a supervisor message about one process.
+func (c *console) system(p *process, msg string) {
+	c.line(p, "*** "+msg)
+}
+
+func (c *console) line(p *process, line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.color {
+		_, _ = fmt.Fprintf(c.out, "\x1b[%dm%-*s |\x1b[0m %s\n", colors[p.color%len(colors)], c.width, p.program.Name, line)
+	} else {
+		_, _ = fmt.Fprintf(c.out, "%-*s | %s\n", c.width, p.program.Name, line)
+	}
+}
+
+type lineWriter struct {
+	console *console
+	process *process
+	buf     []byte
+}
+
+// Write implements io.Writer. Only full lines are printed, the remaining part is kept for the next write.
+func (l *lineWriter) Write(data []byte) (int, error) {
+	l.buf = append(l.buf, data...)
+	for {
+		ix := bytes.IndexByte(l.buf, '\n')
+		if ix < 0 {
+			break
+		}
+		l.console.line(l.process, string(bytes.TrimRight(l.buf[:ix], "\r")))
+		l.buf = l.buf[ix+1:]
+	}
+	return len(data), nil
+}
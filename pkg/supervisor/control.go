@@ -0,0 +1,143 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package supervisor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// request is one control operation sent over the socket.
+type request struct {
+	Op    string   `json:"op"`
+	Names []string `json:"names,omitempty"`
+}
+
+// response is the answer to a control request.
+type response struct {
+	Status []Status `json:"status,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Listen creates the control socket. Stale socket file (left by a crashed supervisor) is removed.
+func Listen(ctx context.Context, path string) (net.Listener, error) {
+	var dialer net.Dialer
+	if conn, err := dialer.DialContext(ctx, "unix", path); err == nil {
+		_ = conn.Close()
+		return nil, errs.Errorf("supervisor is already running (%s)", path)
+	}
+	_ = os.Remove(path)
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return l, nil
+}
+
+// Serve handles control requests until the context is canceled. The listener is closed on return.
+func (s *Supervisor) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return errs.Wrap(err)
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *Supervisor) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var req request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		_ = json.NewEncoder(conn).Encode(response{Error: err.Error()})
+		return
+	}
+
+	var err error
+	switch req.Op {
+	case "status":
+	case "start":
+		err = s.Start(req.Names...)
+	case "stop":
+		err = s.Stop(req.Names...)
+	case "restart":
+		err = s.Restart(req.Names...)
+	default:
+		err = errs.Errorf("unknown operation: %s", req.Op)
+	}
+
+	resp := response{Status: s.Status()}
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	_ = json.NewEncoder(conn).Encode(resp)
+}
+
+// Client connects to the control socket of a running supervisor.
+type Client struct {
+	path string
+}
+
+// NewClient creates a client for the socket at path.
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Status returns with the state of all the supervised programs.
+func (c *Client) Status(ctx context.Context) ([]Status, error) {
+	return c.call(ctx, request{Op: "status"})
+}
+
+// Start starts the stopped programs (all, if no name is given).
+func (c *Client) Start(ctx context.Context, names ...string) ([]Status, error) {
+	return c.call(ctx, request{Op: "start", Names: names})
+}
+
+// Stop stops the programs (all, if no name is given).
+func (c *Client) Stop(ctx context.Context, names ...string) ([]Status, error) {
+	return c.call(ctx, request{Op: "stop", Names: names})
+}
+
+// Restart restarts the programs (all, if no name is given).
+func (c *Client) Restart(ctx context.Context, names ...string) ([]Status, error) {
+	return c.call(ctx, request{Op: "restart", Names: names})
+}
+
+func (c *Client) call(ctx context.Context, req request) ([]Status, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.path)
+	if err != nil {
+		return nil, errs.Errorf("supervisor is not running (use `storj-up run`): %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, errs.Wrap(err)
+	}
+	if resp.Error != "" {
+		return resp.Status, errs.Errorf("%s", resp.Error)
+	}
+	return resp.Status, nil
+}
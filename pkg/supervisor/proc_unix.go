@@ -0,0 +1,26 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+//go:build !windows
+
+package supervisor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the process in a new process group, to make it possible to stop all the children together.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// interruptGroup sends SIGTERM to the full process group.
+func interruptGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+// killGroup sends SIGKILL to the full process group.
+func killGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
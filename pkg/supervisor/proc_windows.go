@@ -0,0 +1,25 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+//go:build windows
+
+package supervisor
+
+import (
+	"os/exec"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// setProcessGroup is a noop on Windows.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptGroup is not supported on Windows, the process is killed instead.
+func interruptGroup(cmd *exec.Cmd) error {
+	return errs.Errorf("graceful stop is not supported on windows")
+}
+
+// killGroup kills the process.
+func killGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
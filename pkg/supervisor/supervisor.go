@@ -0,0 +1,456 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package supervisor starts and watches local processes, similar to what supervisord does for standalone environments.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+)
+
+// SocketName is the name of the control socket, created in the directory of the environment.
+const SocketName = ".storj-up.sock"
+
+// Program is one supervised process.
+type Program struct {
+	Name string
+	// Command is the executable with the arguments.
+	Command []string
+	// Dir is the working directory of the process.
+	Dir string
+	// LogDir is the directory of stdout.log and stderr.log.
+	LogDir string
+	// DependsOn lists the programs which should be running before this one is started.
+	DependsOn []string
+}
+
+// State is the lifecycle state of a supervised process.
+type State string
+
+const (
+	// Waiting means the process waits for the dependencies.
+	Waiting State = "waiting"
+	// Starting means the process is launched, but not yet running for StartTime.
+	Starting State = "starting"
+	// Running means the process is up and running.
+	Running State = "running"
+	// Backoff means the process is crashed, and it will be restarted soon.
+	Backoff State = "backoff"
+	// Exited means the process is finished without error, and it won't be restarted.
+	Exited State = "exited"
+	// Stopped means the process is stopped on request.
+	Stopped State = "stopped"
+)
+
+// Status is the current state of one supervised program.
+type Status struct {
+	Name      string    `json:"name"`
+	State     State     `json:"state"`
+	PID       int       `json:"pid,omitempty"`
+	Restarts  int       `json:"restarts"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Supervisor starts programs in dependency order and restarts them if they are crashed.
+type Supervisor struct {
+	// StartTime is the time while a process should be up to be considered as running.
+	StartTime time.Duration
+	// MinBackoff is the first delay before restarting a crashed process.
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum delay before restarting a crashed process.
+	MaxBackoff time.Duration
+	// StopTimeout is the time to wait after SIGTERM before killing the process group.
+	StopTimeout time.Duration
+
+	console   *console
+	processes []*process
+}
+
+// New creates a supervisor for the programs. Output of all processes is copied to console with a colored prefix.
+func New(programs []Program, out io.Writer) (*Supervisor, error) {
+	ordered, err := startOrder(programs)
+	if err != nil {
+		return nil, err
+	}
+	s := &Supervisor{
+		StartTime:   time.Second,
+		MinBackoff:  time.Second,
+		MaxBackoff:  30 * time.Second,
+		StopTimeout: 15 * time.Second,
+		console:     newConsole(out, ordered),
+	}
+	for ix, p := range ordered {
+		s.processes = append(s.processes, &process{
+			program: p,
+			sup:     s,
+			color:   ix,
+			state:   Waiting,
+			since:   time.Now(),
+			wanted:  true,
+			wake:    make(chan struct{}, 1),
+			ready:   make(chan struct{}),
+		})
+	}
+	return s, nil
+}
+
+// Run starts all the programs and supervises them until the context is canceled.
+// Running processes are terminated (with the full process group) before Run returns.
+func (s *Supervisor) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, p := range s.processes {
+		wg.Add(1)
+		go func(p *process) {
+			defer wg.Done()
+			p.run(ctx)
+		}(p)
+	}
+	wg.Wait()
+	return nil
+}
+
+// Status returns the state of all the programs in start order.
+func (s *Supervisor) Status() []Status {
+	var res []Status
+	for _, p := range s.processes {
+		res = append(res, p.status())
+	}
+	return res
+}
+
+// Stop terminates the selected programs (all, if no name is given). They are not restarted until Start is called.
+func (s *Supervisor) Stop(names ...string) error {
+	return s.each(names, func(p *process) {
+		p.request(false, false)
+	})
+}
+
+// Start starts the selected (stopped or exited) programs (all, if no name is given).
+func (s *Supervisor) Start(names ...string) error {
+	return s.each(names, func(p *process) {
+		p.request(true, false)
+	})
+}
+
+// Restart stops and starts again the selected programs (all, if no name is given).
+func (s *Supervisor) Restart(names ...string) error {
+	return s.each(names, func(p *process) {
+		p.request(true, true)
+	})
+}
+
+func (s *Supervisor) each(names []string, f func(p *process)) error {
+	if len(names) == 0 {
+		for _, p := range s.processes {
+			f(p)
+		}
+		return nil
+	}
+	for _, name := range names {
+		p := s.find(name)
+		if p == nil {
+			return errs.Errorf("no such program: %s", name)
+		}
+		f(p)
+	}
+	return nil
+}
+
+func (s *Supervisor) find(name string) *process {
+	for _, p := range s.processes {
+		if p.program.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// startOrder sorts the programs based on the dependencies. Dependencies which are not part of the programs are ignored.
+func startOrder(programs []Program) ([]Program, error) {
+	byName := map[string]Program{}
+	for _, p := range programs {
+		if _, found := byName[p.Name]; found {
+			return nil, errs.Errorf("program %s is defined twice", p.Name)
+		}
+		byName[p.Name] = p
+	}
+
+	var ordered []Program
+	visited := map[string]bool{}
+	inProgress := map[string]bool{}
+	var visit func(p Program) error
+	visit = func(p Program) error {
+		if visited[p.Name] {
+			return nil
+		}
+		if inProgress[p.Name] {
+			return errs.Errorf("dependency cycle detected at %s", p.Name)
+		}
+		inProgress[p.Name] = true
+		deps := append([]string{}, p.DependsOn...)
+		sort.Strings(deps)
+		for _, d := range deps {
+			if dep, found := byName[d]; found {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		inProgress[p.Name] = false
+		visited[p.Name] = true
+		ordered = append(ordered, p)
+		return nil
+	}
+	for _, p := range programs {
+		if err := visit(p); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// process is the supervised state of one program.
+type process struct {
+	program Program
+	sup     *Supervisor
+	color   int
+
+	mu       sync.Mutex
+	state    State
+	pid      int
+	restarts int
+	since    time.Time
+	lastErr  string
+	wanted   bool
+	restart  bool
+
+	// wake is signaled when wanted/restart is changed.
+	wake chan struct{}
+	// ready is closed when the process is running for the first time.
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+func (p *process) status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return Status{
+		Name:      p.program.Name,
+		State:     p.state,
+		PID:       p.pid,
+		Restarts:  p.restarts,
+		Since:     p.since,
+		LastError: p.lastErr,
+	}
+}
+
+func (p *process) setState(state State, pid int, lastErr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = state
+	p.pid = pid
+	p.since = time.Now()
+	if lastErr != "" {
+		p.lastErr = lastErr
+	}
+}
+
+func (p *process) request(wanted bool, restart bool) {
+	p.mu.Lock()
+	p.wanted = wanted
+	p.restart = restart
+	p.mu.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pending returns with the requested state and resets the restart request.
+func (p *process) pending() (wanted bool, restart bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	restart = p.restart
+	p.restart = false
+	return p.wanted, restart
+}
+
+func (p *process) markReady() {
+	p.readyOnce.Do(func() {
+		close(p.ready)
+	})
+}
+
+func (p *process) run(ctx context.Context) {
+	// stopped (or failing) dependencies shouldn't block the others forever, a stopped program is also ready.
+	defer p.markReady()
+
+	for _, dep := range p.program.DependsOn {
+		if d := p.sup.find(dep); d != nil {
+			select {
+			case <-d.ready:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	backoff := p.sup.MinBackoff
+	for {
+		wanted, _ := p.pending()
+		if !wanted {
+			if p.status().State != Exited {
+				p.setState(Stopped, 0, "")
+			}
+			p.markReady()
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.wake:
+				continue
+			}
+		}
+
+		started := time.Now()
+		err := p.execute(ctx)
+		if ctx.Err() != nil {
+			p.setState(Stopped, 0, "")
+			return
+		}
+		if wanted, restart := p.pending(); !wanted || restart {
+			backoff = p.sup.MinBackoff
+			continue
+		}
+		if err == nil {
+			p.setState(Exited, 0, "")
+			p.sup.console.system(p, "exited")
+			p.request(false, false)
+			continue
+		}
+
+		if time.Since(started) > p.sup.MaxBackoff {
+			backoff = p.sup.MinBackoff
+		}
+		p.mu.Lock()
+		p.restarts++
+		p.mu.Unlock()
+		p.setState(Backoff, 0, err.Error())
+		p.sup.console.system(p, fmt.Sprintf("%v, restarting in %s", err, backoff))
+		// the dependents are started after the first failed attempt, a crash-looping program would block them forever
+		p.markReady()
+		select {
+		case <-ctx.Done():
+			p.setState(Stopped, 0, "")
+			return
+		case <-time.After(backoff):
+		case <-p.wake:
+		}
+		backoff *= 2
+		if backoff > p.sup.MaxBackoff {
+			backoff = p.sup.MaxBackoff
+		}
+	}
+}
+
+// execute starts the process and waits until it's finished, or it's stopped by request / context cancellation.
+func (p *process) execute(ctx context.Context) (err error) {
+	if len(p.program.Command) == 0 {
+		return errs.Errorf("empty command")
+	}
+
+	stdout, stderr, err := p.openLogs()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, stdout.Close(), stderr.Close())
+	}()
+
+	cmd := exec.Command(p.program.Command[0], p.program.Command[1:]...)
+	cmd.Dir = p.program.Dir
+	cmd.Env = os.Environ()
+	cmd.Stdout = io.MultiWriter(stdout, p.sup.console.writer(p))
+	cmd.Stderr = io.MultiWriter(stderr, p.sup.console.writer(p))
+	setProcessGroup(cmd)
+
+	p.setState(Starting, 0, "")
+	if err := cmd.Start(); err != nil {
+		return errs.Wrap(err)
+	}
+	p.setState(Starting, cmd.Process.Pid, "")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	startTimer := time.NewTimer(p.sup.StartTime)
+	defer startTimer.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				return errs.Wrap(err)
+			}
+			return nil
+		case <-startTimer.C:
+			p.setState(Running, cmd.Process.Pid, "")
+			p.markReady()
+		case <-p.wake:
+			p.mu.Lock()
+			stop := !p.wanted || p.restart
+			p.mu.Unlock()
+			if stop {
+				p.terminate(cmd, done)
+				return nil
+			}
+		case <-ctx.Done():
+			p.terminate(cmd, done)
+			return ctx.Err()
+		}
+	}
+}
+
+// terminate stops the process group gracefully, or kills it after the stop timeout.
+func (p *process) terminate(cmd *exec.Cmd, done chan error) {
+	p.sup.console.system(p, "stopping")
+	if err := interruptGroup(cmd); err != nil {
+		_ = killGroup(cmd)
+		<-done
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(p.sup.StopTimeout):
+		p.sup.console.system(p, "didn't stop in time, killing")
+		_ = killGroup(cmd)
+		<-done
+	}
+}
+
+func (p *process) openLogs() (stdout *os.File, stderr *os.File, err error) {
+	if err := os.MkdirAll(p.program.LogDir, 0755); err != nil {
+		return nil, nil, errs.Wrap(err)
+	}
+	stdout, err = os.OpenFile(filepath.Join(p.program.LogDir, "stdout.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, nil, errs.Wrap(err)
+	}
+	stderr, err = os.OpenFile(filepath.Join(p.program.LogDir, "stderr.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		_ = stdout.Close()
+		return nil, nil, errs.Wrap(err)
+	}
+	return stdout, stderr, nil
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package supervisor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOrder(t *testing.T) {
+	ordered, err := startOrder([]Program{
+		{Name: "storagenode1", DependsOn: []string{"satellite-api"}},
+		{Name: "satellite-api", DependsOn: []string{"cockroach", "redis"}},
+		{Name: "redis"},
+		{Name: "cockroach"},
+	})
+	require.NoError(t, err)
+
+	var names []string
+	for _, p := range ordered {
+		names = append(names, p.Name)
+	}
+	require.Equal(t, []string{"cockroach", "redis", "satellite-api", "storagenode1"}, names)
+
+	_, err = startOrder([]Program{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	})
+	require.Error(t, err)
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func TestSupervisor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	dir := t.TempDir()
+	out := &syncBuffer{}
+	sup, err := New([]Program{
+		{
+			Name:      "crashing",
+			Command:   []string{"sh", "-c", "echo crash; exit 1"},
+			Dir:       dir,
+			LogDir:    filepath.Join(dir, "crashing"),
+			DependsOn: []string{"db"},
+		},
+		{
+			Name:    "db",
+			Command: []string{"sh", "-c", "echo ready; sleep 60"},
+			Dir:     dir,
+			LogDir:  filepath.Join(dir, "db"),
+		},
+	}, out)
+	require.NoError(t, err)
+	sup.StartTime = 100 * time.Millisecond
+	sup.MinBackoff = 10 * time.Millisecond
+	sup.MaxBackoff = 20 * time.Millisecond
+	sup.StopTimeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sup.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		status := sup.Status()
+		return status[0].State == Running && status[1].Restarts > 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, sup.Stop("crashing"))
+	require.Eventually(t, func() bool {
+		return sup.Status()[1].State == Stopped
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Error(t, sup.Stop("unknown"))
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, Stopped, sup.Status()[0].State)
+
+	log, err := os.ReadFile(filepath.Join(dir, "crashing", "stdout.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(log), "crash")
+	require.Contains(t, out.String(), "ready")
+}
+
+func TestFailingDependency(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	dir := t.TempDir()
+	sup, err := New([]Program{
+		{
+			Name:    "db",
+			Command: []string{"sh", "-c", "exit 1"},
+			Dir:     dir,
+			LogDir:  filepath.Join(dir, "db"),
+		},
+		{
+			Name:      "satellite-api",
+			Command:   []string{"sh", "-c", "sleep 60"},
+			Dir:       dir,
+			LogDir:    filepath.Join(dir, "satellite-api"),
+			DependsOn: []string{"db"},
+		},
+	}, &syncBuffer{})
+	require.NoError(t, err)
+	// the crash-looping dependency never reaches the running state
+	sup.StartTime = time.Hour
+	sup.MinBackoff = 10 * time.Millisecond
+	sup.MaxBackoff = 20 * time.Millisecond
+	sup.StopTimeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sup.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		status := sup.Status()
+		return status[0].Restarts > 0 && status[1].State == Starting
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,98 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package up
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/common/grant"
+	"storj.io/common/uuid"
+	pkg "storj.io/storj-up/pkg"
+	"storj.io/storj/web/satellite/wasm/consolewasm"
+)
+
+const (
+	// Password is the password of the generated test users.
+	Password = "password"
+	// EncryptionSecret is the passphrase of the generated access grants.
+	EncryptionSecret = "Welcome1"
+)
+
+// Credentials is the structure of the credentials file.
+type Credentials struct {
+	StorjUser     string `json:"email"`
+	StorjPassword string `json:"password"`
+
+	ProjectID        string `json:"ProjectID"`
+	Cookie           string `json:"Cookie"`
+	ApiKey           string `json:"ApiKey"`
+	EncryptionSecret string `json:"EncryptionSecret"`
+	Grant            string `json:"Grant"`
+
+	AccessKey string `json:"AccessKey,omitempty"`
+	SecretKey string `json:"SecretKey,omitempty"`
+	Endpoint  string `json:"Endpoint,omitempty"`
+}
+
+// GenerateCredentials creates (or logs in with) the test user, and creates a project, an API key and an access
+// grant. satellite is the address of the public satellite API, console is the address of the satellite console.
+// S3 credentials are not registered.
+func GenerateCredentials(ctx context.Context, satellite string, console string, email string) (Credentials, error) {
+	creds := Credentials{
+		StorjUser:        email,
+		StorjPassword:    Password,
+		EncryptionSecret: EncryptionSecret,
+	}
+	satelliteNodeURL, err := pkg.GetSatelliteID(ctx, satellite)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+
+	consoleEndpoint := pkg.NewConsoleEndpoints(console, email)
+	err = consoleEndpoint.Login(ctx)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+
+	creds.ProjectID, creds.Cookie, err = consoleEndpoint.GetOrCreateProject(ctx)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+
+	creds.ApiKey, err = consoleEndpoint.CreateAPIKey(ctx, creds.ProjectID)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+
+	projectUUID, err := uuid.FromString(creds.ProjectID)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+
+	creds.Grant, err = consolewasm.GenAccessGrant(satelliteNodeURL+"@"+satellite, creds.ApiKey, EncryptionSecret, base64.StdEncoding.EncodeToString(projectUUID.Bytes()), true)
+	if err != nil {
+		return creds, errs.Wrap(err)
+	}
+	return creds, nil
+}
+
+// RelocateGrant returns with the access grant, changed to use another address of the same satellite (like
+// satellite-api:7777 inside the containers, instead of the published port).
+func RelocateGrant(access string, address string) (string, error) {
+	parsed, err := grant.ParseAccess(access)
+	if err != nil {
+		return "", errs.Wrap(err)
+	}
+	id, _, found := strings.Cut(parsed.SatelliteAddress, "@")
+	if !found {
+		return "", errs.Errorf("satellite address of the access grant has no node ID: %s", parsed.SatelliteAddress)
+	}
+	parsed.SatelliteAddress = id + "@" + address
+	relocated, err := parsed.Serialize()
+	return relocated, errs.Wrap(err)
+}
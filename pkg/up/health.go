@@ -0,0 +1,137 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package up
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/spanner"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/jackc/pgx/v5"
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// WaitHealthy waits until all the storagenodes are checked in to the satellite database, or the context is canceled.
+// The database is accessed with the published port of the database service.
+func (c *Cluster) WaitHealthy(ctx context.Context) error {
+	nodes := 0
+	database := ""
+	for _, s := range c.runtime.GetServices() {
+		switch s.ID().Name {
+		case "storagenode":
+			nodes++
+		case "satellite-api":
+			if v := s.GetENV()["STORJ_DATABASE"]; v != nil {
+				database = *v
+			}
+		}
+	}
+	if database == "" {
+		return errs.Errorf("database of satellite-api is not configured")
+	}
+	project, err := common.LoadComposeFromFile(c.dir, common.ComposeFileName)
+	if err != nil {
+		return err
+	}
+	count, err := c.nodeCounter(project, database)
+	if err != nil {
+		return err
+	}
+
+	found := 0
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		found, err = count(attemptCtx)
+		cancel()
+		if err == nil && found >= nodes {
+			return nil
+		}
+		if waitErr := wait(ctx, time.Second); waitErr != nil {
+			if err != nil {
+				return errs.Errorf("cluster is not healthy: %v", err)
+			}
+			return errs.Errorf("cluster is not healthy: only %d of %d storagenodes are checked in", found, nodes)
+		}
+	}
+}
+
+// nodeCounter returns with the function which counts the checked in nodes in the satellite database.
+func (c *Cluster) nodeCounter(project *types.Project, database string) (func(ctx context.Context) (int, error), error) {
+	u, err := url.Parse(database)
+	if err != nil {
+		return nil, errs.Errorf("invalid database URL %s: %v", database, err)
+	}
+	switch u.Scheme {
+	case "spanner":
+		address, err := c.published(project, c.runtime.Get(runtime.NewServiceInstance("spanner", 0), "emulatorHost"))
+		if err != nil {
+			return nil, err
+		}
+		// client libraries use insecure connection without authentication if the emulator host is set
+		err = os.Setenv("SPANNER_EMULATOR_HOST", address)
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		dbPath, _, _ := strings.Cut(strings.TrimPrefix(database, "spanner://"), "?")
+		return func(ctx context.Context) (int, error) {
+			client, err := spanner.NewClient(ctx, dbPath)
+			if err != nil {
+				return 0, errs.Wrap(err)
+			}
+			defer client.Close()
+			var count int64
+			err = client.Single().Query(ctx, spanner.NewStatement("SELECT COUNT(*) FROM nodes")).Do(func(row *spanner.Row) error {
+				return row.Column(0, &count)
+			})
+			return int(count), errs.Wrap(err)
+		}, nil
+	case "cockroach", "postgres", "postgresql":
+		address, err := c.published(project, u.Host)
+		if err != nil {
+			return nil, err
+		}
+		u.Scheme, u.Host = "postgres", address
+		return func(ctx context.Context) (int, error) {
+			conn, err := pgx.Connect(ctx, u.String())
+			if err != nil {
+				return 0, errs.Wrap(err)
+			}
+			defer func() { _ = conn.Close(ctx) }()
+			var count int
+			err = conn.QueryRow(ctx, "SELECT COUNT(*) FROM nodes").Scan(&count)
+			return count, errs.Wrap(err)
+		}, nil
+	default:
+		return nil, errs.Errorf("unsupported database: %s", u.Scheme)
+	}
+}
+
+// published returns with the host address of a service port (like cockroach:26257), based on the published ports.
+func (c *Cluster) published(project *types.Project, address string) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", errs.Errorf("invalid address %s: %v", address, err)
+	}
+	for _, s := range project.Services {
+		if s.Name != host {
+			continue
+		}
+		for _, p := range s.Ports {
+			if strconv.Itoa(int(p.Target)) == port && p.Published != "" {
+				external := c.runtime.GetHost(runtime.NewServiceInstance(host, 0), "external")
+				return net.JoinHostPort(external, p.Published), nil
+			}
+		}
+	}
+	return "", errs.Errorf("port %s of %s is not published", port, host)
+}
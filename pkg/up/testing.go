@@ -0,0 +1,42 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package up
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// DestroyTimeout is the time limit of removing the cluster at the end of a test.
+var DestroyTimeout = 2 * time.Minute
+
+// Run creates and starts a cluster for a test, and waits until it's healthy. The directory is a temporary directory
+// of the test, if it's not set. The cluster is destroyed when the test and its subtests are completed.
+func Run(tb testing.TB, options Options) *Cluster {
+	tb.Helper()
+	if options.Dir == "" {
+		options.Dir = tb.TempDir()
+	}
+	ctx := tb.Context()
+	cluster, err := New(ctx, options)
+	if err != nil {
+		tb.Fatalf("couldn't create the cluster: %+v", err)
+	}
+	tb.Cleanup(func() {
+		// the context of the test is already canceled
+		ctx, cancel := context.WithTimeout(context.Background(), DestroyTimeout)
+		defer cancel()
+		if err := cluster.Destroy(ctx); err != nil {
+			tb.Errorf("couldn't destroy the cluster: %+v", err)
+		}
+	})
+	if err := cluster.Start(ctx); err != nil {
+		tb.Fatalf("couldn't start the cluster: %+v", err)
+	}
+	if err := cluster.WaitHealthy(ctx); err != nil {
+		tb.Fatalf("%+v", err)
+	}
+	return cluster
+}
@@ -0,0 +1,260 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package up is the Go API of storj-up: it generates, starts and stops docker compose based Storj clusters, for
+// example from integration tests.
+//
+// The services use fixed ports on the host, so only one cluster can run at the same time on a docker host.
+package up
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs/v2"
+
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+	"storj.io/storj-up/pkg/recipe"
+	"storj.io/storj-up/pkg/runtime/compose"
+	"storj.io/storj-up/pkg/runtime/runtime"
+)
+
+// DefaultRecipes are used, if no recipe is selected.
+var DefaultRecipes = []string{"db", "minimal", "edge"}
+
+// Options are the parameters of a new cluster.
+type Options struct {
+	// Dir is the directory of the generated docker-compose.yaml. If empty, a temporary directory is created, which
+	// is removed by Destroy.
+	Dir string
+	// Recipes are the selectors of the recipes or services to add (like minimal or satellite-admin). DefaultRecipes
+	// are used, if empty.
+	Recipes []string
+	// Overrides are the environment variables of the services, by selector (like satellite-api or storagenode).
+	Overrides map[string]map[string]string
+	// Email is the email of the test user, used by Credentials (test@storj.io by default).
+	Email string
+	// Customize is called before the compose file is written, to modify the services (like their images).
+	Customize func(rt runtime.Runtime) error
+}
+
+// Cluster is a storj-up environment, generated to a directory.
+type Cluster struct {
+	dir     string
+	temp    bool
+	email   string
+	runtime *compose.Compose
+}
+
+// New generates docker-compose.yaml to the directory of the cluster. An existing docker-compose.yaml is replaced.
+// The services are not started.
+func New(ctx context.Context, options Options) (_ *Cluster, err error) {
+	c := &Cluster{
+		dir:   options.Dir,
+		email: options.Email,
+	}
+	if c.email == "" {
+		c.email = "test@storj.io"
+	}
+	if c.dir == "" {
+		c.dir, err = os.MkdirTemp("", "storj-up-")
+		if err != nil {
+			return nil, errs.Wrap(err)
+		}
+		c.temp = true
+		defer func() {
+			if err != nil {
+				_ = os.RemoveAll(c.dir)
+			}
+		}()
+	}
+	c.dir, err = filepath.Abs(c.dir)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	err = os.MkdirAll(c.dir, 0o755)
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+
+	c.runtime, err = compose.NewCompose(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	stack, err := recipe.GetStack()
+	if err != nil {
+		return nil, err
+	}
+	recipes := options.Recipes
+	if len(recipes) == 0 {
+		recipes = DefaultRecipes
+	}
+	err = runtime.ApplyRecipes(stack, c.runtime, recipes, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var selectors []string
+	for selector := range options.Overrides {
+		selectors = append(selectors, selector)
+	}
+	sort.Strings(selectors)
+	for _, selector := range selectors {
+		env := options.Overrides[selector]
+		err = runtime.ModifyService(stack, c.runtime, []string{selector}, func(s runtime.Service) error {
+			for key, value := range env {
+				if err := s.AddEnvironment(key, value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Customize != nil {
+		err = options.Customize(c.runtime)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the file is generated from scratch, the previous version shouldn't be saved to the history
+	err = os.Remove(filepath.Join(c.dir, common.ComposeFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errs.Wrap(err)
+	}
+	return c, c.runtime.Write()
+}
+
+// Dir returns with the directory of the cluster.
+func (c *Cluster) Dir() string {
+	return c.dir
+}
+
+// Runtime returns with the runtime of the cluster, to inspect or modify the services. Modifications are saved by
+// Runtime().Write(), and applied by the next Start.
+func (c *Cluster) Runtime() runtime.Runtime {
+	return c.runtime
+}
+
+// ProjectName returns with the compose project name of the cluster (storj-up, or COMPOSE_PROJECT_NAME). The
+// containers are managed with the same name by the Docker Engine API and by the docker compose CLI.
+func (c *Cluster) ProjectName() (string, error) {
+	return docker.ProjectName(c.dir, common.ComposeFileName)
+}
+
+// Start creates and starts the containers with the Docker Engine API, or with `docker compose up -d` if the API is
+// not available.
+func (c *Cluster) Start(ctx context.Context) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		return c.compose(ctx, "up", "-d")
+	}
+	project, err := docker.LoadProject(c.dir, common.ComposeFileName)
+	if err != nil {
+		return err
+	}
+	return client.Up(ctx, project, nil)
+}
+
+// Stop stops the containers. The containers and their data are kept.
+func (c *Cluster) Stop(ctx context.Context) error {
+	client, err := docker.Available(ctx)
+	if err != nil {
+		return c.compose(ctx, "stop")
+	}
+	name, err := c.ProjectName()
+	if err != nil {
+		return err
+	}
+	return client.Stop(ctx, name, 10)
+}
+
+// Destroy removes the containers with their volumes, and the directory of the cluster, if it's created by New.
+func (c *Cluster) Destroy(ctx context.Context) error {
+	err := c.compose(ctx, "down", "--volumes", "--remove-orphans")
+	if c.temp {
+		err = errs.Combine(err, os.RemoveAll(c.dir))
+	}
+	return err
+}
+
+// Credentials creates the test user with a project, an API key and an access grant. It retries until the satellite
+// is available, or the context is canceled.
+func (c *Cluster) Credentials(ctx context.Context) (Credentials, error) {
+	sat := runtime.NewServiceInstance("satellite-api", 0)
+	host := c.runtime.GetHost(sat, "external")
+	satellite := fmt.Sprintf("%s:%d", host, c.runtime.GetPort(sat, "public").External)
+	console := fmt.Sprintf("%s:%d", host, c.runtime.GetPort(sat, "console").External)
+	for {
+		attemptCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		creds, err := GenerateCredentials(attemptCtx, satellite, console, c.email)
+		cancel()
+		if err == nil {
+			return creds, nil
+		}
+		if waitErr := wait(ctx, time.Second); waitErr != nil {
+			return creds, errs.Errorf("couldn't generate credentials: %v", err)
+		}
+	}
+}
+
+// Exec executes a command in the container of a service (like uplink), and returns with the combined output.
+func (c *Cluster) Exec(ctx context.Context, service string, args ...string) (string, error) {
+	name, err := c.ProjectName()
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, "docker", append([]string{"compose", "-p", name, "exec", "-T", service}, args...)...)
+	cmd.Dir = c.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), errs.Errorf("couldn't execute %s in %s: %v\n%s", strings.Join(args, " "), service, err, out)
+	}
+	return string(out), nil
+}
+
+// compose executes a docker compose command in the directory of the cluster, with the project name of the cluster.
+func (c *Cluster) compose(ctx context.Context, args ...string) error {
+	name, err := c.ProjectName()
+	if err != nil {
+		return err
+	}
+	return c.docker(ctx, append([]string{"compose", "-p", name}, args...)...)
+}
+
+// docker executes a docker command in the directory of the cluster. The output is returned in the error only.
+func (c *Cluster) docker(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Dir = c.dir
+	out := &bytes.Buffer{}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return errs.Errorf("couldn't execute docker %s: %v\n%s", strings.Join(args, " "), err, out.String())
+	}
+	return nil
+}
+
+// wait sleeps for the given duration, or returns with the error of the context.
+func wait(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package up
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"storj.io/common/grant"
+	"storj.io/common/macaroon"
+	"storj.io/storj-up/pkg/common"
+	"storj.io/storj-up/pkg/docker"
+	"storj.io/storj/web/satellite/wasm/consolewasm"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("STORJ_DOCKER_HOST", "")
+	cluster, err := New(t.Context(), Options{
+		Dir:     t.TempDir(),
+		Recipes: []string{"minimal", "db"},
+		Overrides: map[string]map[string]string{
+			"satellite-api": {"STORJ_LOG_LEVEL": "info"},
+			"storagenode":   {"STORJ_STORAGE2_MONITOR_MINIMUM_DISK_SPACE": "0B"},
+		},
+	})
+	require.NoError(t, err)
+	require.FileExists(t, filepath.Join(cluster.Dir(), common.ComposeFileName))
+
+	project, err := common.LoadComposeFromFile(cluster.Dir(), common.ComposeFileName)
+	require.NoError(t, err)
+	require.Equal(t, "info", *project.Services["satellite-api"].Environment["STORJ_LOG_LEVEL"])
+	require.Equal(t, "0B", *project.Services["storagenode10"].Environment["STORJ_STORAGE2_MONITOR_MINIMUM_DISK_SPACE"])
+
+	address, err := cluster.published(project, "spanner:9010")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:9010", address)
+	_, err = cluster.published(project, "redis:6379")
+	require.Error(t, err)
+
+	_, err = cluster.nodeCounter(project, "mysql://localhost/master")
+	require.ErrorContains(t, err, "unsupported database")
+
+	// the file is regenerated, without history
+	_, err = New(t.Context(), Options{Dir: cluster.Dir(), Recipes: []string{"minimal", "db"}})
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(cluster.Dir(), ".history"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestLifecycle(t *testing.T) {
+	t.Setenv("COMPOSE_PROJECT_NAME", "")
+	t.Setenv(docker.CLIEnv, "")
+
+	// containers are created with the Engine API
+	var mu sync.Mutex
+	created := map[string]string{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("[]"))
+	})
+	mux.HandleFunc("POST /containers/create", func(w http.ResponseWriter, r *http.Request) {
+		var config docker.ContainerConfig
+		_ = json.NewDecoder(r.Body).Decode(&config)
+		mu.Lock()
+		created[r.URL.Query().Get("name")] = config.Labels[docker.ProjectLabel]
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"Id":"id1"}`))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(server.URL, "http://"))
+
+	// exec and down are executed with the docker CLI
+	bin := t.TempDir()
+	log := filepath.Join(bin, "docker.log")
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "docker"), []byte("#!/bin/sh\necho \"$@\" >> "+log+"\necho PONG\n"), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cluster, err := New(t.Context(), Options{Dir: filepath.Join(t.TempDir(), "other-name"), Recipes: []string{"redis"}})
+	require.NoError(t, err)
+	name, err := cluster.ProjectName()
+	require.NoError(t, err)
+	require.Equal(t, "storj-up", name)
+
+	require.NoError(t, cluster.Start(t.Context()))
+	require.Equal(t, map[string]string{"storj-up-redis-1": "storj-up"}, created)
+	out, err := cluster.Exec(t.Context(), "redis", "redis-cli", "ping")
+	require.NoError(t, err)
+	require.Equal(t, "PONG\n", out)
+	require.NoError(t, cluster.Destroy(t.Context()))
+
+	commands, err := os.ReadFile(log)
+	require.NoError(t, err)
+	require.Equal(t, "compose -p storj-up exec -T redis redis-cli ping\ncompose -p storj-up down --volumes --remove-orphans\n", string(commands))
+}
+
+func TestRelocateGrant(t *testing.T) {
+	key, err := macaroon.NewAPIKey([]byte("secret"))
+	require.NoError(t, err)
+	const id = "12whfK1EDvHJtajBiAUeajQLYcWqxcQmdYQU5zX5cCf6bAxfgu6"
+	access, err := consolewasm.GenAccessGrant(id+"@localhost:7777", key.Serialize(), EncryptionSecret, "c2FsdA==", true)
+	require.NoError(t, err)
+
+	relocated, err := RelocateGrant(access, "satellite-api:7777")
+	require.NoError(t, err)
+	parsed, err := grant.ParseAccess(relocated)
+	require.NoError(t, err)
+	require.Equal(t, id+"@satellite-api:7777", parsed.SatelliteAddress)
+	require.Equal(t, key.Serialize(), parsed.APIKey.Serialize())
+
+	_, err = RelocateGrant("invalid", "satellite-api:7777")
+	require.Error(t, err)
+}
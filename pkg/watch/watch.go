@@ -0,0 +1,260 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+// Package watch watches the source code of Go main packages, and reports which of them are changed.
+package watch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zeebo/errs/v2"
+)
+
+// Watcher watches the directories of Go packages and all their dependencies (except the standard library and the
+// module cache). A change of a file in a package directory, or of go.mod / go.sum of a module, affects the packages
+// which depend on them.
+type Watcher struct {
+	fs       *fsnotify.Watcher
+	debounce time.Duration
+
+	mu       sync.Mutex
+	modCache string
+	// dirs are the packages affected by the files of a directory.
+	dirs map[string]map[string]bool
+	// modules are the packages affected by go.mod and go.sum of a module directory.
+	modules map[string]map[string]bool
+}
+
+// New creates a Watcher. Changes are reported when there are no more changes for the debounce duration.
+func New(debounce time.Duration) (*Watcher, error) {
+	fs, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errs.Wrap(err)
+	}
+	return &Watcher{
+		fs:       fs,
+		debounce: debounce,
+		dirs:     map[string]map[string]bool{},
+		modules:  map[string]map[string]bool{},
+	}, nil
+}
+
+// Add starts to watch a package (a directory with a main package), and its dependencies. Adding a package again
+// refreshes its dependencies, which is required after the imports are changed. Directories which are not required by
+// any package any more are not watched.
+func (w *Watcher) Add(ctx context.Context, pkg string) error {
+	pkg, err := filepath.Abs(pkg)
+	if err != nil {
+		return errs.Wrap(err)
+	}
+	dirs, modules, err := w.dependencies(ctx, pkg)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, affected := range w.dirs {
+		delete(affected, pkg)
+	}
+	for _, affected := range w.modules {
+		delete(affected, pkg)
+	}
+	register := func(index map[string]map[string]bool, dir string) error {
+		if index[dir] == nil {
+			index[dir] = map[string]bool{}
+			if err := w.fs.Add(dir); err != nil {
+				return errs.Errorf("couldn't watch %s: %v", dir, err)
+			}
+		}
+		index[dir][pkg] = true
+		return nil
+	}
+	for _, dir := range dirs {
+		if err := register(w.dirs, dir); err != nil {
+			return err
+		}
+	}
+	for _, dir := range modules {
+		if err := register(w.modules, dir); err != nil {
+			return err
+		}
+	}
+	w.unwatch()
+	return nil
+}
+
+// unwatch stops watching the directories which don't affect any package.
+func (w *Watcher) unwatch() {
+	var unused []string
+	for _, index := range []map[string]map[string]bool{w.dirs, w.modules} {
+		for dir, affected := range index {
+			if len(affected) == 0 {
+				delete(index, dir)
+				unused = append(unused, dir)
+			}
+		}
+	}
+	for _, dir := range unused {
+		if w.dirs[dir] == nil && w.modules[dir] == nil {
+			// the directory may be deleted (and unwatched) already
+			_ = w.fs.Remove(dir)
+		}
+	}
+}
+
+// Packages returns with the number of watched directories for each package.
+func (w *Watcher) Packages() map[string]int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	result := map[string]int{}
+	for _, affected := range w.dirs {
+		for pkg := range affected {
+			result[pkg]++
+		}
+	}
+	return result
+}
+
+// Run waits for the changes, and calls changed with the affected packages (ordered by name), until the context is
+// canceled. Changes during the execution of changed are reported by the next call. Errors of the file system
+// notifications (like a queue overflow) are reported to failed (if not nil), and the watching is continued.
+func (w *Watcher) Run(ctx context.Context, changed func(packages []string), failed func(err error)) error {
+	pending := map[string]bool{}
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			if failed != nil {
+				failed(errs.Wrap(err))
+			}
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			affected := w.affected(event)
+			if len(affected) == 0 {
+				continue
+			}
+			for _, pkg := range affected {
+				pending[pkg] = true
+			}
+			timer.Reset(w.debounce)
+		case <-timer.C:
+			var packages []string
+			for pkg := range pending {
+				packages = append(packages, pkg)
+			}
+			sort.Strings(packages)
+			pending = map[string]bool{}
+			changed(packages)
+		}
+	}
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return errs.Wrap(w.fs.Close())
+}
+
+// affected returns with the packages affected by a file system event.
+func (w *Watcher) affected(event fsnotify.Event) []string {
+	if event.Op == fsnotify.Chmod || ignored(filepath.Base(event.Name)) {
+		return nil
+	}
+	dir, name := filepath.Split(event.Name)
+	dir = filepath.Clean(dir)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var result []string
+	if name == "go.mod" || name == "go.sum" {
+		for pkg := range w.modules[dir] {
+			result = append(result, pkg)
+		}
+	}
+	if !strings.HasSuffix(name, "_test.go") {
+		for pkg := range w.dirs[dir] {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
+// ignored returns true for the temporary files of the editors, and for hidden files.
+func ignored(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp") ||
+		strings.HasPrefix(name, "#")
+}
+
+// dependencies returns with the directories of the (non-standard) packages required to build the package, and the
+// directories of their modules. Packages of the module cache are excluded, as they are never changed.
+func (w *Watcher) dependencies(ctx context.Context, pkg string) (dirs []string, modules []string, err error) {
+	modCache, err := w.moduleCache(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := goCommand(ctx, pkg, "list", "-e", "-deps", "-f", `{{if not .Standard}}{{.Dir}}{{"\t"}}{{with .Module}}{{.Dir}}{{end}}{{end}}`, ".")
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		dir, module, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if dir == "" || (modCache != "" && strings.HasPrefix(dir, modCache+string(filepath.Separator))) {
+			continue
+		}
+		dirs = append(dirs, dir)
+		if module != "" && !seen[module] {
+			seen[module] = true
+			modules = append(modules, module)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, nil, errs.Errorf("no Go package is found in %s", pkg)
+	}
+	return dirs, modules, nil
+}
+
+func (w *Watcher) moduleCache(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.modCache != "" {
+		return w.modCache, nil
+	}
+	out, err := goCommand(ctx, "", "env", "GOMODCACHE")
+	if err != nil {
+		return "", err
+	}
+	w.modCache = strings.TrimSpace(out)
+	return w.modCache, nil
+}
+
+func goCommand(ctx context.Context, dir string, args ...string) (string, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", errs.Errorf("couldn't execute go %s: %v %s", strings.Join(args, " "), err, stderr.String())
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,123 @@
+// Copyright (C) 2026 Storj Labs, Inc.
+// See LICENSE for copying information.
+
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestWatcher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires the go command")
+	}
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.21\n")
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib\"\n")
+	writeFile(t, filepath.Join(dir, "other", "other.go"), "package other\n")
+	writeFile(t, filepath.Join(dir, "cmd", "a", "main.go"), "package main\n\nimport \"example.com/app/lib\"\n\nfunc main() { println(lib.Name) }\n")
+	writeFile(t, filepath.Join(dir, "cmd", "b", "main.go"), "package main\n\nfunc main() {}\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := New(100 * time.Millisecond)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, w.Close()) }()
+
+	a, b := filepath.Join(dir, "cmd", "a"), filepath.Join(dir, "cmd", "b")
+	require.NoError(t, w.Add(ctx, a))
+	require.NoError(t, w.Add(ctx, b))
+	require.Equal(t, map[string]int{a: 2, b: 1}, w.Packages())
+
+	changes := make(chan []string, 10)
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, func(packages []string) { changes <- packages }, func(err error) { t.Log(err) })
+	}()
+	next := func() []string {
+		select {
+		case packages := <-changes:
+			return packages
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "no change is reported")
+			return nil
+		}
+	}
+
+	// changes of a dependency are debounced to one notification
+	writeFile(t, filepath.Join(dir, "lib", "lib.go"), "package lib\n\nconst Name = \"lib2\"\n")
+	writeFile(t, filepath.Join(dir, "lib", "lib2.go"), "package lib\n")
+	require.Equal(t, []string{a}, next())
+
+	// tests, editor files and unused packages are ignored
+	writeFile(t, filepath.Join(dir, "lib", "lib_test.go"), "package lib\n")
+	writeFile(t, filepath.Join(dir, "lib", ".lib.go.swp"), "")
+	writeFile(t, filepath.Join(dir, "other", "other.go"), "package other\n\nconst X = 1\n")
+	writeFile(t, filepath.Join(dir, "go.mod"), "module example.com/app\n\ngo 1.22\n")
+	require.Equal(t, []string{a, b}, next())
+
+	// new imports are watched after Add
+	writeFile(t, filepath.Join(dir, "cmd", "b", "main.go"), "package main\n\nimport _ \"example.com/app/other\"\n\nfunc main() {}\n")
+	require.Equal(t, []string{b}, next())
+	require.NoError(t, w.Add(ctx, b))
+	writeFile(t, filepath.Join(dir, "other", "other.go"), "package other\n\nconst X = 2\n")
+	require.Equal(t, []string{b}, next())
+
+	// removed imports are not watched after Add
+	writeFile(t, filepath.Join(dir, "cmd", "b", "main.go"), "package main\n\nfunc main() {}\n")
+	require.Equal(t, []string{b}, next())
+	require.NoError(t, w.Add(ctx, b))
+	require.Equal(t, map[string]int{a: 2, b: 1}, w.Packages())
+	require.NotContains(t, w.fs.WatchList(), filepath.Join(dir, "other"))
+	require.Contains(t, w.fs.WatchList(), filepath.Join(dir, "lib"))
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Empty(t, changes)
+}
+
+func TestRunErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := New(10 * time.Millisecond)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, w.Close()) }()
+
+	failures := make(chan error, 10)
+	done := make(chan error)
+	go func() {
+		done <- w.Run(ctx, func(packages []string) {}, func(err error) { failures <- err })
+	}()
+
+	// errors are reported, and the watching is continued
+	w.fs.Errors <- fsnotify.ErrEventOverflow
+	w.fs.Errors <- fsnotify.ErrEventOverflow
+	for range 2 {
+		select {
+		case err := <-failures:
+			require.ErrorIs(t, err, fsnotify.ErrEventOverflow)
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "no error is reported")
+		}
+	}
+	select {
+	case err := <-done:
+		require.FailNow(t, "watching is stopped", "%v", err)
+	default:
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+}